@@ -2,8 +2,14 @@ package pgadapter
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/casbin/casbin/v2/model"
 	"github.com/casbin/casbin/v2/persist"
@@ -31,41 +37,124 @@ type CasbinRule struct {
 type Filter struct {
 	P []string
 	G []string
+	// Ptypes holds the field values of the other ptypes, e.g. {"p2": {"", "data1"}, "g2": {"alice"}},
+	// for models with several policy or grouping definitions. P and G take precedence over the "p" and "g" entries.
+	Ptypes map[string][]string
+	// Labels restricts the filter to the rules carrying all these labels, it requires WithLabels.
+	Labels map[string]string
 }
 
 // Adapter represents the github.com/go-pg/pg adapter for policy storage.
 type Adapter struct {
+	// revision and version are accessed atomically and must stay 64-bit aligned.
+	revision        uint64
+	version         int64
 	db              *pg.DB
+	tx              *pg.Tx
+	sharedDB        bool
 	tableName       string
+	schema          string
 	skipTableCreate bool
 	filtered        bool
+	actor           string
+	webhook         *webhook
+	publishers      []ChangePublisher
+	errorHook       ErrorHook
+	subs            subscriptions
+
+	readOnly           int32
+	parent             *Adapter
+	clusterMaintenance bool
+	saveStrategy       SaveStrategy
+	quota              *quota
+	beforeWriteHooks   []BeforeWriteHook
+	middleware         []Middleware
+	allowedPtypes      map[string]bool
+	role               string
+	settings           map[string]string
+	ruleOrder          bool
+	descriptions       bool
+	labels             bool
+	modelStorage       bool
+	bundles            bool
+	searchIndexes      bool
+	arrayColumn        bool
+	operationLog       bool
+	approvals          bool
+	ruleTextColumn     bool
+	columns            map[string]string
+	mappedTable        string
+	notifySender       string
+	watcher            *Watcher
+	skipDefaultIndexes bool
+	txChanges          *[]PolicyChange
+	connectAttempts    int
+	connectBackoff     time.Duration
+	pool               *PoolOptions
+	tlsConfig          *tls.Config
+	softDelete         bool
+	timestamps         bool
+	auditLog           bool
+	tenant             string
+	tenantScoped       bool
+	tenantRLS          bool
+	partitioning       *Partitioning
+	saveBatchSize      int
+	logger             Logger
+	debugLogging       bool
+	queryLog           *queryLogHook
+	snapshots          bool
+	surrogateKey       SurrogateKey
+	idFunc             IDFunc
+	skipDatabaseCreate bool
+	jsonb              bool
+	generatedID        bool
+	normalization      *Normalization
+	statementTimeout   time.Duration
+	replicaArg         interface{}
+	replica            *pg.DB
+	ownsReplica        bool
+	failoverHosts      []string
+	readWriteHost      bool
+	cockroach          bool
+	extraColumns       []ExtraColumn
+	validators         []RuleValidator
+	caseInsensitive    bool
+	naturalUniqueIndex bool
+	cacheTTL           time.Duration
+	cache              *policyCache
 }
 
 type Option func(a *Adapter)
 
 // NewAdapter is the constructor for Adapter.
-// param:arg should be a PostgreS URL string or of type *pg.Options
+// param:arg should be a PostgreS URL string or of type *pg.Options, the URL can tune the pool, see WithPoolOptions,
+// and name a Unix socket directory, e.g. postgres://user@/casbin?host=/var/run/postgresql
 // param:dbname is the name of the database to use and can is optional.
 // If no dbname is provided, the default database name is "casbin" which will be created automatically.
 // If arg is *pg.Options, the arg.Database field is omitted and will be modified according to dbname
+// With skip_database_create=true in the URL, see SkipDatabaseCreate, no database is created and the database
+// of the URL is used unless dbname is provided.
 func NewAdapter(arg interface{}, dbname ...string) (*Adapter, error) {
 	var db *pg.DB
 	var err error
 
 	if len(dbname) > 0 {
-		db, err = createCasbinDatabase(arg, dbname[0])
+		db, err = createCasbinDatabase(arg, dbname[0], nil)
 	} else {
-		db, err = createCasbinDatabase(arg, DefaultDatabaseName)
+		db, err = createCasbinDatabase(arg, "", nil)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("pgadapter.NewAdapter: %v", err)
+		wrapError(OpNewAdapter, &err)
+		return nil, err
 	}
 
 	a := &Adapter{db: db, tableName: DefaultTableName}
 
 	if err := a.createTableifNotExists(); err != nil {
-		return nil, fmt.Errorf("pgadapter.NewAdapter: %v", err)
+		wrapError(OpNewAdapter, &err)
+		return nil, err
 	}
 
 	return a, nil
@@ -74,19 +163,257 @@ func NewAdapter(arg interface{}, dbname ...string) (*Adapter, error) {
 // NewAdapterByDB creates new Adapter by using existing DB connection
 // creates table from CasbinRule struct if it doesn't exist
 func NewAdapterByDB(db *pg.DB, opts ...Option) (*Adapter, error) {
-	a := &Adapter{db: db, tableName: DefaultTableName}
+	a := &Adapter{tableName: DefaultTableName}
 	for _, opt := range opts {
 		opt(a)
 	}
+	return newAdapterByDB(db, a)
+}
 
-	if !a.skipTableCreate {
-		if err := a.createTableifNotExists(); err != nil {
-			return nil, fmt.Errorf("pgadapter.NewAdapter: %v", err)
+// newAdapterByDB sets up a, whose options are applied already, for db. The options are applied only once,
+// since some of them start background work, e.g. WithWebhook, which is stopped if the setup fails.
+func newAdapterByDB(db *pg.DB, a *Adapter) (_ *Adapter, err error) {
+	a.db = db
+	defer func() {
+		if err == nil {
+			return
 		}
+		if a.webhook != nil {
+			a.webhook.close()
+		}
+		if a.replica != nil && a.ownsReplica {
+			a.replica.Close()
+		}
+	}()
+	a.setupLogging()
+
+	if err := a.checkCockroachDB(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.checkShadowSwap(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.mapColumns(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.retryConnect(a.createTables); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.resolveSchema(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.connectReplica(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.startCache(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
 	}
 	return a, nil
 }
 
+// WithTable returns a sibling adapter bound to another rules table, creating it if needed.
+// The sibling has the options of a and shares its connection pool and change publishers,
+// so closing the sibling doesn't close the pool, a must be closed last.
+func (a *Adapter) WithTable(tableName string) (*Adapter, error) {
+	b := a.sibling()
+	b.tableName, b.mappedTable = tableName, ""
+	if err := b.mapColumns(); err != nil {
+		b.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := b.createTables(); err != nil {
+		b.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := b.resolveSchema(); err != nil {
+		b.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	return b, nil
+}
+
+// sibling returns an adapter with the options of a sharing its connection pool.
+func (a *Adapter) sibling() *Adapter {
+	return &Adapter{
+		db:                 a.db,
+		tx:                 a.tx,
+		sharedDB:           true,
+		tableName:          a.tableName,
+		schema:             a.schema,
+		skipTableCreate:    a.skipTableCreate,
+		actor:              a.actor,
+		publishers:         append([]ChangePublisher(nil), a.publishers...),
+		errorHook:          a.errorHook,
+		parent:             a,
+		clusterMaintenance: a.clusterMaintenance,
+		saveStrategy:       a.saveStrategy,
+		quota:              a.quota,
+		beforeWriteHooks:   append([]BeforeWriteHook(nil), a.beforeWriteHooks...),
+		middleware:         append([]Middleware(nil), a.middleware...),
+		allowedPtypes:      a.allowedPtypes,
+		role:               a.role,
+		settings:           a.settings,
+		ruleOrder:          a.ruleOrder,
+		descriptions:       a.descriptions,
+		labels:             a.labels,
+		modelStorage:       a.modelStorage,
+		bundles:            a.bundles,
+		searchIndexes:      a.searchIndexes,
+		arrayColumn:        a.arrayColumn,
+		operationLog:       a.operationLog,
+		approvals:          a.approvals,
+		ruleTextColumn:     a.ruleTextColumn,
+		columns:            a.columns,
+		mappedTable:        a.mappedTable,
+		notifySender:       a.notifySender,
+		skipDefaultIndexes: a.skipDefaultIndexes,
+		txChanges:          a.txChanges,
+		connectAttempts:    a.connectAttempts,
+		connectBackoff:     a.connectBackoff,
+		pool:               a.pool,
+		tlsConfig:          a.tlsConfig,
+		softDelete:         a.softDelete,
+		timestamps:         a.timestamps,
+		auditLog:           a.auditLog,
+		tenant:             a.tenant,
+		tenantScoped:       a.tenantScoped,
+		tenantRLS:          a.tenantRLS,
+		partitioning:       a.partitioning,
+		saveBatchSize:      a.saveBatchSize,
+		logger:             a.logger,
+		debugLogging:       a.debugLogging,
+		queryLog:           a.queryLog,
+		snapshots:          a.snapshots,
+		surrogateKey:       a.surrogateKey,
+		idFunc:             a.idFunc,
+		skipDatabaseCreate: a.skipDatabaseCreate,
+		jsonb:              a.jsonb,
+		generatedID:        a.generatedID,
+		normalization:      a.normalization,
+		statementTimeout:   a.statementTimeout,
+		replica:            a.replica,
+		failoverHosts:      a.failoverHosts,
+		readWriteHost:      a.readWriteHost,
+		cockroach:          a.cockroach,
+		extraColumns:       a.extraColumns,
+		validators:         append([]RuleValidator(nil), a.validators...),
+		caseInsensitive:    a.caseInsensitive,
+		naturalUniqueIndex: a.naturalUniqueIndex,
+		cacheTTL:           a.cacheTTL,
+		cache:              a.cache,
+	}
+}
+
+// createTables creates the tables used by the adapter unless SkipTableCreate is set.
+func (a *Adapter) createTables() error {
+	if a.skipTableCreate {
+		return nil
+	}
+	if err := a.checkPartitioning(); err != nil {
+		return err
+	}
+	if a.mappedTable != "" {
+		if err := a.createMappedView(); err != nil {
+			return err
+		}
+	} else if err := a.createTableifNotExists(); err != nil {
+		return err
+	}
+	if a.ruleOrder {
+		if err := a.createSeqColumn(); err != nil {
+			return err
+		}
+	}
+	if a.descriptions {
+		if err := a.createDescriptionColumn(); err != nil {
+			return err
+		}
+	}
+	if a.labels {
+		if err := a.createLabelsColumn(); err != nil {
+			return err
+		}
+	}
+	if a.clusterMaintenance {
+		if err := a.createMaintenanceTable(); err != nil {
+			return err
+		}
+	}
+	if a.saveStrategy == SaveFailIfChanged {
+		if err := a.createVersionTable(); err != nil {
+			return err
+		}
+	}
+	if a.modelStorage {
+		if err := a.createModelTable(); err != nil {
+			return err
+		}
+	}
+	if a.bundles {
+		if err := a.createBundleTable(); err != nil {
+			return err
+		}
+	}
+	if a.searchIndexes {
+		if err := a.createSearchIndexes(); err != nil {
+			return err
+		}
+	}
+	if a.arrayColumn {
+		if err := a.createArrayColumn(); err != nil {
+			return err
+		}
+	}
+	if a.operationLog {
+		if err := a.createOperationLogTable(); err != nil {
+			return err
+		}
+	}
+	if a.approvals {
+		if err := a.createPendingTable(); err != nil {
+			return err
+		}
+	}
+	if a.ruleTextColumn {
+		if err := a.createRuleTextColumn(); err != nil {
+			return err
+		}
+	}
+	if a.timestamps {
+		if err := a.createTimestampColumns(); err != nil {
+			return err
+		}
+	}
+	if a.auditLog {
+		if err := a.createAuditTable(); err != nil {
+			return err
+		}
+	}
+	if a.snapshots {
+		if err := a.createSnapshotTable(); err != nil {
+			return err
+		}
+	}
+	if len(a.extraColumns) > 0 {
+		if err := a.createExtraColumns(); err != nil {
+			return err
+		}
+	}
+	if a.naturalUniqueIndex {
+		if err := a.createNaturalUniqueIndex(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // WithTableName can be used to pass custom table name for Casbin rules
 func WithTableName(tableName string) Option {
 	return func(a *Adapter) {
@@ -102,27 +429,43 @@ func SkipTableCreate() Option {
 	}
 }
 
-func createCasbinDatabase(arg interface{}, dbname string) (*pg.DB, error) {
-	var opts *pg.Options
-	var err error
-	if connURL, ok := arg.(string); ok {
-		opts, err = pg.ParseURL(connURL)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		opts, ok = arg.(*pg.Options)
-		if !ok {
-			return nil, fmt.Errorf("must pass in a PostgreS URL string or an instance of *pg.Options, received %T instead", arg)
+// createCasbinDatabase creates the database dbname, or DefaultDatabaseName if empty, if needed and connects to it,
+// the pool settings of the URL are applied first, then the connection options of cfg if not nil.
+// With SkipDatabaseCreate it connects to dbname, or the database of arg if empty, without creating it.
+// A role not allowed to create databases connects to the database if it exists.
+func createCasbinDatabase(arg interface{}, dbname string, cfg *Adapter) (*pg.DB, error) {
+	opts, skip, err := connectOptions(arg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if skip {
+		if dbname != "" {
+			opts.Database = dbname
 		}
+		return pg.Connect(opts), nil
+	}
+	if dbname == "" {
+		dbname = DefaultDatabaseName
 	}
 
 	db := pg.Connect(opts)
 	defer db.Close()
 
-	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname))
-	if err != nil && !strings.Contains(err.Error(), "42P04") {
-		return nil, err
+	if cfg != nil && cfg.cockroach {
+		_, err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", dbname))
+	} else {
+		_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname))
+	}
+	if pgErrorCode(err) == pgCodeInsufficientPrivilege {
+		// Roles without CREATEDB can still use an existing database.
+		var exists bool
+		if _, existsErr := db.QueryOne(pg.Scan(&exists), "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = ?)", dbname); existsErr == nil && exists {
+			err = nil
+		}
+	}
+	if err != nil && pgErrorCode(err) != pgCodeDuplicateDB {
+		return nil, &Error{Op: OpNewAdapter, Kind: ErrDatabaseCreate, Err: err}
 	}
 	db.Close()
 
@@ -132,23 +475,109 @@ func createCasbinDatabase(arg interface{}, dbname string) (*pg.DB, error) {
 	return db, nil
 }
 
+// connectOptions returns the options connecting to arg, a URL or *pg.Options, over TCP or a Unix socket, the pool settings of the URL
+// are applied first, then the connection options of cfg if not nil, see also WithFailoverHosts. It reports whether the database creation
+// is skipped, see SkipDatabaseCreate.
+func connectOptions(arg interface{}, cfg *Adapter) (opts *pg.Options, skip bool, err error) {
+	skip = cfg != nil && cfg.skipDatabaseCreate
+	var hosts []string
+	var readWrite bool
+	var socket string
+	if connURL, ok := arg.(string); ok {
+		connURL, socket, err = parseSocketURL(connURL)
+		if err != nil {
+			return nil, false, err
+		}
+		connURL, hosts, readWrite, err = parseHostsURL(connURL)
+		if err != nil {
+			return nil, false, err
+		}
+		var urlPool *PoolOptions
+		connURL, urlPool, err = parsePoolURL(connURL)
+		if err != nil {
+			return nil, false, err
+		}
+		var urlSkip bool
+		connURL, urlSkip, err = parseSkipDatabaseCreate(connURL)
+		if err != nil {
+			return nil, false, err
+		}
+		skip = skip || urlSkip
+		opts, err = pg.ParseURL(connURL)
+		if err != nil {
+			return nil, false, err
+		}
+		urlPool.apply(opts)
+	} else {
+		opts, ok = arg.(*pg.Options)
+		if !ok {
+			return nil, false, fmt.Errorf("must pass in a PostgreS URL string or an instance of *pg.Options, received %T instead", arg)
+		}
+	}
+
+	if cfg != nil {
+		cfg.pool.apply(opts)
+		if cfg.tlsConfig != nil {
+			opts.TLSConfig = cfg.tlsConfig
+		}
+		cfg.applyStatementTimeout(opts)
+		hosts = append(hosts, cfg.failoverHosts...)
+		readWrite = readWrite || cfg.readWriteHost
+	}
+	applySocket(opts, socket)
+	applyFailover(opts, hosts, readWrite)
+	return opts, skip, nil
+}
+
 // Close close database connection
 func (a *Adapter) Close() error {
-	if a != nil && a.db != nil {
+	if a == nil {
+		return nil
+	}
+	if a.watcher != nil {
+		a.watcher.Close()
+	}
+	a.subs.close()
+	if a.webhook != nil {
+		a.webhook.close()
+	}
+	if a.cache != nil && !a.sharedDB {
+		a.cache.close()
+	}
+	if a.replica != nil && a.ownsReplica && !a.sharedDB {
+		a.replica.Close()
+	}
+	if a.db != nil && !a.sharedDB {
 		return a.db.Close()
 	}
 	return nil
 }
 
 func (a *Adapter) createTableifNotExists() error {
-	err := a.db.Model((*CasbinRule)(nil)).Table(a.tableName).CreateTable(&orm.CreateTableOptions{
+	if a.partitioning != nil {
+		stmts := partitionedTableSQL(a.tableName, nil, a.partitioning, false)
+		if _, err := a.conn().Exec(stmts[0]); err != nil {
+			return err
+		}
+		if err := a.createDefaultIndexes(a.tableName); err != nil {
+			return err
+		}
+		for _, stmt := range stmts[1:] {
+			if _, err := a.conn().Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := a.conn().Model((*CasbinRule)(nil)).Table(a.tableName).CreateTable(&orm.CreateTableOptions{
 		Temp:        false,
 		IfNotExists: true,
 	})
 	if err != nil {
 		return err
 	}
-	return nil
+	return a.createDefaultIndexes(a.tableName)
 }
 
 func (r *CasbinRule) String() string {
@@ -192,18 +621,51 @@ func (r *CasbinRule) String() string {
 
 // LoadPolicy loads policy from database.
 func (a *Adapter) LoadPolicy(model model.Model) error {
-	var lines []*CasbinRule
+	return a.LoadPolicyCtx(context.Background(), model)
+}
 
-	if err := a.db.Model(&lines).Table(a.tableName).Select(); err != nil {
-		return err
-	}
+// LoadPolicyCtx is like LoadPolicy, ctx bounds the database calls.
+func (a *Adapter) LoadPolicyCtx(ctx context.Context, model model.Model) error {
+	return a.doContext(ctx, &Operation{Name: OpLoadPolicy}, func(ctx context.Context) error {
+		return a.loadPolicy(ctx, model)
+	})
+}
 
-	for _, line := range lines {
-		err := persist.LoadPolicyLine(line.String(), model)
-		if err != nil {
+// loadQuery applies the rule order and the bundle selection to a query loading rules into the model.
+func (a *Adapter) loadQuery(query *orm.Query) *orm.Query {
+	return a.ordered(a.enabledBundles(query))
+}
+
+func (a *Adapter) loadPolicy(ctx context.Context, model model.Model) (err error) {
+	defer a.handleError(OpLoadPolicy, "", 0, &err)
+
+	// The rows are streamed into the model, so large policies are never held in memory as a whole,
+	// unless WithPolicyCache keeps them.
+	rows := 0
+	err = a.cachedLoad("", func(line string) error {
+		rows++
+		return persist.LoadPolicyLine(line, model)
+	}, func(handler func(string) error) error {
+		if err := a.loadVersion(ctx); err != nil {
 			return err
 		}
+		skipped := skippedRules{}
+		query := a.loadQuery(a.readConn().ModelContext(ctx, (*CasbinRule)(nil)).Table(a.tableName))
+		err := query.ForEach(func(line *CasbinRule) error {
+			if !a.loadAllowed(line.Ptype, skipped) {
+				return nil
+			}
+			return handler(line.String())
+		})
+		if err == nil {
+			a.reportSkipped(OpLoadPolicy, skipped)
+		}
+		return err
+	})
+	if err != nil {
+		return err
 	}
+	countRows(ctx, rows)
 
 	a.filtered = false
 
@@ -244,118 +706,386 @@ func savePolicyLine(ptype string, rule []string) *CasbinRule {
 	return line
 }
 
+// IDFunc computes the ID under which a rule is stored, see WithIDFunc.
+type IDFunc func(ptype string, rule []string) string
+
+// WithIDFunc makes the adapter compute the IDs of the rules with fn instead of the meow checksum of
+// "ptype,v0,v1,...", e.g. SHA256PolicyID where meow is not accepted. fn must return the same ID
+// for the same rule and different IDs for different rules. The IDs of the stored rules must have been
+// computed by the same function, RepairPolicyIDs recomputes them when switching functions.
+func WithIDFunc(fn IDFunc) Option {
+	return func(a *Adapter) {
+		a.idFunc = fn
+	}
+}
+
+// SHA256PolicyID is an IDFunc returning the hex encoded SHA-256 hash of "ptype,v0,v1,...".
+func SHA256PolicyID(ptype string, rule []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{ptype}, rule...), ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ruleID returns the ID of the rule, computed by the function of WithIDFunc if set, see also WithCaseInsensitive.
+func (a *Adapter) ruleID(ptype string, rule []string) string {
+	rule = a.fold(rule)
+	if a.idFunc != nil {
+		return a.idFunc(ptype, rule)
+	}
+	return policyID(ptype, rule)
+}
+
+// ruleLine is like savePolicyLine with the ID computed by ruleID.
+func (a *Adapter) ruleLine(ptype string, rule []string) *CasbinRule {
+	rule = a.fold(rule)
+	line := savePolicyLine(ptype, rule)
+	if a.idFunc != nil {
+		line.ID = a.idFunc(ptype, rule)
+	}
+	return line
+}
+
 // SavePolicy saves policy to database.
 func (a *Adapter) SavePolicy(model model.Model) error {
-	tx, err := a.db.Begin()
-	if err != nil {
-		return fmt.Errorf("start DB transaction: %v", err)
-	}
-	defer tx.Close()
+	return a.SavePolicyCtx(context.Background(), model)
+}
 
-	_, err = tx.Model((*CasbinRule)(nil)).Table(a.tableName).Where("id IS NOT NULL").Delete()
-	if err != nil {
+// SavePolicyCtx is like SavePolicy, ctx bounds the database calls.
+func (a *Adapter) SavePolicyCtx(ctx context.Context, model model.Model) error {
+	return a.doContext(ctx, &Operation{Name: OpSavePolicy}, func(ctx context.Context) error {
+		return a.savePolicy(ctx, model)
+	})
+}
+
+func (a *Adapter) savePolicy(ctx context.Context, model model.Model) (err error) {
+	defer a.handleError(OpSavePolicy, "", 0, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
 		return err
 	}
 
 	var lines []*CasbinRule
 
 	for ptype, ast := range model["p"] {
+		if err := a.beforeWrite(OpSavePolicy, "p", ptype, ast.Policy); err != nil {
+			return err
+		}
 		for _, rule := range ast.Policy {
-			line := savePolicyLine(ptype, rule)
+			line := a.ruleLine(ptype, rule)
 			lines = append(lines, line)
 		}
 	}
 
 	for ptype, ast := range model["g"] {
+		if err := a.beforeWrite(OpSavePolicy, "g", ptype, ast.Policy); err != nil {
+			return err
+		}
 		for _, rule := range ast.Policy {
-			line := savePolicyLine(ptype, rule)
+			line := a.ruleLine(ptype, rule)
 			lines = append(lines, line)
 		}
 	}
 
-	for _, line := range lines {
-		_, err = tx.Model(line).Table(a.tableName).
-			OnConflict("DO NOTHING").
-			Insert()
-		if err != nil {
+	change := PolicyChange{Operation: OpSavePolicy}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := a.checkVersion(tx); err != nil {
+			return err
+		}
+
+		if a.saveStrategy == SaveShadowSwap {
+			if err := a.swapInRules(tx, lines); err != nil {
+				return err
+			}
+			return a.recordChange(ctx, tx, change)
+		}
+
+		// The stored rules are replaced by inserting and deleting only the rules that differ,
+		// so the table is never empty and the unchanged rows are left alone. With WithRuleOrder,
+		// the table is emptied and refilled in the order of the model.
+		replace := a.saveStrategy != SaveMergeUnion
+		if replace && !a.ruleOrder {
+			sortRules(lines)
+			if _, err := a.replaceRules(ctx, tx, uniqueRules(lines), &SyncReport{}); err != nil {
+				return err
+			}
+			return a.recordChange(ctx, tx, change)
+		}
+		if replace {
+			_, err := tx.Model((*CasbinRule)(nil)).Table(a.tableName).Where("id IS NOT NULL").Delete()
+			if err != nil {
+				return err
+			}
+		}
+
+		// The emptied table is filled in batches with WithSaveBatchSize, or with COPY directly,
+		// views of mapped tables don't support COPY.
+		if a.batchSize() > 0 {
+			if err := a.insertBatches(tx, lines); err != nil {
+				return err
+			}
+		} else if replace && a.mappedTable == "" {
+			if err := copyRules(tx, a.tableName, lines); err != nil {
+				return err
+			}
+		} else if err := a.insertRules(tx, lines); err != nil {
 			return err
 		}
-	}
 
-	err = tx.Commit()
+		return a.recordChange(ctx, tx, change)
+	})
 	if err != nil {
-		return fmt.Errorf("commit DB transaction: %v", err)
+		return err
 	}
 
+	countRows(ctx, len(lines))
+	a.changed(ctx, change)
+
 	return nil
 }
 
 // AddPolicy adds a policy rule to the storage.
 func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
-	line := savePolicyLine(ptype, rule)
-	err := a.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
-		_, err := a.db.Model(line).
+	return a.AddPolicyCtx(context.Background(), sec, ptype, rule)
+}
+
+// AddPolicyCtx is like AddPolicy, ctx bounds the database calls.
+func (a *Adapter) AddPolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+	op := &Operation{Name: OpAddPolicy, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.addPolicy(ctx, sec, ptype, rule, nil)
+	})
+}
+
+// addPolicy adds rule and stores meta with it, meta is nil for plain AddPolicy calls.
+func (a *Adapter) addPolicy(ctx context.Context, sec string, ptype string, rule []string, meta *PolicyMeta) (err error) {
+	defer a.handleError(OpAddPolicy, ptype, 1, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.beforeWrite(OpAddPolicy, sec, ptype, [][]string{rule}); err != nil {
+		return err
+	}
+
+	line := a.ruleLine(ptype, rule)
+	change := PolicyChange{Operation: OpAddPolicy, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.Model(line).
 			Table(a.tableName).
 			OnConflict("DO NOTHING").
 			Insert()
+		if err != nil {
+			return err
+		}
+		if err := a.checkQuota(tx, []*CasbinRule{line}); err != nil {
+			return err
+		}
+		if meta != nil {
+			if err := a.setMeta(tx, line.ID, meta); err != nil {
+				return err
+			}
+		}
 
-		return err
+		return a.recordChange(ctx, tx, change)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	a.changed(ctx, change)
+
+	return nil
 }
 
 // AddPolicies adds policy rules to the storage.
 func (a *Adapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	return a.AddPoliciesCtx(context.Background(), sec, ptype, rules)
+}
+
+// AddPoliciesCtx is like AddPolicies, ctx bounds the database calls.
+func (a *Adapter) AddPoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	_, err := a.AddPoliciesReturningCtx(ctx, sec, ptype, rules)
+	return err
+}
+
+// AddPoliciesReturning is like AddPolicies but returns the rules that were inserted,
+// leaving out the rules already stored.
+func (a *Adapter) AddPoliciesReturning(sec string, ptype string, rules [][]string) ([][]string, error) {
+	return a.AddPoliciesReturningCtx(context.Background(), sec, ptype, rules)
+}
+
+// AddPoliciesReturningCtx is like AddPoliciesReturning, ctx bounds the database calls.
+func (a *Adapter) AddPoliciesReturningCtx(ctx context.Context, sec string, ptype string, rules [][]string) ([][]string, error) {
+	op := &Operation{Name: OpAddPolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	err := a.doContext(ctx, op, func(ctx context.Context) error {
+		var err error
+		op.Result, err = a.addPolicies(ctx, sec, ptype, rules)
+		return err
+	})
+	return op.Result, err
+}
+
+func (a *Adapter) addPolicies(ctx context.Context, sec string, ptype string, rules [][]string) (_ [][]string, err error) {
+	defer a.handleError(OpAddPolicies, ptype, len(rules), &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.beforeWrite(OpAddPolicies, sec, ptype, rules); err != nil {
+		return nil, err
+	}
+
 	var lines []*CasbinRule
 	for _, rule := range rules {
-		line := savePolicyLine(ptype, rule)
+		line := a.ruleLine(ptype, rule)
 		lines = append(lines, line)
 	}
 
-	err := a.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+	var inserted [][]string
+	change := PolicyChange{Operation: OpAddPolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		var ids []string
 		_, err := tx.Model(&lines).
 			Table(a.tableName).
 			OnConflict("DO NOTHING").
-			Insert()
-		return err
+			Returning("id").
+			Insert(&ids)
+		if err != nil {
+			return err
+		}
+		if err := a.checkQuota(tx, lines); err != nil {
+			return err
+		}
+
+		isNew := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			isNew[id] = true
+		}
+		inserted = inserted[:0]
+		for i, line := range lines {
+			if isNew[line.ID] {
+				inserted = append(inserted, rules[i])
+				// A rule given twice is inserted once.
+				delete(isNew, line.ID)
+			}
+		}
+		return a.recordChange(ctx, tx, change)
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	a.changed(ctx, change)
+
+	return inserted, nil
 }
 
 // RemovePolicy removes a policy rule from the storage.
 func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
-	line := savePolicyLine(ptype, rule)
-	err := a.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
-		_, err := a.db.Model(line).Table(a.tableName).WherePK().Delete()
+	return a.RemovePolicyCtx(context.Background(), sec, ptype, rule)
+}
+
+// RemovePolicyCtx is like RemovePolicy, ctx bounds the database calls.
+func (a *Adapter) RemovePolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+	op := &Operation{Name: OpRemovePolicy, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.removePolicy(ctx, sec, ptype, rule)
+	})
+}
+
+func (a *Adapter) removePolicy(ctx context.Context, sec string, ptype string, rule []string) (err error) {
+	defer a.handleError(OpRemovePolicy, ptype, 1, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
 		return err
+	}
+	if err := a.beforeWrite(OpRemovePolicy, sec, ptype, [][]string{rule}); err != nil {
+		return err
+	}
+
+	line := a.ruleLine(ptype, rule)
+	change := PolicyChange{Operation: OpRemovePolicy, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.Model(line).Table(a.tableName).WherePK().Delete()
+		if err != nil {
+			return err
+		}
+		return a.recordChange(ctx, tx, change)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	a.changed(ctx, change)
+
+	return nil
 }
 
 // RemovePolicies removes policy rules from the storage.
 func (a *Adapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
-	var lines []*CasbinRule
+	return a.RemovePoliciesCtx(context.Background(), sec, ptype, rules)
+}
+
+// RemovePoliciesCtx is like RemovePolicies, ctx bounds the database calls.
+func (a *Adapter) RemovePoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	op := &Operation{Name: OpRemovePolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.removePolicies(ctx, sec, ptype, rules)
+	})
+}
+
+func (a *Adapter) removePolicies(ctx context.Context, sec string, ptype string, rules [][]string) (err error) {
+	defer a.handleError(OpRemovePolicies, ptype, len(rules), &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.beforeWrite(OpRemovePolicies, sec, ptype, rules); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(rules))
 	for _, rule := range rules {
-		line := savePolicyLine(ptype, rule)
-		lines = append(lines, line)
+		ids = append(ids, a.ruleID(ptype, rule))
 	}
 
-	err := a.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
-		_, err := tx.Model(&lines).Table(a.tableName).
-			Delete()
-		return err
+	change := PolicyChange{Operation: OpRemovePolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := a.deleteIDs(ctx, tx, ids); err != nil {
+			return err
+		}
+		return a.recordChange(ctx, tx, change)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	a.changed(ctx, change)
+
+	return nil
 }
 
-// RemoveFilteredPolicy removes policy rules that match the filter from the storage.
-func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
-	query := a.db.Model((*CasbinRule)(nil)).Table(a.tableName).Where("ptype = ?", ptype)
+// deleteBatchSize is the number of rules deleted by a statement of deleteIDs.
+const deleteBatchSize = 1000
 
+// deleteIDs deletes the rules with the given IDs within tx, with a statement per deleteBatchSize rules.
+func (a *Adapter) deleteIDs(ctx context.Context, tx *pg.Tx, ids []string) error {
+	for len(ids) > 0 {
+		n := deleteBatchSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM ? WHERE id IN (?)", pg.Ident(a.tableName), pg.In(ids[:n]))
+		if err != nil {
+			return err
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+// fieldQuery restricts query to the rules whose values from fieldIndex on are fieldValues,
+// empty values match any value.
+func fieldQuery(query *orm.Query, fieldIndex int, fieldValues []string) *orm.Query {
 	idx := fieldIndex + len(fieldValues)
 	if fieldIndex <= 0 && idx > 0 && fieldValues[0-fieldIndex] != "" {
 		query = query.Where("v0 = ?", fieldValues[0-fieldIndex])
@@ -375,25 +1105,103 @@ func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int,
 	if fieldIndex <= 5 && idx > 5 && fieldValues[5-fieldIndex] != "" {
 		query = query.Where("v5 = ?", fieldValues[5-fieldIndex])
 	}
+	return query
+}
+
+// RemoveFilteredPolicy removes policy rules that match the filter from the storage.
+func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.RemoveFilteredPolicyCtx(context.Background(), sec, ptype, fieldIndex, fieldValues...)
+}
+
+// RemoveFilteredPolicyCtx is like RemoveFilteredPolicy, ctx bounds the database calls.
+func (a *Adapter) RemoveFilteredPolicyCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	_, err := a.RemoveFilteredPolicyReturningCtx(ctx, sec, ptype, fieldIndex, fieldValues...)
+	return err
+}
 
-	err := a.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
-		_, err := query.Delete()
+// RemoveFilteredPolicyReturning is like RemoveFilteredPolicy but returns the removed rules.
+func (a *Adapter) RemoveFilteredPolicyReturning(sec string, ptype string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	return a.RemoveFilteredPolicyReturningCtx(context.Background(), sec, ptype, fieldIndex, fieldValues...)
+}
+
+// RemoveFilteredPolicyReturningCtx is like RemoveFilteredPolicyReturning, ctx bounds the database calls.
+func (a *Adapter) RemoveFilteredPolicyReturningCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	op := &Operation{Name: OpRemoveFilteredPolicy, Sec: sec, Ptype: ptype, Rules: [][]string{fieldValues}, FieldIndex: fieldIndex}
+	err := a.doContext(ctx, op, func(ctx context.Context) error {
+		var err error
+		op.Result, err = a.removeFilteredPolicy(ctx, sec, ptype, fieldIndex, fieldValues...)
 		return err
 	})
+	return op.Result, err
+}
 
-	return err
+func (a *Adapter) removeFilteredPolicy(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer a.handleError(OpRemoveFilteredPolicy, ptype, 0, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.beforeWrite(OpRemoveFilteredPolicy, sec, ptype, [][]string{fieldValues}); err != nil {
+		return nil, err
+	}
+
+	var lines []*CasbinRule
+	query := a.conn().ModelContext(ctx, &lines).Table(a.tableName).Where("ptype = ?", ptype)
+
+	query = fieldQuery(query, fieldIndex, a.fold(fieldValues))
+
+	change := PolicyChange{
+		Operation:  OpRemoveFilteredPolicy,
+		Sec:        sec,
+		Ptype:      ptype,
+		Rules:      [][]string{fieldValues},
+		FieldIndex: fieldIndex,
+	}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := query.DB(tx).Returning("*").Delete()
+		if err != nil {
+			return err
+		}
+
+		change.Removed = make([][]string, 0, len(lines))
+		for _, line := range lines {
+			change.Removed = append(change.Removed, line.rule())
+		}
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.changed(ctx, change)
+
+	return change.Removed, nil
 }
 
+// LoadFilteredPolicy loads only policy rules that match the filter, which must be a *Filter.
 func (a *Adapter) LoadFilteredPolicy(model model.Model, filter interface{}) error {
+	return a.LoadFilteredPolicyCtx(context.Background(), model, filter)
+}
+
+// LoadFilteredPolicyCtx is like LoadFilteredPolicy, ctx bounds the database calls.
+func (a *Adapter) LoadFilteredPolicyCtx(ctx context.Context, model model.Model, filter interface{}) error {
+	return a.doContext(ctx, &Operation{Name: OpLoadFilteredPolicy}, func(ctx context.Context) error {
+		return a.loadFilteredPolicyModel(ctx, model, filter)
+	})
+}
+
+func (a *Adapter) loadFilteredPolicyModel(ctx context.Context, model model.Model, filter interface{}) (err error) {
+	defer a.handleError(OpLoadFilteredPolicy, "", 0, &err)
+
 	if filter == nil {
-		return a.LoadPolicy(model)
+		return a.loadPolicy(ctx, model)
 	}
 
 	filterValue, ok := filter.(*Filter)
 	if !ok {
 		return fmt.Errorf("invalid filter type")
 	}
-	err := a.loadFilteredPolicy(model, filterValue, persist.LoadPolicyLine)
+	err = a.loadFilteredPolicy(ctx, model, filterValue, persist.LoadPolicyLine)
 	if err != nil {
 		return err
 	}
@@ -401,6 +1209,37 @@ func (a *Adapter) LoadFilteredPolicy(model model.Model, filter interface{}) erro
 	return nil
 }
 
+// filterSection is the field values the rules of ptype must match, see Filter.
+type filterSection struct {
+	ptype  string
+	values []string
+}
+
+// sections returns the ptypes of the filter with their field values, the ptypes without values are left out.
+func (f *Filter) sections() []filterSection {
+	var secs []filterSection
+	if f.P != nil {
+		secs = append(secs, filterSection{"p", f.P})
+	}
+	if f.G != nil {
+		secs = append(secs, filterSection{"g", f.G})
+	}
+	ptypes := make([]string, 0, len(f.Ptypes))
+	for ptype := range f.Ptypes {
+		ptypes = append(ptypes, ptype)
+	}
+	sort.Strings(ptypes)
+	for _, ptype := range ptypes {
+		if (ptype == "p" && f.P != nil) || (ptype == "g" && f.G != nil) {
+			continue
+		}
+		if values := f.Ptypes[ptype]; values != nil {
+			secs = append(secs, filterSection{ptype, values})
+		}
+	}
+	return secs
+}
+
 func buildQuery(query *orm.Query, values []string) (*orm.Query, error) {
 	for ind, v := range values {
 		if v == "" {
@@ -420,75 +1259,141 @@ func buildQuery(query *orm.Query, values []string) (*orm.Query, error) {
 		case 5:
 			query = query.Where("v5 = ?", v)
 		default:
-			return nil, fmt.Errorf("filter has more values than expected, should not exceed 6 values")
+			return nil, ErrTooManyFields
 		}
 	}
 	return query, nil
 }
 
-func (a *Adapter) loadFilteredPolicy(model model.Model, filter *Filter, handler func(string, model.Model) error) error {
-	if filter.P != nil {
-		lines := []*CasbinRule{}
-
-		query := a.db.Model(&lines).Table(a.tableName).Where("ptype = 'p'")
-		query, err := buildQuery(query, filter.P)
-		if err != nil {
-			return err
-		}
-		err = query.Select()
-		if err != nil {
-			return err
-		}
-
-		for _, line := range lines {
-			handler(line.String(), model)
-		}
+func (a *Adapter) loadFilteredPolicy(ctx context.Context, model model.Model, filter *Filter, handler func(string, model.Model) error) error {
+	filter = a.foldFilter(filter)
+	key, err := json.Marshal(filter)
+	if err != nil {
+		return err
 	}
-	if filter.G != nil {
-		lines := []*CasbinRule{}
-
-		query := a.db.Model(&lines).Table(a.tableName).Where("ptype = 'g'")
-		query, err := buildQuery(query, filter.G)
-		if err != nil {
-			return err
-		}
-		err = query.Select()
-		if err != nil {
-			return err
-		}
-
-		for _, line := range lines {
-			handler(line.String(), model)
+	rows := 0
+	defer func() { countRows(ctx, rows) }()
+	return a.cachedLoad(string(key), func(line string) error {
+		rows++
+		handler(line, model)
+		return nil
+	}, func(handler func(string) error) error {
+		skipped := skippedRules{}
+		for _, sec := range filter.sections() {
+			query := a.loadQuery(a.readConn().ModelContext(ctx, (*CasbinRule)(nil)).Table(a.tableName).Where("ptype = ?", sec.ptype))
+			query = labelQuery(query, filter.Labels)
+			query, err := buildQuery(query, sec.values)
+			if err != nil {
+				return err
+			}
+			err = query.ForEach(func(line *CasbinRule) error {
+				if a.loadAllowed(line.Ptype, skipped) {
+					return handler(line.String())
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
 		}
-	}
-	return nil
+		a.reportSkipped(OpLoadFilteredPolicy, skipped)
+		return nil
+	})
 }
 
 func (a *Adapter) IsFiltered() bool {
 	return a.filtered
 }
 
+// IsFilteredCtx is like IsFiltered.
+func (a *Adapter) IsFilteredCtx(ctx context.Context) bool {
+	return a.filtered
+}
+
 // UpdatePolicy updates a policy rule from storage.
 // This is part of the Auto-Save feature.
 func (a *Adapter) UpdatePolicy(sec string, ptype string, oldRule, newPolicy []string) error {
-	return a.UpdatePolicies(sec, ptype, [][]string{oldRule}, [][]string{newPolicy})
+	return a.UpdatePolicyCtx(context.Background(), sec, ptype, oldRule, newPolicy)
+}
+
+// UpdatePolicyCtx is like UpdatePolicy, ctx bounds the database calls.
+func (a *Adapter) UpdatePolicyCtx(ctx context.Context, sec string, ptype string, oldRule, newPolicy []string) error {
+	op := &Operation{Name: OpUpdatePolicy, Sec: sec, Ptype: ptype, Rules: [][]string{newPolicy}, OldRules: [][]string{oldRule}}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.updatePolicyRules(ctx, OpUpdatePolicy, sec, ptype, [][]string{oldRule}, [][]string{newPolicy})
+	})
 }
 
 // UpdatePolicies updates some policy rules to storage, like db, redis.
 func (a *Adapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return a.UpdatePoliciesCtx(context.Background(), sec, ptype, oldRules, newRules)
+}
+
+// UpdatePoliciesCtx is like UpdatePolicies, ctx bounds the database calls.
+func (a *Adapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype string, oldRules, newRules [][]string) error {
+	op := &Operation{Name: OpUpdatePolicies, Sec: sec, Ptype: ptype, Rules: newRules, OldRules: oldRules}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.updatePolicyRules(ctx, OpUpdatePolicies, sec, ptype, oldRules, newRules)
+	})
+}
+
+func (a *Adapter) updatePolicyRules(ctx context.Context, op string, sec string, ptype string, oldRules, newRules [][]string) (err error) {
+	defer a.handleError(op, ptype, len(newRules), &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.beforeWrite(op, sec, ptype, newRules); err != nil {
+		return err
+	}
+
 	oldLines := make([]*CasbinRule, 0, len(oldRules))
 	newLines := make([]*CasbinRule, 0, len(newRules))
 	for _, rule := range oldRules {
-		oldLines = append(oldLines, savePolicyLine(ptype, rule))
+		oldLines = append(oldLines, a.ruleLine(ptype, rule))
 	}
 	for _, rule := range newRules {
-		newLines = append(newLines, savePolicyLine(ptype, rule))
+		newLines = append(newLines, a.ruleLine(ptype, rule))
+	}
+
+	change := PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: newRules, OldRules: oldRules}
+	if err := a.updatePolicies(ctx, oldLines, newLines, change); err != nil {
+		return err
 	}
 
-	return a.updatePolicies(oldLines, newLines)
+	a.changed(ctx, change)
+
+	return nil
 }
 
+// UpdateFilteredPolicies deletes the rules matching the filter, adds newPolicies and returns the deleted rules.
+// The deleted rules are returned without their ptype, like the rules passed to the adapter.
 func (a *Adapter) UpdateFilteredPolicies(sec string, ptype string, newPolicies [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	return a.UpdateFilteredPoliciesCtx(context.Background(), sec, ptype, newPolicies, fieldIndex, fieldValues...)
+}
+
+// UpdateFilteredPoliciesCtx is like UpdateFilteredPolicies, ctx bounds the database calls.
+func (a *Adapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string, ptype string, newPolicies [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	op := &Operation{Name: OpUpdateFilteredPolicies, Sec: sec, Ptype: ptype, Rules: newPolicies, FieldIndex: fieldIndex}
+	err := a.doContext(ctx, op, func(ctx context.Context) error {
+		var err error
+		op.Result, err = a.updateFilteredPolicies(ctx, sec, ptype, newPolicies, fieldIndex, fieldValues...)
+		return err
+	})
+	return op.Result, err
+}
+
+func (a *Adapter) updateFilteredPolicies(ctx context.Context, sec string, ptype string, newPolicies [][]string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer a.handleError(OpUpdateFilteredPolicies, ptype, len(newPolicies), &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.beforeWrite(OpUpdateFilteredPolicies, sec, ptype, newPolicies); err != nil {
+		return nil, err
+	}
+	fieldValues = a.fold(fieldValues)
+
 	line := &CasbinRule{}
 
 	line.Ptype = ptype
@@ -514,35 +1419,52 @@ func (a *Adapter) UpdateFilteredPolicies(sec string, ptype string, newPolicies [
 	newP := make([]CasbinRule, 0, len(newPolicies))
 	oldP := make([]CasbinRule, 0)
 	for _, newRule := range newPolicies {
-		newP = append(newP, *(savePolicyLine(ptype, newRule)))
+		newP = append(newP, *(a.ruleLine(ptype, newRule)))
 	}
 
-	err := a.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+	change := PolicyChange{
+		Operation:  OpUpdateFilteredPolicies,
+		Sec:        sec,
+		Ptype:      ptype,
+		Rules:      newPolicies,
+		FieldIndex: fieldIndex,
+	}
+	// Rows are locked and inserted in id order, so concurrent updates of overlapping rules are less likely to deadlock.
+	sort.Slice(newP, func(i, j int) bool { return newP[i].ID < newP[j].ID })
+	err = a.runInTransactionRetry(ctx, func(tx *pg.Tx) error {
+		str, args := line.queryString()
+		_, err := tx.Exec("SELECT id FROM ? WHERE "+str+" ORDER BY id FOR UPDATE", append([]interface{}{pg.Ident(a.tableName)}, args...)...)
+		if err != nil {
+			return err
+		}
+		oldP = oldP[:0]
+		_, err = tx.Model(&oldP).Table(a.tableName).Where(str, args...).Returning("*").Delete()
+		if err != nil {
+			return err
+		}
 		for i := range newP {
-			str, args := line.queryString()
-			_, err := tx.Model(&oldP).Table(a.tableName).Where(str, args...).Delete()
-			if err != nil {
-				tx.Rollback()
-				return err
-			}
 			_, err = tx.Model(&newP[i]).Table(a.tableName).
 				OnConflict("DO NOTHING").
 				Insert()
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
 		}
-		return nil
-	})
 
-	// return deleted rulues
-	oldPolicies := make([][]string, 0)
-	for _, v := range oldP {
-		oldPolicy := v.toStringPolicy()
-		oldPolicies = append(oldPolicies, oldPolicy)
+		// return deleted rules
+		change.OldRules = make([][]string, 0, len(oldP))
+		for _, v := range oldP {
+			change.OldRules = append(change.OldRules, v.rule())
+		}
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return oldPolicies, err
+
+	a.changed(ctx, change)
+
+	return change.OldRules, nil
 }
 
 func (c *CasbinRule) queryString() (string, []interface{}) {
@@ -603,24 +1525,66 @@ func (c *CasbinRule) toStringPolicy() []string {
 	return policy
 }
 
-func (a *Adapter) updatePolicies(oldLines, newLines []*CasbinRule) error {
-	tx, err := a.db.Begin()
-	if err != nil {
-		return err
+// field returns the value of the column v<i>.
+func (c *CasbinRule) field(i int) string {
+	switch i {
+	case 0:
+		return c.V0
+	case 1:
+		return c.V1
+	case 2:
+		return c.V2
+	case 3:
+		return c.V3
+	case 4:
+		return c.V4
+	case 5:
+		return c.V5
+	}
+	return ""
+}
+
+// rule returns the values of the columns v0 to v5 without the trailing empty ones.
+func (c *CasbinRule) rule() []string {
+	values := c.values()
+	for len(values) > 0 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
 	}
-	defer tx.Close()
+	return values
+}
 
-	for i, line := range oldLines {
-		str, args := line.queryString()
-		_, err = tx.Model(newLines[i]).Table(a.tableName).Where(str, args...).Update()
-		if err != nil {
-			tx.Rollback()
-			return err
+// values returns the values of the columns v0 to v5.
+func (c *CasbinRule) values() []string {
+	return []string{c.V0, c.V1, c.V2, c.V3, c.V4, c.V5}
+}
+
+// matches reports whether every non-empty value equals the corresponding column, like buildQuery.
+func (c *CasbinRule) matches(values []string) bool {
+	for i, v := range values {
+		if v != "" && c.field(i) != v {
+			return false
 		}
 	}
+	return true
+}
 
-	if err = tx.Commit(); err != nil {
-		return err
+func (a *Adapter) updatePolicies(ctx context.Context, oldLines, newLines []*CasbinRule, change PolicyChange) error {
+	// Rows are updated in id order, so concurrent updates of overlapping rules are less likely to deadlock.
+	order := make([]int, len(oldLines))
+	for i := range order {
+		order[i] = i
 	}
-	return nil
+	sort.SliceStable(order, func(i, j int) bool { return oldLines[order[i]].ID < oldLines[order[j]].ID })
+
+	return a.runInTransactionRetry(ctx, func(tx *pg.Tx) error {
+		for _, i := range order {
+			str, args := oldLines[i].queryString()
+			// The ID is set too, it is derived from the rule.
+			_, err := tx.Model(newLines[i]).Table(a.tableName).Column(ruleColumns...).Where(str, args...).Update()
+			if err != nil {
+				return err
+			}
+		}
+		return a.recordChange(ctx, tx, change)
+	})
 }
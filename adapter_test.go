@@ -1,10 +1,13 @@
 package pgadapter
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
 	"github.com/casbin/casbin/v2/util"
 	"github.com/go-pg/pg/v10"
 	"github.com/stretchr/testify/suite"
@@ -15,6 +18,8 @@ type AdapterTestSuite struct {
 	suite.Suite
 	e *casbin.Enforcer
 	a *Adapter
+	// opts are the options of the adapter under test, which then works on its own table.
+	opts []Option
 }
 
 func (s *AdapterTestSuite) assertPolicy(expected, res [][]string) {
@@ -36,13 +41,12 @@ func (s *AdapterTestSuite) SetupTest() {
 	var err error
 	s.a, err = NewAdapter(os.Getenv("PG_CONN"))
 	s.Require().NoError(err)
+	if len(s.opts) > 0 {
+		s.a, err = NewAdapterByDB(s.a.db, s.opts...)
+		s.Require().NoError(err)
+	}
 
-	// This is a trick to save the current policy to the DB.
-	// We can't call e.SavePolicy() because the adapter in the enforcer is still the file adapter.
-	// The current policy means the policy in the Casbin enforcer (aka in memory).
-	e, err := casbin.NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
-	s.Require().NoError(err)
-	err = s.a.SavePolicy(e.GetModel())
+	err = SeedPoliciesFromFile(context.Background(), s.a.db, "examples/rbac_policy.csv", s.opts...)
 	s.Require().NoError(err)
 
 	s.e, err = casbin.NewEnforcer("examples/rbac_model.conf", s.a)
@@ -300,6 +304,17 @@ func (s *AdapterTestSuite) TestUpdatePolicy() {
 	s.Require().NoError(err)
 
 	s.assertPolicy(s.e.GetPolicy(), [][]string{{"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"bob", "data1", "read"}, {"alice", "data2", "write"}})
+
+	// The updated rows get the ID of their new rule, so they can be removed by it.
+	_, err = s.e.RemovePolicy("bob", "data1", "read")
+	s.Require().NoError(err)
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy(s.e.GetPolicy(), [][]string{{"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"alice", "data2", "write"}})
+}
+
+func (s *AdapterTestSuite) TestUpdatePolicyConflict() {
+	err := s.a.UpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"bob", "data2", "write"})
+	s.Require().ErrorIs(err, ErrPolicyExists)
 }
 
 func (s *AdapterTestSuite) TestUpdatePolicyWithLoadFilteredPolicy() {
@@ -335,8 +350,9 @@ func (s *AdapterTestSuite) TestUpdateFilteredPolicies() {
 	err = s.e.SavePolicy()
 	s.Require().NoError(err)
 
-	_, err = s.a.UpdateFilteredPolicies("p", "p", [][]string{{"alice", "data2", "write"}}, 0, "alice", "data1", "read")
+	removed, err := s.a.UpdateFilteredPolicies("p", "p", [][]string{{"alice", "data2", "write"}}, 0, "alice", "data1", "read")
 	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"alice", "data1", "read"}}, removed)
 	_, err = s.a.UpdateFilteredPolicies("p", "p", [][]string{{"bob", "data1", "read"}}, 0, "bob", "data2", "write")
 	s.Require().NoError(err)
 
@@ -345,6 +361,111 @@ func (s *AdapterTestSuite) TestUpdateFilteredPolicies() {
 
 	s.assertPolicy(s.e.GetPolicy(), [][]string{{"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"alice", "data2", "write"}, {"bob", "data1", "read"}})
 }
+
+// TestUpdateFilteredPoliciesResult checks that the Adapter and the Fake return the same deleted rules.
+func (s *AdapterTestSuite) TestUpdateFilteredPoliciesResult() {
+	f := NewFake()
+	s.Require().NoError(f.SavePolicy(s.e.GetModel()))
+
+	for _, a := range []persist.UpdatableAdapter{s.a, f} {
+		removed, err := a.UpdateFilteredPolicies("p", "p", [][]string{{"carol", "data2", "read"}}, 1, "data2")
+		s.Require().NoError(err)
+		s.Require().ElementsMatch([][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, removed, "%T", a)
+	}
+}
+
+func (s *AdapterTestSuite) TestRemoveFilteredPolicyReturning() {
+	ch, err := s.a.Subscribe(context.Background())
+	s.Require().NoError(err)
+
+	removed, err := s.a.RemoveFilteredPolicyReturning("p", "p", 0, "data2_admin")
+	s.Require().NoError(err)
+	s.assertPolicy([][]string{{"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, removed)
+
+	change := <-ch
+	s.Require().Equal(OpRemoveFilteredPolicy, change.Operation)
+	s.assertPolicy(removed, change.Removed)
+}
+
+func (s *AdapterTestSuite) TestAddPoliciesReturning() {
+	inserted, err := s.a.AddPoliciesReturning("p", "p", [][]string{{"alice", "data1", "read"}, {"carol", "data1", "read"}, {"carol", "data1", "read"}})
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"carol", "data1", "read"}}, inserted)
+}
+
+func (s *AdapterTestSuite) TestLoadPolicyLarge() {
+	rules := make([][]string, 5000)
+	for i := range rules {
+		rules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+	}
+	_, err := s.e.AddPolicies(rules)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().Len(s.e.GetPolicy(), 5004)
+}
+
+func (s *AdapterTestSuite) TestRemovePoliciesLarge() {
+	rules := make([][]string, 2500)
+	for i := range rules {
+		rules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+	}
+	s.Require().NoError(s.a.AddPolicies("p", "p", rules))
+	s.Require().NoError(s.a.RemovePolicies("p", "p", append(rules, []string{"alice", "data1", "read"})))
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().Len(s.e.GetPolicy(), 3)
+}
+
+func (s *AdapterTestSuite) TestContextMethods() {
+	ctx := context.Background()
+	s.Require().NoError(s.a.AddPolicyCtx(ctx, "p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(s.a.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "alice"))
+	s.Require().NoError(s.a.LoadPolicyCtx(ctx, s.e.GetModel()))
+	s.Require().True(s.e.HasPolicy("carol", "data1", "read"))
+	s.Require().False(s.e.HasPolicy("alice", "data1", "read"))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err := s.a.LoadPolicyCtx(canceled, s.e.GetModel())
+	s.Require().ErrorIs(err, context.Canceled)
+	err = s.a.RemovePolicyCtx(canceled, "p", "p", []string{"carol", "data1", "read"})
+	s.Require().ErrorIs(err, context.Canceled)
+
+	ok, err := s.a.HasPolicy(ctx, "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok, "the canceled removal has not been applied")
+}
+
+// runCoreTests runs the tests of the core adapter methods with the options of s.
+func runCoreTests(t *testing.T, s *AdapterTestSuite) {
+	tests := map[string]func(){
+		"SaveLoad":                           s.TestSaveLoad,
+		"AutoSave":                           s.TestAutoSave,
+		"RemovePolicy":                       s.TestRemovePolicy,
+		"RemoveFilteredPolicy":               s.TestRemoveFilteredPolicy,
+		"LoadFilteredPolicy":                 s.TestLoadFilteredPolicy,
+		"LoadFilteredGroupingPolicy":         s.TestLoadFilteredGroupingPolicy,
+		"LoadFilteredPolicyNilFilter":        s.TestLoadFilteredPolicyNilFilter,
+		"SavePolicyClearPreviousData":        s.TestSavePolicyClearPreviousData,
+		"UpdatePolicy":                       s.TestUpdatePolicy,
+		"UpdatePolicyWithLoadFilteredPolicy": s.TestUpdatePolicyWithLoadFilteredPolicy,
+		"UpdateFilteredPolicies":             s.TestUpdateFilteredPolicies,
+		"RemoveFilteredPolicyReturning":      s.TestRemoveFilteredPolicyReturning,
+		"AddPoliciesReturning":               s.TestAddPoliciesReturning,
+		"LoadPolicyLarge":                    s.TestLoadPolicyLarge,
+		"ContextMethods":                     s.TestContextMethods,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s.SetT(t)
+			s.SetupTest()
+			defer s.TearDownTest()
+			test()
+		})
+	}
+}
+
 func TestAdapterTestSuite(t *testing.T) {
 	suite.Run(t, new(AdapterTestSuite))
 }
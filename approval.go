@@ -0,0 +1,220 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DefaultPendingTableName is the table holding the proposed changes, see WithApprovals.
+const DefaultPendingTableName = "casbin_rule_pending"
+
+// Status of a PendingChange.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Changeset is a set of rule changes applied at once, each rule starts with its ptype.
+type Changeset struct {
+	Add    [][]string `json:"add,omitempty"`
+	Remove [][]string `json:"remove,omitempty"`
+}
+
+// PendingChange is a Changeset proposed with ProposeChanges and its review.
+type PendingChange struct {
+	tableName  struct{}  `pg:"casbin_rule_pending"`
+	ID         int64     `pg:",pk"`
+	RuleTable  string    `pg:",notnull"`
+	Changes    Changeset `pg:"type:jsonb,notnull"`
+	Status     string    `pg:",notnull"`
+	ProposedBy string    `pg:",notnull"`
+	ProposedAt time.Time `pg:"default:now(),notnull"`
+	ReviewedBy string
+	ReviewedAt time.Time
+}
+
+// WithApprovals creates the pending table, so changes can be proposed with ProposeChanges and applied once
+// someone else approved them with Approve, e.g. to enforce a four-eyes review of the permission changes.
+// The adapter doesn't prevent direct writes, restrict them with the database permissions if needed.
+func WithApprovals() Option {
+	return func(a *Adapter) {
+		a.approvals = true
+	}
+}
+
+func (a *Adapter) createPendingTable() error {
+	return a.conn().Model((*PendingChange)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+}
+
+func (a *Adapter) checkApprovals() error {
+	if !a.approvals {
+		return errors.New("approvals are not enabled, see WithApprovals")
+	}
+	return nil
+}
+
+// ProposeChanges stores changes for review and returns the ID of the pending change.
+// The rules are checked like the rules of a write, so invalid changes are rejected right away.
+func (a *Adapter) ProposeChanges(ctx context.Context, proposer string, changes Changeset) (_ int64, err error) {
+	defer a.handleError(OpProposeChanges, "", len(changes.Add)+len(changes.Remove), &err)
+
+	if err := a.checkApprovals(); err != nil {
+		return 0, err
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+	if _, _, err := a.changesetLines(OpProposeChanges, changes); err != nil {
+		return 0, err
+	}
+
+	p := &PendingChange{RuleTable: a.tableName, Changes: changes, Status: StatusPending, ProposedBy: proposer}
+	if _, err := a.conn().ModelContext(ctx, p).Returning("id").Insert(); err != nil {
+		return 0, err
+	}
+	return p.ID, nil
+}
+
+// ListPending returns the changes waiting for a review, oldest first.
+func (a *Adapter) ListPending(ctx context.Context) (_ []PendingChange, err error) {
+	defer a.handleError(OpListPending, "", 0, &err)
+
+	if err := a.checkApprovals(); err != nil {
+		return nil, err
+	}
+
+	var pending []PendingChange
+	err = a.conn().ModelContext(ctx, &pending).
+		Where("rule_table = ?", a.tableName).
+		Where("status = ?", StatusPending).
+		Order("id").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// Approve applies the pending change id in a single transaction and records approver as its reviewer.
+// It fails with ErrSelfApproval if approver proposed the change and with ErrNotFound if the change
+// doesn't exist or has already been reviewed. The change is published as a single Approve change.
+func (a *Adapter) Approve(ctx context.Context, id int64, approver string) error {
+	return a.doContext(ctx, &Operation{Name: OpApprove}, func(ctx context.Context) error {
+		return a.approve(ctx, id, approver)
+	})
+}
+
+func (a *Adapter) approve(ctx context.Context, id int64, approver string) (err error) {
+	defer a.handleError(OpApprove, "", 0, &err)
+
+	if err := a.checkApprovals(); err != nil {
+		return err
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	change := PolicyChange{Operation: OpApprove}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		p, err := a.reviewPending(ctx, tx, id, approver, StatusApproved)
+		if err != nil {
+			return err
+		}
+		added, removed, err := a.changesetLines(OpApprove, p.Changes)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range removed {
+			if _, err := tx.Model(line).Table(a.tableName).WherePK().Delete(); err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			if _, err := tx.Model(&added).Table(a.tableName).OnConflict("DO NOTHING").Insert(); err != nil {
+				return err
+			}
+			if err := a.checkQuota(tx, added); err != nil {
+				return err
+			}
+		}
+
+		change.Rules, change.Removed = p.Changes.Add, p.Changes.Remove
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.changed(ctx, change)
+	return nil
+}
+
+// Reject discards the pending change id and records reviewer as its reviewer.
+func (a *Adapter) Reject(ctx context.Context, id int64, reviewer string) (err error) {
+	defer a.handleError(OpReject, "", 0, &err)
+
+	if err := a.checkApprovals(); err != nil {
+		return err
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	return a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := a.reviewPending(ctx, tx, id, reviewer, StatusRejected)
+		return err
+	})
+}
+
+// reviewPending locks the pending change id and sets its status within tx.
+func (a *Adapter) reviewPending(ctx context.Context, tx *pg.Tx, id int64, reviewer, status string) (*PendingChange, error) {
+	p := &PendingChange{ID: id}
+	err := tx.ModelContext(ctx, p).
+		WherePK().
+		Where("rule_table = ?", a.tableName).
+		Where("status = ?", StatusPending).
+		For("UPDATE").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	if status == StatusApproved && p.ProposedBy == reviewer {
+		return nil, ErrSelfApproval
+	}
+
+	p.Status, p.ReviewedBy, p.ReviewedAt = status, reviewer, time.Now()
+	if _, err := tx.ModelContext(ctx, p).Column("status", "reviewed_by", "reviewed_at").WherePK().Update(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// changesetLines checks the rules of changes and returns them as lines.
+func (a *Adapter) changesetLines(op string, changes Changeset) (added, removed []*CasbinRule, err error) {
+	for _, rules := range [][][]string{changes.Add, changes.Remove} {
+		for _, rule := range rules {
+			if len(rule) < 2 || rule[0] == "" {
+				return nil, nil, fmt.Errorf("invalid rule %q, rules must start with their ptype", rule)
+			}
+			if len(rule) > 7 {
+				return nil, nil, ErrTooManyFields
+			}
+		}
+	}
+	if added, err = a.promoteLines(op, changes.Add); err != nil {
+		return nil, nil, err
+	}
+	if removed, err = a.promoteLines(op, changes.Remove); err != nil {
+		return nil, nil, err
+	}
+	return added, removed, nil
+}
@@ -0,0 +1,67 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangesetLines(t *testing.T) {
+	a := &Adapter{}
+
+	added, removed, err := a.changesetLines(OpProposeChanges, Changeset{
+		Add:    [][]string{{"p", "carol", "data1", "read"}, {"g", "carol", "admin"}},
+		Remove: [][]string{{"p", "alice", "data1", "read"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, added, 2)
+	require.Equal(t, "g", added[1].Ptype)
+	require.Equal(t, []string{"alice", "data1", "read"}, removed[0].rule())
+
+	_, _, err = a.changesetLines(OpProposeChanges, Changeset{Add: [][]string{{"alice", "data1", "read"}, {"p"}}})
+	require.Error(t, err)
+
+	a = &Adapter{allowedPtypes: map[string]bool{"p": true}}
+	_, _, err = a.changesetLines(OpProposeChanges, Changeset{Remove: [][]string{{"g", "alice", "admin"}}})
+	require.True(t, errors.Is(err, ErrPtypeNotAllowed))
+}
+
+func (s *AdapterTestSuite) TestApprovals() {
+	a, err := NewAdapterByDB(s.a.db, WithApprovals())
+	s.Require().NoError(err)
+
+	id, err := a.ProposeChanges(context.Background(), "alice", Changeset{
+		Add:    [][]string{{"p", "carol", "data1", "read"}},
+		Remove: [][]string{{"p", "bob", "data2", "write"}},
+	})
+	s.Require().NoError(err)
+
+	pending, err := a.ListPending(context.Background())
+	s.Require().NoError(err)
+	s.Require().Len(pending, 1)
+	s.Require().Equal("alice", pending[0].ProposedBy)
+
+	err = a.Approve(context.Background(), id, "alice")
+	s.Require().True(errors.Is(err, ErrSelfApproval))
+
+	s.Require().NoError(a.Approve(context.Background(), id, "bob"))
+	ok, err := a.HasPolicy(context.Background(), "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	ok, err = a.HasPolicy(context.Background(), "p", []string{"bob", "data2", "write"})
+	s.Require().NoError(err)
+	s.Require().False(ok)
+
+	err = a.Approve(context.Background(), id, "bob")
+	s.Require().True(errors.Is(err, ErrNotFound), "a change is applied only once")
+
+	id, err = a.ProposeChanges(context.Background(), "alice", Changeset{Add: [][]string{{"p", "dave", "data1", "read"}}})
+	s.Require().NoError(err)
+	s.Require().NoError(a.Reject(context.Background(), id, "bob"))
+
+	pending, err = a.ListPending(context.Background())
+	s.Require().NoError(err)
+	s.Require().Empty(pending)
+}
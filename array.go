@@ -0,0 +1,54 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// WithArrayColumn adds a rule text[] column to the rules table holding the non-empty values of each rule,
+// generated by Postgres from the value columns, with a GIN index. It allows subset queries on values
+// at any position, e.g. rule @> ARRAY['alice'], see PoliciesContaining and QueryRules.
+// The column requires Postgres 12 or later.
+func WithArrayColumn() Option {
+	return func(a *Adapter) {
+		a.arrayColumn = true
+	}
+}
+
+func (a *Adapter) createArrayColumn() error {
+	_, err := a.conn().Exec(`ALTER TABLE ? ADD COLUMN IF NOT EXISTS rule text[]
+		GENERATED ALWAYS AS (array_remove(ARRAY[v0, v1, v2, v3, v4, v5], NULL)) STORED`, pg.Ident(a.tableName))
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? USING gin (rule)",
+		pg.Ident(unqualified(a.tableName)+"_rule_idx"), pg.Ident(a.tableName))
+	return err
+}
+
+// PoliciesContaining returns the rules having all values, at any position, in canonical order, see ExportPolicies.
+// Each returned rule starts with its ptype. It requires WithArrayColumn.
+func (a *Adapter) PoliciesContaining(ctx context.Context, values ...string) (_ [][]string, err error) {
+	defer a.handleError(OpPoliciesContaining, "", 0, &err)
+
+	if !a.arrayColumn {
+		return nil, errors.New("the array column is not enabled, see WithArrayColumn")
+	}
+
+	var lines []*CasbinRule
+	err = a.conn().ModelContext(ctx, &lines).Table(a.tableName).
+		Where("rule @> ?::text[]", pg.Array(values)).
+		OrderExpr(canonicalOrder).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.ptypeRule())
+	}
+	return rules, nil
+}
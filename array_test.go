@@ -0,0 +1,21 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestPoliciesContaining() {
+	a, err := NewAdapterByDB(s.a.db, WithArrayColumn())
+	s.Require().NoError(err)
+
+	rules, err := a.PoliciesContaining(context.Background(), "data2", "write")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "bob", "data2", "write"}, {"p", "data2_admin", "data2", "write"}}, rules)
+
+	rules, err = a.PoliciesContaining(context.Background(), "data2_admin")
+	s.Require().NoError(err)
+	s.Require().Len(rules, 3)
+
+	_, err = s.a.PoliciesContaining(context.Background(), "alice")
+	s.Require().Error(err)
+}
@@ -0,0 +1,101 @@
+package pgadapter
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DefaultAuditTableName is the table holding the audit log, see WithAuditLog.
+const DefaultAuditTableName = "casbin_rule_audit"
+
+// AuditEntry is a row of the audit log, it records a rule changed by an operation.
+type AuditEntry struct {
+	tableName struct{} `pg:"casbin_rule_audit"`
+	ID        int64    `pg:",pk"`
+	RuleTable string   `pg:",notnull"`
+	Operation string   `pg:",notnull"`
+	Ptype     string
+	// OldRule is the rule removed or replaced, NewRule the rule added or the replacement.
+	OldRule   []string `pg:",array"`
+	NewRule   []string `pg:",array"`
+	Actor     string
+	CreatedAt time.Time `pg:"default:now(),notnull"`
+}
+
+// WithAuditLog creates the audit log table and makes every write record the rules it changed into it
+// within the transaction of the write, one entry per rule with its old and new value, so the table answers
+// who changed which rule when. The actor is the one of ContextWithActor or WithActor.
+// The other operations, like SavePolicy replacing the whole policy, record a single entry without rules.
+// Entries are never deleted by the adapter.
+func WithAuditLog() Option {
+	return func(a *Adapter) {
+		a.auditLog = true
+	}
+}
+
+func (a *Adapter) createAuditTable() error {
+	err := a.conn().Model((*AuditEntry)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? (rule_table, created_at)",
+		pg.Ident(DefaultAuditTableName+"_rule_table_idx"), pg.Ident(DefaultAuditTableName))
+	return err
+}
+
+// audit records change into the audit log within tx if WithAuditLog is set.
+func (a *Adapter) audit(tx *pg.Tx, change PolicyChange) error {
+	if !a.auditLog {
+		return nil
+	}
+	entries := auditEntries(a.tableName, change)
+	_, err := tx.Model(&entries).Insert()
+	return err
+}
+
+// auditEntries returns the audit log entries of change.
+func auditEntries(table string, change PolicyChange) []*AuditEntry {
+	entry := func(oldRule, newRule []string) *AuditEntry {
+		return &AuditEntry{
+			RuleTable: table, Operation: change.Operation, Ptype: change.Ptype,
+			OldRule: oldRule, NewRule: newRule, Actor: change.Actor,
+		}
+	}
+
+	var entries []*AuditEntry
+	switch change.Operation {
+	case OpAddPolicy, OpAddPolicies:
+		for _, rule := range change.Rules {
+			entries = append(entries, entry(nil, rule))
+		}
+	case OpRemovePolicy, OpRemovePolicies:
+		for _, rule := range change.Rules {
+			entries = append(entries, entry(rule, nil))
+		}
+	case OpRemoveFilteredPolicy:
+		for _, rule := range change.Removed {
+			entries = append(entries, entry(rule, nil))
+		}
+	case OpUpdatePolicy, OpUpdatePolicies:
+		for i, rule := range change.Rules {
+			if i < len(change.OldRules) {
+				entries = append(entries, entry(change.OldRules[i], rule))
+			}
+		}
+	case OpUpdateFilteredPolicies:
+		for _, rule := range change.OldRules {
+			entries = append(entries, entry(rule, nil))
+		}
+		for _, rule := range change.Rules {
+			entries = append(entries, entry(nil, rule))
+		}
+	}
+	if len(entries) == 0 {
+		entries = append(entries, entry(nil, nil))
+	}
+	return entries
+}
@@ -0,0 +1,46 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEntries(t *testing.T) {
+	entries := auditEntries("casbin_rule", PolicyChange{
+		Operation: OpUpdatePolicies, Ptype: "p", Actor: "jane",
+		OldRules: [][]string{{"alice", "data1", "read"}},
+		Rules:    [][]string{{"alice", "data1", "write"}},
+	})
+	require.Len(t, entries, 1)
+	require.Equal(t, []string{"alice", "data1", "read"}, entries[0].OldRule)
+	require.Equal(t, []string{"alice", "data1", "write"}, entries[0].NewRule)
+	require.Equal(t, "jane", entries[0].Actor)
+
+	entries = auditEntries("casbin_rule", PolicyChange{Operation: OpRemoveFilteredPolicy, Ptype: "p", Removed: [][]string{{"bob"}, {"carol"}}})
+	require.Len(t, entries, 2)
+	require.Nil(t, entries[1].NewRule)
+
+	entries = auditEntries("casbin_rule", PolicyChange{Operation: OpSavePolicy})
+	require.Len(t, entries, 1)
+	require.Equal(t, OpSavePolicy, entries[0].Operation)
+}
+
+func (s *AdapterTestSuite) TestAuditLog() {
+	a, err := NewAdapterByDB(s.a.db, WithAuditLog(), WithActor("billing-service"))
+	s.Require().NoError(err)
+
+	ctx := ContextWithActor(context.Background(), "jane")
+	s.Require().NoError(a.AddPoliciesCtx(ctx, "p", "p", [][]string{{"carol", "data1", "read"}}))
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"carol", "data1", "read"}, []string{"carol", "data1", "write"}))
+
+	var entries []AuditEntry
+	s.Require().NoError(s.a.db.Model(&entries).Order("id").Select())
+	s.Require().Len(entries, 2)
+	s.Require().Equal(OpAddPolicies, entries[0].Operation)
+	s.Require().Equal("jane", entries[0].Actor)
+	s.Require().Equal([]string{"carol", "data1", "read"}, entries[0].NewRule)
+	s.Require().Equal("billing-service", entries[1].Actor)
+	s.Require().Equal([]string{"carol", "data1", "read"}, entries[1].OldRule)
+}
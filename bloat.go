@@ -0,0 +1,117 @@
+package pgadapter
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// bloatDeadRatio is the share of dead rows above which BloatReport recommends vacuuming and tuning autovacuum.
+const bloatDeadRatio = 0.2
+
+// suggestedAutovacuumSettings make autovacuum process the rules table long before the default 20% of dead rows,
+// which SavePolicy reaches on every call since it deletes all the rows.
+var suggestedAutovacuumSettings = map[string]string{
+	"autovacuum_vacuum_scale_factor":  "0.01",
+	"autovacuum_vacuum_threshold":     "1000",
+	"autovacuum_analyze_scale_factor": "0.02",
+}
+
+// BloatReport estimates the bloat of the rules table from the statistics collector.
+type BloatReport struct {
+	LiveRows int64
+	DeadRows int64
+	// DeadRatio is the share of dead rows among all the rows.
+	DeadRatio float64
+	// TableBytes is the size of the table including its indexes and TOAST data.
+	TableBytes int64
+	// EstimatedBloatBytes is the part of TableBytes estimated to be taken by dead rows.
+	EstimatedBloatBytes int64
+	LastVacuum          time.Time
+	LastAutovacuum      time.Time
+	// Settings are the storage parameters set on the table, e.g. autovacuum_vacuum_scale_factor.
+	Settings map[string]string
+	// NeedsVacuum is true when the dead rows exceed 20% of the rows, see Adapter.Vacuum.
+	NeedsVacuum bool
+	// SuggestedSettings are the autovacuum settings recommended for the table, which aren't set yet.
+	// They can be applied with Adapter.SetTableSettings.
+	SuggestedSettings map[string]string
+}
+
+// BloatReport reports the dead rows and estimated bloat of the rules table and suggests autovacuum settings.
+// The numbers are estimates of the statistics collector, they are zero until the table has been analyzed.
+func (a *Adapter) BloatReport(ctx context.Context) (_ *BloatReport, err error) {
+	defer a.handleError(OpBloatReport, "", 0, &err)
+
+	var (
+		r          BloatReport
+		lastVacuum pg.NullTime
+		lastAuto   pg.NullTime
+		options    []string
+	)
+	_, err = a.conn().QueryOneContext(ctx, pg.Scan(&r.LiveRows, &r.DeadRows, &r.TableBytes, &lastVacuum, &lastAuto, pg.Array(&options)), `
+		SELECT coalesce(s.n_live_tup, 0), coalesce(s.n_dead_tup, 0), pg_total_relation_size(c.oid),
+			s.last_vacuum, s.last_autovacuum, coalesce(c.reloptions, '{}')
+		FROM pg_class c
+		LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+		WHERE c.oid = to_regclass(?)
+	`, quoteQualified(a.tableName))
+	if err != nil {
+		return nil, err
+	}
+	r.LastVacuum = lastVacuum.Time
+	r.LastAutovacuum = lastAuto.Time
+
+	if total := r.LiveRows + r.DeadRows; total > 0 {
+		r.DeadRatio = float64(r.DeadRows) / float64(total)
+	}
+	r.EstimatedBloatBytes = int64(r.DeadRatio * float64(r.TableBytes))
+	r.NeedsVacuum = r.DeadRatio > bloatDeadRatio
+
+	r.Settings = make(map[string]string, len(options))
+	for _, option := range options {
+		if i := strings.IndexByte(option, '='); i >= 0 {
+			r.Settings[option[:i]] = option[i+1:]
+		}
+	}
+	r.SuggestedSettings = make(map[string]string)
+	if r.NeedsVacuum {
+		for k, v := range suggestedAutovacuumSettings {
+			if _, ok := r.Settings[k]; !ok {
+				r.SuggestedSettings[k] = v
+			}
+		}
+	}
+	return &r, nil
+}
+
+// SetTableSettings sets storage parameters of the rules table, e.g. the SuggestedSettings of a BloatReport.
+func (a *Adapter) SetTableSettings(ctx context.Context, settings map[string]string) (err error) {
+	defer a.handleError(OpSetTableSettings, "", 0, &err)
+
+	if len(settings) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	query := "ALTER TABLE ? SET ("
+	params := []interface{}{pg.Ident(a.tableName)}
+	for i, k := range keys {
+		if i > 0 {
+			query += ", "
+		}
+		query += "? = ?"
+		params = append(params, pg.Ident(k), settings[k])
+	}
+	query += ")"
+
+	_, err = a.conn().ExecContext(ctx, query, params...)
+	return err
+}
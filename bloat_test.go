@@ -0,0 +1,20 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestBloatReport() {
+	ctx := context.Background()
+
+	r, err := s.a.BloatReport(ctx)
+	s.Require().NoError(err)
+	s.Require().Positive(r.TableBytes)
+	s.Require().Empty(r.Settings)
+
+	s.Require().NoError(s.a.SetTableSettings(ctx, suggestedAutovacuumSettings))
+	r, err = s.a.BloatReport(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(suggestedAutovacuumSettings, r.Settings)
+	s.Require().Empty(r.SuggestedSettings)
+}
@@ -0,0 +1,111 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DefaultBundleTableName is the table holding the state of the bundles, see WithBundles.
+const DefaultBundleTableName = "casbin_bundle"
+
+// bundleState is a row of the bundle table, one per bundle and rules table.
+type bundleState struct {
+	tableName struct{} `pg:"casbin_bundle"`
+	RuleTable string   `pg:",pk"`
+	Name      string   `pg:",pk"`
+	Enabled   bool     `pg:",use_zero,notnull"`
+}
+
+// WithBundles adds a bundle column to the rules table and creates the bundle table.
+// A bundle is a named set of rules, e.g. an experimental or customer specific rule set,
+// which can be switched on and off at once with SetBundleEnabled. Rules are put in a bundle with AddPolicyWithMeta.
+// LoadPolicy and LoadFilteredPolicy load the rules without bundle and the rules of the enabled bundles,
+// bundles are disabled until they are enabled.
+func WithBundles() Option {
+	return func(a *Adapter) {
+		a.bundles = true
+	}
+}
+
+func (a *Adapter) createBundleTable() error {
+	_, err := a.conn().Exec("ALTER TABLE ? ADD COLUMN IF NOT EXISTS bundle text", pg.Ident(a.tableName))
+	if err != nil {
+		return err
+	}
+	return a.conn().Model((*bundleState)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+}
+
+// enabledBundles restricts query to the rules without bundle and the rules of the enabled bundles.
+func (a *Adapter) enabledBundles(query *orm.Query) *orm.Query {
+	if !a.bundles {
+		return query
+	}
+	return query.Where("bundle IS NULL OR bundle IN (SELECT name FROM ? WHERE rule_table = ? AND enabled)",
+		pg.Ident(DefaultBundleTableName), a.tableName)
+}
+
+// SetBundleEnabled enables or disables the bundle name, it requires WithBundles.
+// The change is published like the policy changes, so the enforcers can reload the policy.
+func (a *Adapter) SetBundleEnabled(ctx context.Context, name string, enabled bool) error {
+	return a.doContext(ctx, &Operation{Name: OpSetBundleEnabled}, func(ctx context.Context) error {
+		return a.setBundleEnabled(ctx, name, enabled)
+	})
+}
+
+func (a *Adapter) setBundleEnabled(ctx context.Context, name string, enabled bool) (err error) {
+	defer a.handleError(OpSetBundleEnabled, "", 0, &err)
+
+	if !a.bundles {
+		return errors.New("bundles are not enabled, see WithBundles")
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	change := PolicyChange{Operation: OpSetBundleEnabled}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.ModelContext(ctx, &bundleState{RuleTable: a.tableName, Name: name, Enabled: enabled}).
+			OnConflict("(rule_table, name) DO UPDATE").
+			Set("enabled = EXCLUDED.enabled").
+			Insert()
+		if err != nil {
+			return err
+		}
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.changed(ctx, change)
+	return nil
+}
+
+// Bundles returns the bundles having rules or a state and whether they are enabled.
+func (a *Adapter) Bundles(ctx context.Context) (_ map[string]bool, err error) {
+	defer a.handleError(OpBundles, "", 0, &err)
+
+	var names []string
+	_, err = a.conn().QueryContext(ctx, &names, "SELECT DISTINCT bundle FROM ? WHERE bundle IS NOT NULL", pg.Ident(a.tableName))
+	if err != nil {
+		return nil, err
+	}
+	var states []bundleState
+	if err := a.conn().ModelContext(ctx, &states).Where("rule_table = ?", a.tableName).Select(); err != nil {
+		return nil, err
+	}
+
+	bundles := make(map[string]bool, len(names)+len(states))
+	for _, name := range names {
+		bundles[name] = false
+	}
+	for _, state := range states {
+		bundles[state.Name] = state.Enabled
+	}
+	return bundles, nil
+}
@@ -0,0 +1,33 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestBundles() {
+	a, err := NewAdapterByDB(s.a.db, WithBundles())
+	s.Require().NoError(err)
+
+	ctx := context.Background()
+	meta := PolicyMeta{Bundle: "experiment"}
+	s.Require().NoError(a.AddPolicyWithMeta(ctx, "p", "p", []string{"carol", "data3", "read"}, meta))
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.Require().False(e.HasPolicy("carol", "data3", "read"), "bundles are disabled by default")
+
+	bundles, err := a.Bundles(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]bool{"experiment": false}, bundles)
+
+	s.Require().NoError(a.SetBundleEnabled(ctx, "experiment", true))
+	s.Require().NoError(e.LoadPolicy())
+	s.Require().True(e.HasPolicy("carol", "data3", "read"))
+	s.Require().True(e.HasPolicy("alice", "data1", "read"), "rules without bundle are always loaded")
+
+	s.Require().NoError(a.SetBundleEnabled(ctx, "experiment", false))
+	s.Require().NoError(e.LoadFilteredPolicy(&Filter{P: []string{}}))
+	s.Require().False(e.HasPolicy("carol", "data3", "read"))
+}
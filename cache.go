@@ -0,0 +1,156 @@
+package pgadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// WithPolicyCache caches the rules read by LoadPolicy and LoadFilteredPolicy for ttl, so the reloads of several
+// enforcers within a short window read the database once. The cached rules of a table are dropped on every write
+// notified on DefaultNotifyChannel, by any adapter, so the option requires WithNotify, and on the writes of the
+// adapter itself right away. The loads within the caller's transaction, see WithTx, bypass the cache.
+// The cache is shared with the sibling adapters, see WithTable, and stopped by Close.
+func WithPolicyCache(ttl time.Duration) Option {
+	return func(a *Adapter) {
+		a.cacheTTL = ttl
+	}
+}
+
+// policyCache holds the rule lines loaded per table and filter.
+type policyCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+	// generations counts the invalidations per table, the loads started before one aren't cached.
+	generations map[string]uint64
+
+	ln   *pg.Listener
+	done chan struct{}
+}
+
+type cacheKey struct {
+	table  string
+	filter string
+}
+
+type cacheEntry struct {
+	lines   []string
+	expires time.Time
+}
+
+// startCache creates the cache with WithPolicyCache and listens to the notifications invalidating it.
+func (a *Adapter) startCache() error {
+	if a.cacheTTL <= 0 || a.cache != nil {
+		return nil
+	}
+	if a.notifySender == "" {
+		return errors.New("the policy cache requires notifications, see WithNotify")
+	}
+	if a.db == nil {
+		return errNoPool
+	}
+	c := &policyCache{
+		ttl:         a.cacheTTL,
+		entries:     make(map[cacheKey]cacheEntry),
+		generations: make(map[string]uint64),
+		ln:          a.db.Listen(context.Background(), DefaultNotifyChannel),
+		done:        make(chan struct{}),
+	}
+	go c.run()
+	a.cache = c
+	return nil
+}
+
+func (c *policyCache) run() {
+	defer close(c.done)
+	for n := range c.ln.Channel() {
+		var msg Notification
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err == nil {
+			c.invalidate(msg.Table)
+		}
+	}
+}
+
+func (c *policyCache) close() {
+	c.ln.Close()
+	<-c.done
+}
+
+// generation returns the number of invalidations of table, to be passed to put.
+func (c *policyCache) generation(table string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[table]
+}
+
+func (c *policyCache) get(table, filter string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[cacheKey{table, filter}]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.lines, true
+}
+
+// put caches lines unless table was invalidated since generation.
+func (c *policyCache) put(table, filter string, generation uint64, lines []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[table] != generation {
+		return
+	}
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[cacheKey{table, filter}] = cacheEntry{lines: lines, expires: now.Add(c.ttl)}
+}
+
+func (c *policyCache) invalidate(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[table]++
+	for key := range c.entries {
+		if key.table == table {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cachedLoad passes the rule lines of filter, empty for the whole policy, to handler from the cache,
+// or from load, caching them.
+func (a *Adapter) cachedLoad(filter string, handler func(string) error, load func(handler func(string) error) error) error {
+	c := a.cache
+	if c == nil || a.tx != nil {
+		return load(handler)
+	}
+	if lines, ok := c.get(a.tableName, filter); ok {
+		for _, line := range lines {
+			if err := handler(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	generation := c.generation(a.tableName)
+	var lines []string
+	err := load(func(line string) error {
+		lines = append(lines, line)
+		return handler(line)
+	})
+	if err != nil {
+		return err
+	}
+	c.put(a.tableName, filter, generation, lines)
+	return nil
+}
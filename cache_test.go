@@ -0,0 +1,75 @@
+package pgadapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCache(t *testing.T) {
+	c := &policyCache{ttl: time.Minute, entries: map[cacheKey]cacheEntry{}, generations: map[string]uint64{}}
+	a := &Adapter{tableName: "casbin_rule", cache: c}
+
+	loads := 0
+	load := func(handler func(string) error) error {
+		loads++
+		return handler("p, alice, data1, read")
+	}
+	var lines []string
+	collect := func(line string) error {
+		lines = append(lines, line)
+		return nil
+	}
+
+	require.NoError(t, a.cachedLoad("", collect, load))
+	require.NoError(t, a.cachedLoad("", collect, load))
+	require.Equal(t, 1, loads, "the second load is served by the cache")
+	require.Equal(t, []string{"p, alice, data1, read", "p, alice, data1, read"}, lines)
+
+	require.NoError(t, a.cachedLoad(`{"P":["alice"]}`, collect, load))
+	require.Equal(t, 2, loads, "filters are cached apart")
+
+	c.invalidate("casbin_rule")
+	require.NoError(t, a.cachedLoad("", collect, load))
+	require.Equal(t, 3, loads)
+
+	// A load racing with an invalidation isn't cached.
+	require.NoError(t, a.cachedLoad("racing", collect, func(handler func(string) error) error {
+		c.invalidate("casbin_rule")
+		return load(handler)
+	}))
+	_, ok := c.get("casbin_rule", "racing")
+	require.False(t, ok)
+
+	require.Error(t, (&Adapter{cacheTTL: time.Minute}).startCache(), "the cache requires WithNotify")
+}
+
+func (s *AdapterTestSuite) TestPolicyCache() {
+	a, err := NewAdapterByDB(s.a.db, WithNotify(), WithPolicyCache(time.Minute))
+	s.Require().NoError(err)
+	defer a.cache.close()
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+
+	// Rules inserted without notification aren't seen until the cache expires.
+	_, err = s.a.db.Exec("INSERT INTO casbin_rule (id, ptype, v0, v1, v2) VALUES ('direct', 'p', 'dave', 'data1', 'read')")
+	s.Require().NoError(err)
+	s.Require().NoError(e.LoadPolicy())
+	s.Require().False(e.HasPolicy("dave", "data1", "read"))
+
+	other, err := NewAdapterByDB(s.a.db, WithNotify())
+	s.Require().NoError(err)
+	s.Require().NoError(other.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	s.Require().Eventually(func() bool {
+		s.Require().NoError(e.LoadPolicy())
+		return e.HasPolicy("carol", "data1", "read")
+	}, 5*time.Second, 50*time.Millisecond, "the notification drops the cached rules")
+	s.Require().True(e.HasPolicy("dave", "data1", "read"))
+
+	_, err = e.AddPolicy("erin", "data1", "read")
+	s.Require().NoError(err)
+	s.Require().NoError(e.LoadPolicy())
+	s.Require().True(e.HasPolicy("erin", "data1", "read"), "the writes of the adapter drop the cached rules right away")
+}
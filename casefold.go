@@ -0,0 +1,47 @@
+package pgadapter
+
+import "strings"
+
+// WithCaseInsensitive makes the adapter lowercase the values of the rules it writes, before computing their IDs,
+// and the values of the filters it queries with, so "Alice" and "alice" are the same rule and match the same
+// filters, e.g. for subjects coming from an identity provider inconsistent about casing.
+// The rules stored before keep their case, and the enforcer keeps the values it was given until it reloads
+// the policy. Lowercasing on write keeps the IDs consistent, which citext columns wouldn't.
+func WithCaseInsensitive() Option {
+	return func(a *Adapter) {
+		a.caseInsensitive = true
+	}
+}
+
+// fold returns values lowercased with WithCaseInsensitive, values itself otherwise.
+func (a *Adapter) fold(values []string) []string {
+	if !a.caseInsensitive {
+		return values
+	}
+	folded := make([]string, len(values))
+	for i, v := range values {
+		folded[i] = strings.ToLower(v)
+	}
+	return folded
+}
+
+// foldFilter returns filter with its values lowercased with WithCaseInsensitive, filter itself otherwise.
+func (a *Adapter) foldFilter(filter *Filter) *Filter {
+	if !a.caseInsensitive || filter == nil {
+		return filter
+	}
+	folded := &Filter{Labels: filter.Labels}
+	if filter.P != nil {
+		folded.P = a.fold(filter.P)
+	}
+	if filter.G != nil {
+		folded.G = a.fold(filter.G)
+	}
+	if filter.Ptypes != nil {
+		folded.Ptypes = make(map[string][]string, len(filter.Ptypes))
+		for ptype, values := range filter.Ptypes {
+			folded.Ptypes[ptype] = a.fold(values)
+		}
+	}
+	return folded
+}
@@ -0,0 +1,43 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	a := &Adapter{}
+	require.NotEqual(t, a.ruleID("p", []string{"Alice", "data1", "read"}), a.ruleID("p", []string{"alice", "data1", "read"}))
+
+	WithCaseInsensitive()(a)
+	require.Equal(t, a.ruleID("p", []string{"Alice", "data1", "read"}), a.ruleID("p", []string{"alice", "data1", "read"}))
+	rule := []string{"Alice", "Data1", "read"}
+	line := a.ruleLine("p", rule)
+	require.Equal(t, []string{"alice", "data1", "read"}, line.rule())
+	require.Equal(t, []string{"Alice", "Data1", "read"}, rule, "the rules of the caller are left alone")
+
+	filter := a.foldFilter(&Filter{P: []string{"", "Data1"}, Ptypes: map[string][]string{"g2": {"Bob"}}})
+	require.Equal(t, &Filter{P: []string{"", "data1"}, Ptypes: map[string][]string{"g2": {"bob"}}}, filter)
+}
+
+func (s *AdapterTestSuite) TestCaseInsensitive() {
+	a, err := NewAdapterByDB(s.a.db, WithCaseInsensitive())
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"Carol", "data1", "read"}))
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "DATA1", "read"}))
+	rules, err := a.GetFilteredPolicies("p", 0, "CAROL")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"carol", "data1", "read"}}, rules)
+
+	ok, err := a.HasPolicy(context.Background(), "p", []string{"CaRoL", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok)
+
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"CAROL", "Data1", "Read"}))
+	rules, err = a.GetFilteredPolicies("p", 0, "carol")
+	s.Require().NoError(err)
+	s.Require().Empty(rules)
+}
@@ -0,0 +1,160 @@
+package pgadapter
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Operation names reported in PolicyChange and Error.
+const (
+	OpNewAdapter             = "NewAdapter"
+	OpLoadPolicy             = "LoadPolicy"
+	OpLoadFilteredPolicy     = "LoadFilteredPolicy"
+	OpSavePolicy             = "SavePolicy"
+	OpAddPolicy              = "AddPolicy"
+	OpAddPolicies            = "AddPolicies"
+	OpRemovePolicy           = "RemovePolicy"
+	OpRemovePolicies         = "RemovePolicies"
+	OpRemoveFilteredPolicy   = "RemoveFilteredPolicy"
+	OpUpdatePolicy           = "UpdatePolicy"
+	OpUpdatePolicies         = "UpdatePolicies"
+	OpUpdateFilteredPolicies = "UpdateFilteredPolicies"
+	OpPublish                = "Publish"
+	OpWebhook                = "Webhook"
+	OpGetPolicyByID          = "GetPolicyByID"
+	OpQueryRules             = "QueryRules"
+	OpExportPolicies         = "ExportPolicies"
+	OpComparePolicies        = "ComparePolicies"
+	OpPromotePolicies        = "PromotePolicies"
+	OpReorderPolicies        = "ReorderPolicies"
+	OpListPolicies           = "ListPolicies"
+	OpSetPolicyLabels        = "SetPolicyLabels"
+	OpSaveModelText          = "SaveModelText"
+	OpLoadModelText          = "LoadModelText"
+	OpSetBundleEnabled       = "SetBundleEnabled"
+	OpBundles                = "Bundles"
+	OpIsEmpty                = "IsEmpty"
+	OpHasPolicy              = "HasPolicy"
+	OpAnalyze                = "Analyze"
+	OpReindex                = "Reindex"
+	OpVacuum                 = "Vacuum"
+	OpBloatReport            = "BloatReport"
+	OpSetTableSettings       = "SetTableSettings"
+	OpSearchPolicies         = "SearchPolicies"
+	OpPoliciesContaining     = "PoliciesContaining"
+	OpReadOperations         = "ReadOperations"
+	OpReplayOperations       = "ReplayOperations"
+	OpImportPolicies         = "ImportPolicies"
+	OpProposeChanges         = "ProposeChanges"
+	OpListPending            = "ListPending"
+	OpApprove                = "Approve"
+	OpReject                 = "Reject"
+	OpGetPolicyByText        = "GetPolicyByText"
+	OpRepairPolicyIDs        = "RepairPolicyIDs"
+	OpSyncPolicy             = "SyncPolicy"
+	OpLoadIncrementalPolicy  = "LoadIncrementalPolicy"
+	OpPurgeDeleted           = "PurgeDeleted"
+	OpPing                   = "Ping"
+	OpSnapshotPolicy         = "SnapshotPolicy"
+	OpListSnapshots          = "ListSnapshots"
+	OpLoadSnapshot           = "LoadSnapshot"
+	OpDeleteSnapshot         = "DeleteSnapshot"
+	OpRestorePolicyAt        = "RestorePolicyAt"
+	OpLoadPolicyAt           = "LoadPolicyAt"
+	OpDumpPolicy             = "DumpPolicy"
+	OpRestorePolicy          = "RestorePolicy"
+	OpMigrate01x             = "Migrate01x"
+	OpListPoliciesPage       = "ListPoliciesPage"
+	OpDispatch               = "Dispatch"
+	OpSavePolicyPlan         = "SavePolicyPlan"
+	OpGetFilteredPolicies    = "GetFilteredPolicies"
+	OpGetImplicitPermissions = "GetImplicitPermissions"
+)
+
+// PolicyChange describes a mutation that has been successfully written to the database.
+type PolicyChange struct {
+	Operation string     `json:"operation"`
+	Sec       string     `json:"sec,omitempty"`
+	Ptype     string     `json:"ptype,omitempty"`
+	Rules     [][]string `json:"rules,omitempty"`
+	OldRules  [][]string `json:"old_rules,omitempty"`
+	// FieldIndex is only meaningful for RemoveFilteredPolicy and UpdateFilteredPolicies,
+	// where Rules holds the field values of the filter.
+	FieldIndex int `json:"field_index,omitempty"`
+	// Removed holds the rules deleted by RemoveFilteredPolicy.
+	Removed [][]string `json:"removed,omitempty"`
+	Actor   string     `json:"actor,omitempty"`
+	// Revision is incremented by one for every change made through this adapter.
+	Revision uint64 `json:"revision"`
+}
+
+// ChangePublisher receives every PolicyChange after it has been committed to the database.
+// Implementations can forward the changes to a message bus such as NATS, Kafka or SNS.
+// Publish is called synchronously, implementations that talk to remote systems should buffer internally.
+// Errors returned by Publish don't affect the already committed mutation, they are reported to the error hook.
+type ChangePublisher interface {
+	Publish(ctx context.Context, change PolicyChange) error
+}
+
+// WithChangePublisher registers a ChangePublisher, it can be used several times to register more publishers.
+func WithChangePublisher(p ChangePublisher) Option {
+	return func(a *Adapter) {
+		a.publishers = append(a.publishers, p)
+	}
+}
+
+// WithActor sets the actor reported in every PolicyChange emitted by the adapter,
+// typically the name of the service or user performing the writes.
+func WithActor(actor string) Option {
+	return func(a *Adapter) {
+		a.actor = actor
+	}
+}
+
+type actorKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying actor, e.g. the user behind a request,
+// which is recorded instead of the one of WithActor by the operations run with ctx in the operation log
+// and the audit log.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorOf returns the actor carried by ctx, or the one of WithActor.
+func (a *Adapter) actorOf(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return a.actor
+}
+
+// Revision returns the revision of the last change made through this adapter.
+func (a *Adapter) Revision() uint64 {
+	return atomic.LoadUint64(&a.revision)
+}
+
+// changed is called after a mutation has been committed, ctx is the one of the mutation,
+// so the change carries the same actor as its operation log and audit entries.
+func (a *Adapter) changed(ctx context.Context, change PolicyChange) {
+	if a.cache != nil {
+		a.cache.invalidate(a.tableName)
+	}
+	if change.Actor == "" {
+		change.Actor = a.actorOf(ctx)
+	}
+	if a.txChanges != nil {
+		// Transaction publishes the change once committed.
+		*a.txChanges = append(*a.txChanges, change)
+		return
+	}
+
+	change.Revision = atomic.AddUint64(&a.revision, 1)
+
+	a.subscriptions().publish(change)
+
+	for _, p := range a.publishers {
+		if err := p.Publish(context.Background(), change); err != nil {
+			a.handleError(OpPublish, change.Ptype, len(change.Rules), &err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	changes []PolicyChange
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, change PolicyChange) error {
+	p.changes = append(p.changes, change)
+	return nil
+}
+
+func TestChangePublisher(t *testing.T) {
+	p1, p2 := &recordingPublisher{}, &recordingPublisher{}
+	a := &Adapter{}
+	WithChangePublisher(p1)(a)
+	WithChangePublisher(p2)(a)
+
+	a.changed(context.Background(), PolicyChange{Operation: OpRemovePolicy, Ptype: "p", Rules: [][]string{{"bob", "data2", "write"}}})
+	a.changed(context.Background(), PolicyChange{Operation: OpSavePolicy})
+
+	for _, p := range []*recordingPublisher{p1, p2} {
+		require.Len(t, p.changes, 2)
+		require.Equal(t, OpRemovePolicy, p.changes[0].Operation)
+		require.Equal(t, uint64(1), p.changes[0].Revision)
+		require.Equal(t, OpSavePolicy, p.changes[1].Operation)
+		require.Equal(t, uint64(2), p.changes[1].Revision)
+	}
+	require.Equal(t, uint64(2), a.Revision())
+}
+
+func TestChangeActor(t *testing.T) {
+	p := &recordingPublisher{}
+	a := &Adapter{}
+	WithActor("service")(a)
+	WithChangePublisher(p)(a)
+
+	a.changed(context.Background(), PolicyChange{Operation: OpAddPolicy})
+	a.changed(ContextWithActor(context.Background(), "alice"), PolicyChange{Operation: OpAddPolicy})
+
+	require.Len(t, p.changes, 2)
+	require.Equal(t, "service", p.changes[0].Actor)
+	require.Equal(t, "alice", p.changes[1].Actor, "the actor of the context wins like in the operation log")
+}
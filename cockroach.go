@@ -0,0 +1,65 @@
+package pgadapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cockroachBatchSize is the number of rows inserted by a statement with WithCockroachDB, unless WithSaveBatchSize is set.
+const cockroachBatchSize = 1000
+
+// WithCockroachDB makes the adapter work with CockroachDB, which speaks the Postgres protocol
+// but lacks some of its features. The rules are inserted with multi-row INSERT statements instead of
+// COPY and temporary tables, SavePolicy doesn't lock the table, the serializable transactions of CockroachDB
+// failing the conflicting writes instead, and NewSyncedEnforcer and NewEnforcerFromConn create the database
+// with CREATE DATABASE IF NOT EXISTS. The IDs hashed from the rules spread the rows over the ranges of the table,
+// so the default ID is kept. The options relying on triggers, views, advisory locks, LISTEN/NOTIFY,
+// extensions or SET ROLE are rejected by the constructors.
+func WithCockroachDB() Option {
+	return func(a *Adapter) {
+		a.cockroach = true
+	}
+}
+
+// checkCockroachDB returns an error if options CockroachDB doesn't support are combined with WithCockroachDB.
+func (a *Adapter) checkCockroachDB() error {
+	if !a.cockroach {
+		return nil
+	}
+	var unsupported []string
+	for name, set := range map[string]bool{
+		"WithColumnMapping":                len(a.columns) > 0,
+		"WithSoftDelete":                   a.softDelete,
+		"WithTenant":                       a.tenantScoped,
+		"WithSurrogateKey":                 a.surrogateKey != 0,
+		"WithJSONBStorage":                 a.jsonb,
+		"WithPartitioning":                 a.partitioning != nil,
+		"WithRole":                         a.role != "",
+		"WithSaveStrategy(SaveShadowSwap)": a.saveStrategy == SaveShadowSwap,
+		"WithNotify":                       a.notifySender != "",
+		"WithOperationLog":                 a.operationLog,
+		"WithQuota":                        a.quota != nil,
+		"WithModelStorage":                 a.modelStorage,
+		"WithSearchIndexes":                a.searchIndexes,
+		"WithRuleTextColumn":               a.ruleTextColumn,
+		"WithTimestamps":                   a.timestamps,
+	} {
+		if set {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	sort.Strings(unsupported)
+	return fmt.Errorf("%s can't be used with CockroachDB", strings.Join(unsupported, ", "))
+}
+
+// batchSize returns the number of rows inserted by a statement, zero if the rules are copied instead.
+func (a *Adapter) batchSize() int {
+	if a.saveBatchSize == 0 && a.cockroach {
+		return cockroachBatchSize
+	}
+	return a.saveBatchSize
+}
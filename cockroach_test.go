@@ -0,0 +1,35 @@
+package pgadapter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCockroachDB(t *testing.T) {
+	a := &Adapter{}
+	WithSoftDelete()(a)
+	require.NoError(t, a.checkCockroachDB(), "the options are only checked with WithCockroachDB")
+	require.Zero(t, a.batchSize())
+
+	WithCockroachDB()(a)
+	WithNotify()(a)
+	WithOperationLog()(a)
+	require.EqualError(t, a.checkCockroachDB(), "WithNotify, WithOperationLog, WithSoftDelete can't be used with CockroachDB")
+	require.Equal(t, cockroachBatchSize, a.batchSize())
+
+	a = &Adapter{}
+	WithCockroachDB()(a)
+	WithSaveBatchSize(10)(a)
+	require.NoError(t, a.checkCockroachDB())
+	require.Equal(t, 10, a.batchSize())
+}
+
+// TestCockroachDBSuite runs the tests of the adapter against CockroachDB, PG_CONN being its URL.
+func TestCockroachDBSuite(t *testing.T) {
+	if os.Getenv("COCKROACH") == "" {
+		t.Skip("COCKROACH is not set")
+	}
+	runCoreTests(t, &AdapterTestSuite{opts: []Option{WithCockroachDB()}})
+}
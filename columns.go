@@ -0,0 +1,230 @@
+package pgadapter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ruleColumns are the columns of the rules table.
+var ruleColumns = []string{"id", "ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
+
+// WithColumnMapping maps the columns of the rules table, id, ptype and v0 to v5, to other names,
+// e.g. {"ptype": "p_type"} for the tables created by v0.1.x, so existing tables can be used without renaming columns.
+// The adapter then works on an updatable view of the table named <table>_mapped exposing the usual column names,
+// which it creates at start unless SkipTableCreate is set. TableName returns the name of the view and QueryRules
+// predicates use the usual column names. Options adding columns or indexes to the rules table can't be combined
+// with a mapping. Mappings of several options are merged.
+func WithColumnMapping(columns map[string]string) Option {
+	return func(a *Adapter) {
+		if a.columns == nil {
+			a.columns = make(map[string]string, len(columns))
+		}
+		for column, name := range columns {
+			a.columns[column] = name
+		}
+	}
+}
+
+// WithPtypeColumn maps the ptype column to name, see WithColumnMapping.
+func WithPtypeColumn(name string) Option {
+	return WithColumnMapping(map[string]string{"ptype": name})
+}
+
+// mapColumns makes the adapter work on the view of its table if a column mapping is set.
+// The view also hides the removed rules with WithSoftDelete and the rules of other tenants with WithTenant,
+// and the surrogate key with WithSurrogateKey. With WithJSONBStorage, it exposes the values of the rule column,
+// with WithGeneratedID, it leaves the generated id out of the writes, and with WithNormalizedStorage,
+// it joins the rules table and the normalized rules.
+func (a *Adapter) mapColumns() error {
+	if len(a.columns) == 0 && !a.softDelete && !a.tenantScoped && a.surrogateKey == 0 && !a.jsonb && !a.generatedID &&
+		a.normalization == nil {
+		return nil
+	}
+	if err := a.checkJSONBStorage(); err != nil {
+		return err
+	}
+	if err := a.checkGeneratedID(); err != nil {
+		return err
+	}
+	if err := a.checkNormalization(); err != nil {
+		return err
+	}
+	if err := a.mapSurrogateKey(); err != nil {
+		return err
+	}
+	if err := checkColumnMapping(a.columns); err != nil {
+		return err
+	}
+	a.mappedTable = a.tableName
+	a.tableName = a.ruleView().name()
+	return nil
+}
+
+func (a *Adapter) createMappedView() error {
+	stmts := a.ruleView().sql()
+	if _, err := a.conn().Exec(stmts[0]); err != nil {
+		return err
+	}
+	// The jsonb table has its own indexes.
+	if !a.jsonb {
+		if err := a.createDefaultIndexes(a.mappedTable); err != nil {
+			return err
+		}
+	}
+	for _, stmt := range stmts[1:] {
+		if _, err := a.conn().Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkColumnMapping(columns map[string]string) error {
+	for column, name := range columns {
+		if name == "" || !containsString(ruleColumns, column) {
+			return fmt.Errorf("invalid column mapping %q to %q, the columns are %s", column, name, strings.Join(ruleColumns, ", "))
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mappedColumn returns the name of the table column holding column.
+func mappedColumn(columns map[string]string, column string) string {
+	if name, ok := columns[column]; ok {
+		return name
+	}
+	return column
+}
+
+// createRulesTableSQL returns the statement creating the rules table with the mapped column names,
+// the layout is the one go-pg creates for CasbinRule. With a surrogate key, the ID computed from the rule
+// is unique instead of the primary key.
+func createRulesTableSQL(table string, columns map[string]string, key SurrogateKey) string {
+	defs := make([]string, 0, len(ruleColumns)+2)
+	if key != 0 {
+		defs = append(defs, key.columnSQL())
+	}
+	for _, column := range ruleColumns {
+		defs = append(defs, quoteIdent(mappedColumn(columns, column))+" text")
+	}
+	if key != 0 {
+		defs = append(defs, "UNIQUE ("+quoteIdent(mappedColumn(columns, "id"))+")")
+	} else {
+		defs = append(defs, "PRIMARY KEY ("+quoteIdent(mappedColumn(columns, "id"))+")")
+	}
+	return "CREATE TABLE IF NOT EXISTS " + quoteQualified(table) + " (" + strings.Join(defs, ", ") + ")"
+}
+
+// ruleView is the view of the rules table an adapter works on, see mapColumns.
+type ruleView struct {
+	table      string
+	columns    map[string]string
+	softDelete bool
+	// tenant is the tenant the view is restricted to when scoped is set, see WithTenant.
+	tenant       string
+	scoped       bool
+	rls          bool
+	partitioning *Partitioning
+	surrogateKey SurrogateKey
+	jsonb        bool
+	generatedID  bool
+	// normalization is set with WithNormalizedStorage once its defaults are set, see checkNormalization.
+	normalization *Normalization
+}
+
+// ruleView returns the view of the rules table of a, the table is a.mappedTable once mapColumns was called.
+func (a *Adapter) ruleView() ruleView {
+	table := a.tableName
+	if a.mappedTable != "" {
+		table = a.mappedTable
+	}
+	return ruleView{
+		table:         table,
+		columns:       a.columns,
+		softDelete:    a.softDelete,
+		tenant:        a.tenant,
+		scoped:        a.tenantScoped,
+		rls:           a.tenantRLS,
+		partitioning:  a.partitioning,
+		surrogateKey:  a.surrogateKey,
+		jsonb:         a.jsonb,
+		generatedID:   a.generatedID,
+		normalization: a.normalization,
+	}
+}
+
+// name returns the name of the view.
+func (v ruleView) name() string {
+	name := v.table
+	if v.softDelete {
+		name += "_live"
+	}
+	if v.scoped {
+		name += "_t_" + tenantHash(v.tenant)
+	}
+	if name == v.table {
+		name += "_mapped"
+	}
+	return name
+}
+
+// sql returns the statements creating the table and its view, the table is created by the first one,
+// so the indexes can be created before the partitions and the view.
+func (v ruleView) sql() []string {
+	if v.jsonb {
+		return v.jsonbSQL()
+	}
+	if v.generatedID {
+		return v.generatedIDSQL()
+	}
+	if v.normalization != nil {
+		return v.normalizedSQL()
+	}
+	stmts := []string{createRulesTableSQL(v.table, v.columns, v.surrogateKey)}
+	if v.partitioning != nil {
+		stmts = partitionedTableSQL(v.table, v.columns, v.partitioning, v.scoped)
+	}
+	if v.softDelete {
+		stmts = append(stmts, "ALTER TABLE "+quoteQualified(v.table)+" ADD COLUMN IF NOT EXISTS deleted_at timestamptz")
+	}
+	if v.scoped {
+		stmts = append(stmts, v.tenantSQL()...)
+	}
+	stmts = append(stmts, v.createSQL())
+	if v.softDelete {
+		stmts = append(stmts, v.softDeleteTriggerSQL()...)
+	}
+	return stmts
+}
+
+// createSQL returns the statement creating the view renaming the mapped columns of the table.
+// Postgres forwards the writes of such simple views to the table.
+func (v ruleView) createSQL() string {
+	cols := make([]string, 0, len(ruleColumns))
+	for _, column := range ruleColumns {
+		cols = append(cols, quoteIdent(mappedColumn(v.columns, column))+" AS "+column)
+	}
+	var where []string
+	if v.softDelete {
+		where = append(where, "deleted_at IS NULL")
+	}
+	if v.scoped {
+		where = append(where, "tenant_id = "+quoteLiteral(v.tenant))
+	}
+
+	stmt := "CREATE OR REPLACE VIEW " + quoteQualified(v.name()) +
+		" AS SELECT " + strings.Join(cols, ", ") + " FROM " + quoteQualified(v.table)
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	return stmt
+}
@@ -0,0 +1,69 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnMapping(t *testing.T) {
+	columns := map[string]string{"ptype": "p_type"}
+	require.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "legacy"."rules" ("id" text, "p_type" text, "v0" text, "v1" text, "v2" text, "v3" text, "v4" text, "v5" text, PRIMARY KEY ("id"))`,
+		createRulesTableSQL("legacy.rules", columns, 0))
+	require.Equal(t,
+		`CREATE OR REPLACE VIEW "legacy"."rules_mapped" AS SELECT "id" AS id, "p_type" AS ptype, "v0" AS v0, "v1" AS v1, "v2" AS v2, "v3" AS v3, "v4" AS v4, "v5" AS v5 FROM "legacy"."rules"`,
+		ruleView{table: "legacy.rules", columns: columns}.createSQL())
+
+	a := &Adapter{tableName: "rules"}
+	WithPtypeColumn("p_type")(a)
+	WithColumnMapping(map[string]string{"v0": "sub"})(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "rules_mapped", a.tableName)
+	require.Equal(t, map[string]string{"ptype": "p_type", "v0": "sub"}, a.columns)
+
+	a = &Adapter{tableName: "rules"}
+	WithColumnMapping(map[string]string{"v6": "extra"})(a)
+	require.Error(t, a.mapColumns())
+}
+
+func (s *AdapterTestSuite) TestColumnMapping() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("legacy_rule"), WithPtypeColumn("p_type"))
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	_, err = e.AddPolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+	_, err = e.UpdatePolicy([]string{"alice", "data1", "read"}, []string{"alice", "data1", "write"})
+	s.Require().NoError(err)
+	_, err = e.AddGroupingPolicy("alice", "admin")
+	s.Require().NoError(err)
+	_, err = e.RemoveFilteredGroupingPolicy(0, "alice")
+	s.Require().NoError(err)
+
+	var ptypes []string
+	_, err = s.a.db.Query(&ptypes, "SELECT p_type FROM legacy_rule")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"p"}, ptypes)
+
+	s.Require().NoError(e.LoadPolicy())
+	s.assertPolicy([][]string{{"alice", "data1", "write"}}, e.GetPolicy())
+}
+
+func TestSoftDeleteView(t *testing.T) {
+	stmts := ruleView{table: "rules", softDelete: true}.sql()
+	require.Len(t, stmts, 6)
+	require.Equal(t, `ALTER TABLE "rules" ADD COLUMN IF NOT EXISTS deleted_at timestamptz`, stmts[1])
+	require.Equal(t,
+		`CREATE OR REPLACE VIEW "rules_live" AS SELECT "id" AS id, "ptype" AS ptype, "v0" AS v0, "v1" AS v1, "v2" AS v2, "v3" AS v3, "v4" AS v4, "v5" AS v5 FROM "rules" WHERE deleted_at IS NULL`,
+		stmts[2])
+	require.Equal(t, `CREATE TRIGGER soft_delete INSTEAD OF DELETE ON "rules_live" FOR EACH ROW EXECUTE PROCEDURE "rules_soft_delete"()`, stmts[5])
+
+	a := &Adapter{tableName: "rules"}
+	WithSoftDelete()(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "rules_live", a.tableName)
+	require.Equal(t, "rules", a.mappedTable)
+}
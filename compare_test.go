@@ -0,0 +1,51 @@
+package pgadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRules(t *testing.T) {
+	here := []*CasbinRule{
+		savePolicyLine("g", []string{"alice", "admin"}),
+		savePolicyLine("p", []string{"alice", "data1", "read"}),
+		savePolicyLine("p", []string{"bob", "data2", "write"}),
+	}
+	there := []*CasbinRule{
+		savePolicyLine("p", []string{"alice", "data1", "read"}),
+		savePolicyLine("p", []string{"alice", "data1", "write"}),
+	}
+
+	d := diffRules(here, there)
+	require.False(t, d.Equal())
+	require.Equal(t, [][]string{{"g", "alice", "admin"}, {"p", "bob", "data2", "write"}}, d.OnlyHere)
+	require.Equal(t, [][]string{{"p", "alice", "data1", "write"}}, d.OnlyThere)
+
+	require.True(t, diffRules(here, here).Equal())
+}
+
+func (s *AdapterTestSuite) TestComparePolicies() {
+	other, err := s.a.WithTable("casbin_rule_other")
+	s.Require().NoError(err)
+	s.Require().NoError(SeedPoliciesFromFile(context.Background(), s.a.db, "examples/rbac_policy.csv", WithTableName("casbin_rule_other")))
+
+	d, err := s.a.ComparePolicies(context.Background(), other)
+	s.Require().NoError(err)
+	s.Require().True(d.Equal())
+
+	s.Require().NoError(other.RemovePolicy("p", "p", []string{"bob", "data2", "write"}))
+	s.Require().NoError(other.AddPolicy("p", "p", []string{"carol", "data3", "read"}))
+
+	d, err = s.a.ComparePolicies(context.Background(), other)
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "bob", "data2", "write"}}, d.OnlyHere)
+	s.Require().Equal([][]string{{"p", "carol", "data3", "read"}}, d.OnlyThere)
+
+	d, err = s.a.ComparePoliciesWith(context.Background(), strings.NewReader(rbacPolicyExport+"p,carol,data3,read\n"))
+	s.Require().NoError(err)
+	s.Require().Empty(d.OnlyHere)
+	s.Require().Equal([][]string{{"p", "carol", "data3", "read"}}, d.OnlyThere)
+}
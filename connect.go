@@ -0,0 +1,70 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// WithConnectRetry makes the constructors try up to attempts times to connect to Postgres and create the tables,
+// waiting backoff before the first retry and twice as long before each next one, e.g. while the database
+// container is still starting. Only connection failures are retried.
+func WithConnectRetry(attempts int, backoff time.Duration) Option {
+	return func(a *Adapter) {
+		a.connectAttempts = attempts
+		a.connectBackoff = backoff
+	}
+}
+
+// retryConnect runs fn until it succeeds or fails with another error than a connection failure,
+// at most as often as set by WithConnectRetry.
+func (a *Adapter) retryConnect(fn func() error) error {
+	backoff := a.connectBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= a.connectAttempts || !connectFailed(err) {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// connectFailed reports whether err is a failure to reach the database.
+func connectFailed(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		err = e.Err
+	}
+	return errorKind(err) == ErrConnUnavailable
+}
+
+// Ping checks that the database is reachable and that the rules table exists with the columns the adapter uses,
+// e.g. for a readiness probe. A missing table or column fails with ErrTableMissing, an unreachable database
+// with ErrConnUnavailable.
+func (a *Adapter) Ping(ctx context.Context) (err error) {
+	defer a.handleError(OpPing, "", 0, &err)
+
+	columns := []string{"id", "ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
+	if a.descriptions {
+		columns = append(columns, "description")
+	}
+	if a.labels {
+		columns = append(columns, "labels")
+	}
+	if a.bundles {
+		columns = append(columns, "bundle")
+	}
+	if a.timestamps {
+		columns = append(columns, "created_at", "updated_at")
+	}
+	_, err = a.conn().ExecContext(ctx, "SELECT "+strings.Join(columns, ", ")+" FROM ? LIMIT 0", pg.Ident(a.tableName))
+	if pgErrorCode(err) == pgCodeUndefinedColumn {
+		return fmt.Errorf("%w: %v", ErrTableMissing, err)
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryConnect(t *testing.T) {
+	a := &Adapter{}
+	WithConnectRetry(3, time.Millisecond)(a)
+
+	calls := 0
+	err := a.retryConnect(func() error {
+		calls++
+		return &Error{Op: OpNewAdapter, Kind: ErrDatabaseCreate, Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = a.retryConnect(func() error {
+		calls++
+		if calls < 2 {
+			return pgError(pgCodeCannotConnectNow)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	calls = 0
+	err = a.retryConnect(func() error {
+		calls++
+		return pgError(pgCodeUndefinedTable)
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, calls, "only connection failures are retried")
+}
+
+func (s *AdapterTestSuite) TestPing() {
+	ctx := context.Background()
+	s.Require().NoError(s.a.Ping(ctx))
+
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_missing"), SkipTableCreate())
+	s.Require().NoError(err)
+	s.Require().ErrorIs(a.Ping(ctx), ErrTableMissing)
+
+	a, err = NewAdapterByDB(s.a.db, WithDescriptions(), SkipTableCreate())
+	s.Require().NoError(err)
+	s.Require().ErrorIs(a.Ping(ctx), ErrTableMissing, "the description column is missing")
+}
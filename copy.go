@@ -0,0 +1,92 @@
+package pgadapter
+
+import (
+	"bytes"
+	"encoding/csv"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// copyTableName is the temporary table SavePolicy copies the rules into when they can't be copied
+// into the rules table directly.
+const copyTableName = "casbin_rule_copy"
+
+// copyRules writes lines to table with COPY within tx.
+func copyRules(tx *pg.Tx, table string, lines []*CasbinRule) error {
+	data, err := copyData(lines)
+	if err != nil {
+		return err
+	}
+	_, err = tx.CopyFrom(data, "COPY ? (id, ptype, v0, v1, v2, v3, v4, v5) FROM STDIN WITH (FORMAT csv)", pg.Ident(table))
+	return err
+}
+
+// copyData returns lines as the CSV data of COPY.
+func copyData(lines []*CasbinRule) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		// Like ON CONFLICT DO NOTHING, duplicates are skipped.
+		if seen[line.ID] {
+			continue
+		}
+		seen[line.ID] = true
+		// Empty values are written unquoted, which COPY reads as NULL like the inserts of the adapter.
+		if err := w.Write(append([]string{line.ID, line.Ptype}, line.values()...)); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// insertRules inserts lines into the rules table within tx, skipping the rules already stored.
+// The lines are copied into a temporary table first, which is much faster than inserting them one by one.
+func (a *Adapter) insertRules(tx *pg.Tx, lines []*CasbinRule) error {
+	if a.cockroach {
+		return a.insertBatches(tx, lines)
+	}
+	_, err := tx.Exec("DROP TABLE IF EXISTS pg_temp.?; "+
+		"CREATE TEMP TABLE ? (id text, ptype text, v0 text, v1 text, v2 text, v3 text, v4 text, v5 text) ON COMMIT DROP",
+		pg.Ident(copyTableName), pg.Ident(copyTableName))
+	if err != nil {
+		return err
+	}
+	if err := copyRules(tx, copyTableName, lines); err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO ? (id, ptype, v0, v1, v2, v3, v4, v5) "+
+		"SELECT id, ptype, v0, v1, v2, v3, v4, v5 FROM ? ON CONFLICT DO NOTHING",
+		pg.Ident(a.tableName), pg.Ident(copyTableName))
+	return err
+}
+
+// WithSaveBatchSize makes SavePolicy insert the rules with INSERT statements of at most size rows instead of COPY,
+// which bounds the work of each statement and suits the proxies and databases not supporting COPY.
+// Like with COPY, all the statements run in the transaction of SavePolicy.
+func WithSaveBatchSize(size int) Option {
+	return func(a *Adapter) {
+		a.saveBatchSize = size
+	}
+}
+
+// insertBatches inserts lines into the rules table within tx with statements of at most a.batchSize() rows,
+// skipping the rules already stored.
+func (a *Adapter) insertBatches(tx *pg.Tx, lines []*CasbinRule) error {
+	for len(lines) > 0 {
+		n := a.batchSize()
+		if n > len(lines) {
+			n = len(lines)
+		}
+		batch := lines[:n]
+		if _, err := tx.Model(&batch).Table(a.tableName).OnConflict("DO NOTHING").Insert(); err != nil {
+			return err
+		}
+		lines = lines[n:]
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package pgadapter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyData(t *testing.T) {
+	data, err := copyData([]*CasbinRule{
+		savePolicyLine("p", []string{"alice", "data,1", "read"}),
+		savePolicyLine("g", []string{"alice", "admin"}),
+		savePolicyLine("p", []string{"alice", "data,1", "read"}),
+	})
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%s,p,alice,\"data,1\",read,,,\n%s,g,alice,admin,,,,\n",
+		policyID("p", []string{"alice", "data,1", "read"}), policyID("g", []string{"alice", "admin"})), data.String())
+}
+
+func (s *AdapterTestSuite) TestSavePolicyCopy() {
+	rules := make([][]string, 20000)
+	for i := range rules {
+		rules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+	}
+	s.e.EnableAutoSave(false)
+	_, err := s.e.AddPolicies(rules)
+	s.Require().NoError(err)
+	s.Require().NoError(s.e.SavePolicy())
+
+	a, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveMergeUnion))
+	s.Require().NoError(err)
+	s.e.SetAdapter(a)
+	s.e.ClearPolicy()
+	_, err = s.e.AddPolicy("carol", "data1", "read")
+	s.Require().NoError(err)
+	s.Require().NoError(s.e.SavePolicy())
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().Len(s.e.GetPolicy(), 20005)
+}
+
+func (s *AdapterTestSuite) TestSaveBatchSize() {
+	a, err := NewAdapterByDB(s.a.db, WithSaveBatchSize(2))
+	s.Require().NoError(err)
+	s.e.SetAdapter(a)
+
+	s.e.EnableAutoSave(false)
+	_, err = s.e.AddPolicies([][]string{{"carol", "data1", "read"}, {"dave", "data1", "read"}})
+	s.Require().NoError(err)
+	s.Require().NoError(s.e.SavePolicy())
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().Len(s.e.GetPolicy(), 6)
+	s.Require().Len(s.e.GetGroupingPolicy(), 1)
+}
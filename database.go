@@ -0,0 +1,43 @@
+package pgadapter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// skipDatabaseCreateParam is the query parameter of the connection URL setting SkipDatabaseCreate.
+const skipDatabaseCreateParam = "skip_database_create"
+
+// SkipDatabaseCreate makes NewSyncedEnforcer and NewEnforcerFromConn connect to the database named in the URL
+// instead of creating the "casbin" database, e.g. for roles not allowed to create databases.
+// The URL constructors, NewAdapter included, also accept it as the query parameter skip_database_create=true.
+func SkipDatabaseCreate() Option {
+	return func(a *Adapter) {
+		a.skipDatabaseCreate = true
+	}
+}
+
+// parseSkipDatabaseCreate removes the skip_database_create parameter from the query of connURL, which go-pg rejects,
+// and returns the URL left and the parameter value.
+func parseSkipDatabaseCreate(connURL string) (string, bool, error) {
+	u, err := url.Parse(connURL)
+	if err != nil {
+		return "", false, err
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", false, err
+	}
+	value := query.Get(skipDatabaseCreateParam)
+	if value == "" {
+		return connURL, false, nil
+	}
+	skip, err := strconv.ParseBool(value)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s %q: %w", skipDatabaseCreateParam, value, err)
+	}
+	query.Del(skipDatabaseCreateParam)
+	u.RawQuery = query.Encode()
+	return u.String(), skip, nil
+}
@@ -0,0 +1,147 @@
+package pgadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/go-pg/pg/v10"
+)
+
+// Dispatcher is a persist.Dispatcher using Postgres to order the policy changes of the enforcers of all instances
+// and fan them out, without a separate messaging system. The changes are written through the adapter,
+// which appends them to the operation log, and every instance applies the log to its enforcer in the same order
+// when notified, its own changes included.
+type Dispatcher struct {
+	a  *Adapter
+	e  *casbin.DistributedEnforcer
+	ln *pg.Listener
+
+	// mu serializes the application of the log, lastID is the ID of the last applied entry.
+	mu     sync.Mutex
+	lastID int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ persist.Dispatcher = (*Dispatcher)(nil)
+
+// NewDispatcher creates a Dispatcher for e writing through a and sets it as the dispatcher of e,
+// a must be created with WithOperationLog and WithNotify. e reloads its policy, the changes logged afterwards
+// are applied to it. The changes are applied asynchronously, as the enforcer holds its lock while dispatching,
+// so the changes made through e are visible in e shortly after its methods return, see CatchUp.
+// The log is also applied every DefaultAutoLoadInterval in case a notification was missed.
+// Close the dispatcher before a.
+func NewDispatcher(a *Adapter, e *casbin.DistributedEnforcer) (*Dispatcher, error) {
+	if !a.operationLog || a.notifySender == "" {
+		return nil, errors.New("the dispatcher requires WithOperationLog and WithNotify")
+	}
+	if a.db == nil {
+		return nil, errNoPool
+	}
+
+	d := &Dispatcher{a: a, e: e, stop: make(chan struct{}), done: make(chan struct{})}
+	_, err := a.db.QueryOne(pg.Scan(&d.lastID), "SELECT coalesce(max(id), 0) FROM ? WHERE rule_table = ?",
+		pg.Ident(DefaultOperationLogTableName), a.tableName)
+	if err != nil {
+		return nil, err
+	}
+	// The changes logged between the query and the reload are applied twice, which is harmless.
+	if err := e.LoadPolicy(); err != nil {
+		return nil, err
+	}
+
+	d.ln = a.db.Listen(context.Background(), DefaultNotifyChannel)
+	e.SetDispatcher(d)
+	go d.run()
+	return d, nil
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	ticker := time.NewTicker(DefaultAutoLoadInterval)
+	defer ticker.Stop()
+	ch := d.ln.Channel()
+	for {
+		select {
+		case n, ok := <-ch:
+			if !ok {
+				return
+			}
+			var msg Notification
+			if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil || msg.Table != d.a.tableName {
+				continue
+			}
+		case <-ticker.C:
+		case <-d.stop:
+			return
+		}
+		if err := d.CatchUp(context.Background()); err != nil {
+			d.a.handleError(OpDispatch, "", 0, &err)
+		}
+	}
+}
+
+// CatchUp applies the changes logged since the last applied one to the enforcer,
+// e.g. to read the changes just made through the enforcer. It must not be called while the enforcer is locked.
+func (d *Dispatcher) CatchUp(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lastID, err := d.a.ReplayOperations(ctx, d.e, d.lastID)
+	d.lastID = lastID
+	return err
+}
+
+// Close stops applying the changes.
+func (d *Dispatcher) Close() {
+	close(d.stop)
+	d.ln.Close()
+	<-d.done
+}
+
+// AddPolicies adds the rules for all instances.
+func (d *Dispatcher) AddPolicies(sec string, ptype string, rules [][]string) error {
+	return d.a.AddPolicies(sec, ptype, rules)
+}
+
+// RemovePolicies removes the rules for all instances.
+func (d *Dispatcher) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	return d.a.RemovePolicies(sec, ptype, rules)
+}
+
+// RemoveFilteredPolicy removes the rules matching the filter for all instances.
+func (d *Dispatcher) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return d.a.RemoveFilteredPolicy(sec, ptype, fieldIndex, fieldValues...)
+}
+
+// ClearPolicy removes all rules for all instances.
+func (d *Dispatcher) ClearPolicy() error {
+	_, err := d.a.syncRules(context.Background(), nil)
+	return err
+}
+
+// UpdatePolicy replaces a rule for all instances.
+func (d *Dispatcher) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return d.a.UpdatePolicy(sec, ptype, oldRule, newRule)
+}
+
+// UpdatePolicies replaces rules for all instances.
+func (d *Dispatcher) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return d.a.UpdatePolicies(sec, ptype, oldRules, newRules)
+}
+
+// UpdateFilteredPolicies removes oldRules and adds newRules for all instances in a single transaction.
+func (d *Dispatcher) UpdateFilteredPolicies(sec string, ptype string, oldRules [][]string, newRules [][]string) error {
+	ctx := context.Background()
+	return d.a.Transaction(ctx, func(a *Adapter) error {
+		if err := a.RemovePoliciesCtx(ctx, sec, ptype, oldRules); err != nil {
+			return err
+		}
+		return a.AddPoliciesCtx(ctx, sec, ptype, newRules)
+	})
+}
@@ -0,0 +1,70 @@
+package pgadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestDispatcher() {
+	newEnforcer := func() (*casbin.DistributedEnforcer, *Dispatcher) {
+		a, err := NewAdapterByDB(s.a.db, WithOperationLog(), WithNotify())
+		s.Require().NoError(err)
+		e, err := casbin.NewDistributedEnforcer("examples/rbac_model.conf", a)
+		s.Require().NoError(err)
+		d, err := NewDispatcher(a, e)
+		s.Require().NoError(err)
+		return e, d
+	}
+	e1, d1 := newEnforcer()
+	defer d1.Close()
+	e2, d2 := newEnforcer()
+	defer d2.Close()
+
+	_, err := e1.AddPolicy("carol", "data1", "read")
+	s.Require().NoError(err)
+	_, err = e2.RemovePolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+
+	for _, e := range []*casbin.DistributedEnforcer{e1, e2} {
+		e := e
+		s.Require().Eventually(func() bool {
+			return e.HasPolicy("carol", "data1", "read") && !e.HasPolicy("alice", "data1", "read")
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// The changes can be applied right away.
+	_, err = e1.AddPolicy("dave", "data1", "read")
+	s.Require().NoError(err)
+	s.Require().NoError(d1.CatchUp(context.Background()))
+	s.Require().True(e1.HasPolicy("dave", "data1", "read"))
+}
+
+func (s *AdapterTestSuite) TestDispatcherCommitOrder() {
+	a, err := NewAdapterByDB(s.a.db, WithOperationLog(), WithNotify())
+	s.Require().NoError(err)
+	e, err := casbin.NewDistributedEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	d, err := NewDispatcher(a, e)
+	s.Require().NoError(err)
+	defer d.Close()
+
+	tx, err := s.a.db.Begin()
+	s.Require().NoError(err)
+	defer tx.Close()
+	s.Require().NoError(a.WithTx(tx).AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+
+	// The second transaction is allocated the next ID and tries to commit first.
+	done := make(chan error, 1)
+	go func() { done <- a.AddPolicy("p", "p", []string{"dave", "data1", "read"}) }()
+	time.Sleep(200 * time.Millisecond)
+	s.Require().NoError(d.CatchUp(context.Background()))
+	s.Require().False(e.HasPolicy("dave", "data1", "read"))
+
+	s.Require().NoError(tx.Commit())
+	s.Require().NoError(<-done)
+	s.Require().NoError(d.CatchUp(context.Background()))
+	s.Require().True(e.HasPolicy("carol", "data1", "read"), "the change committed last with the lower ID is applied")
+	s.Require().True(e.HasPolicy("dave", "data1", "read"))
+}
@@ -0,0 +1,102 @@
+package pgadapter
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DomainLoader loads the policy of an enforcer one domain at a time, when the domain is first needed,
+// for deployments with too many domains to load all of them up front.
+// The enforcer should be created without loading the policy, e.g. with casbin.NewEnforcer(model) and
+// SetAdapter, or its policy cleared before the first LoadDomainIfNeeded.
+type DomainLoader struct {
+	// PolicyDomainIndex is the index of the domain among the values of the p rules, 1 by default.
+	PolicyDomainIndex int
+	// RoleDomainIndex is the index of the domain among the values of the g rules, 2 by default.
+	RoleDomainIndex int
+
+	a      *Adapter
+	mu     sync.Mutex
+	loaded map[string]bool
+}
+
+// NewDomainLoader creates a DomainLoader for the rules of a, using the domain positions of
+// the usual RBAC with domains model: p = sub, dom, obj, act and g = _, _, _.
+func NewDomainLoader(a *Adapter) *DomainLoader {
+	return &DomainLoader{
+		PolicyDomainIndex: 1,
+		RoleDomainIndex:   2,
+		a:                 a,
+		loaded:            make(map[string]bool),
+	}
+}
+
+// LoadDomainIfNeeded adds the p and g rules of domain to the model of e unless they have been loaded already,
+// and rebuilds the role links. The adapter reports itself as filtered afterwards, so the partial policy can't be saved.
+func (l *DomainLoader) LoadDomainIfNeeded(ctx context.Context, e casbin.IEnforcer, domain string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.loaded[domain] {
+		return nil
+	}
+	if err := l.a.loadDomain(ctx, l, e, domain); err != nil {
+		return err
+	}
+	l.loaded[domain] = true
+	return nil
+}
+
+// Loaded reports whether the rules of domain have been loaded.
+func (l *DomainLoader) Loaded(domain string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loaded[domain]
+}
+
+// Reset forgets the loaded domains, it must be called when the policy of the enforcer is cleared or reloaded.
+func (l *DomainLoader) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.loaded = make(map[string]bool)
+}
+
+func (a *Adapter) loadDomain(ctx context.Context, l *DomainLoader, e casbin.IEnforcer, domain string) (err error) {
+	defer a.handleError(OpLoadFilteredPolicy, "", 0, &err)
+
+	var lines []*CasbinRule
+	query := a.loadQuery(a.conn().ModelContext(ctx, &lines).Table(a.tableName)).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("ptype LIKE 'p%' AND ? = ?", pg.Ident(valueColumn(l.PolicyDomainIndex)), domain).
+				WhereOr("ptype LIKE 'g%' AND ? = ?", pg.Ident(valueColumn(l.RoleDomainIndex)), domain)
+			return q, nil
+		})
+	if err := query.Select(); err != nil {
+		return err
+	}
+
+	model := e.GetModel()
+	skipped := skippedRules{}
+	for _, line := range lines {
+		if !a.loadAllowed(line.Ptype, skipped) {
+			continue
+		}
+		if err := persist.LoadPolicyLine(line.String(), model); err != nil {
+			return err
+		}
+	}
+	a.reportSkipped(OpLoadFilteredPolicy, skipped)
+	a.filtered = true
+	return e.BuildRoleLinks()
+}
+
+// valueColumn returns the name of the column holding the value at index i.
+func valueColumn(i int) string {
+	return "v" + strconv.Itoa(i)
+}
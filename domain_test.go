@@ -0,0 +1,39 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestDomainLoader() {
+	a, err := s.a.WithTable("casbin_rule_domains")
+	s.Require().NoError(err)
+	err = SeedPoliciesFromFile(context.Background(), s.a.db, "examples/rbac_with_domains_policy.csv", WithTableName("casbin_rule_domains"))
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_with_domains_model.conf")
+	s.Require().NoError(err)
+	e.SetAdapter(a)
+
+	l := NewDomainLoader(a)
+	ctx := context.Background()
+	s.Require().NoError(l.LoadDomainIfNeeded(ctx, e, "domain1"))
+	s.Require().True(l.Loaded("domain1"))
+	s.Require().False(l.Loaded("domain2"))
+	s.Require().True(a.IsFiltered())
+
+	ok, err := e.Enforce("alice", "domain1", "data1", "read")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	ok, err = e.Enforce("bob", "domain2", "data2", "read")
+	s.Require().NoError(err)
+	s.Require().False(ok, "domain2 is not loaded yet")
+
+	s.Require().NoError(l.LoadDomainIfNeeded(ctx, e, "domain2"))
+	s.Require().NoError(l.LoadDomainIfNeeded(ctx, e, "domain2"))
+	ok, err = e.Enforce("bob", "domain2", "data2", "read")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	s.Require().Len(e.GetPolicy(), 4, "domains are loaded once")
+}
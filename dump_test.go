@@ -0,0 +1,37 @@
+package pgadapter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPolicyJSON(t *testing.T) {
+	rules, err := readPolicyJSON(strings.NewReader(`[["p","alice","data1","read"],["g","alice","admin"]]`))
+	require.NoError(t, err)
+	require.Equal(t, map[string][][]string{"p": {{"alice", "data1", "read"}}, "g": {{"alice", "admin"}}}, rules)
+
+	_, err = readPolicyJSON(strings.NewReader(`{"p": []}`))
+	require.Error(t, err)
+}
+
+func (s *AdapterTestSuite) TestDumpRestorePolicy() {
+	ctx := context.Background()
+	for _, format := range []DumpFormat{DumpCSV, DumpJSON} {
+		var buf bytes.Buffer
+		s.Require().NoError(s.a.DumpPolicy(ctx, &buf, format))
+
+		s.Require().NoError(s.a.RemoveFilteredPolicy("p", "p", 0, "alice"))
+		report, err := s.a.RestorePolicy(ctx, &buf, format)
+		s.Require().NoError(err)
+		s.Require().Equal([][]string{{"p", "alice", "data1", "read"}}, report.Added)
+		s.Require().Empty(report.Removed)
+	}
+
+	var buf bytes.Buffer
+	s.Require().NoError(s.a.DumpPolicy(ctx, &buf, DumpJSON))
+	s.Require().True(strings.HasPrefix(buf.String(), "[\n[\"g\",\"alice\",\"data2_admin\"],\n"), buf.String())
+}
@@ -0,0 +1,104 @@
+package pgadapter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/go-pg/pg/v10"
+)
+
+// DefaultAutoLoadInterval is the interval at which the enforcers created by NewSyncedEnforcer reload the policy.
+const DefaultAutoLoadInterval = 30 * time.Second
+
+// NewSyncedEnforcer creates an adapter for the PostgreS URL connURL like NewAdapter, configured with opts,
+// and a SyncedEnforcer using the model at modelPath and the adapter. The enforcer reloads the policy
+// every DefaultAutoLoadInterval, so the changes made by the other replicas are picked up.
+// With WithNotify, the enforcer also reloads the policy as soon as another replica changed it, using a Watcher
+// closed with the adapter. The caller stops the reloading with StopAutoLoadPolicy and closes the adapter when done.
+func NewSyncedEnforcer(modelPath, connURL string, opts ...Option) (*casbin.SyncedEnforcer, *Adapter, error) {
+	a, err := newAdapterFromConn(connURL, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, err := casbin.NewSyncedEnforcer(modelPath, a)
+	if err != nil {
+		a.Close()
+		return nil, nil, err
+	}
+	if a.notifySender != "" {
+		w, err := NewWatcher(a)
+		if err != nil {
+			a.Close()
+			return nil, nil, err
+		}
+		a.watcher = w
+		if err := e.SetWatcher(w); err != nil {
+			a.Close()
+			return nil, nil, err
+		}
+	}
+	e.StartAutoLoadPolicy(DefaultAutoLoadInterval)
+	return e, a, nil
+}
+
+// NewEnforcerFromConn creates an adapter for the PostgreS URL connURL like NewAdapter, configured with opts,
+// and an enforcer using the adapter with its policy loaded. modelConf is either the path of the model file
+// or the model configuration itself, which is recognized by its [request_definition] section.
+// The caller closes the adapter when done.
+func NewEnforcerFromConn(connURL, modelConf string, opts ...Option) (*casbin.Enforcer, *Adapter, error) {
+	m, err := loadModel(modelConf)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := newAdapterFromConn(connURL, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, err := casbin.NewEnforcer(m, a)
+	if err != nil {
+		a.Close()
+		return nil, nil, err
+	}
+	return e, a, nil
+}
+
+// loadModel loads the model configuration text or file modelConf.
+func loadModel(modelConf string) (model.Model, error) {
+	if strings.Contains(modelConf, "[request_definition]") {
+		return model.NewModelFromString(modelConf)
+	}
+	return model.NewModelFromFile(modelConf)
+}
+
+// newAdapterFromConn creates the default database like NewAdapter and an adapter for it configured with opts.
+func newAdapterFromConn(arg interface{}, opts []Option) (*Adapter, error) {
+	// The options are applied beforehand to retry the database creation and set up the connection,
+	// see WithConnectRetry, WithPoolOptions and WithTLSConfig.
+	a := &Adapter{tableName: DefaultTableName}
+	for _, opt := range opts {
+		opt(a)
+	}
+	var db *pg.DB
+	err := a.retryConnect(func() error {
+		var err error
+		db, err = createCasbinDatabase(arg, "", a)
+		return err
+	})
+	if err != nil {
+		if a.webhook != nil {
+			a.webhook.close()
+		}
+		wrapError(OpNewAdapter, &err)
+		return nil, err
+	}
+	a, err = newAdapterByDB(db, a)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return a, nil
+}
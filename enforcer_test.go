@@ -0,0 +1,87 @@
+package pgadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *AdapterTestSuite) TestNewSyncedEnforcer() {
+	e, a, err := NewSyncedEnforcer("examples/rbac_model.conf", os.Getenv("PG_CONN"), WithActor("replica-1"))
+	s.Require().NoError(err)
+	defer a.Close()
+	defer e.StopAutoLoadPolicy()
+
+	s.Require().True(e.IsAutoLoadingRunning())
+	ok, err := e.Enforce("alice", "data2", "read")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+}
+
+func TestLoadModel(t *testing.T) {
+	m, err := loadModel("examples/rbac_model.conf")
+	require.NoError(t, err)
+	text, err := os.ReadFile("examples/rbac_model.conf")
+	require.NoError(t, err)
+	m2, err := loadModel(string(text))
+	require.NoError(t, err)
+	// ToText walks the maps of the model, so the sections are compared one by one.
+	require.Len(t, m2, len(m))
+	for sec, assertions := range m {
+		require.Len(t, m2[sec], len(assertions), sec)
+		for key, assertion := range assertions {
+			require.Contains(t, m2[sec], key)
+			require.Equal(t, assertion.Value, m2[sec][key].Value, key)
+		}
+	}
+
+	_, err = loadModel("examples/missing.conf")
+	require.Error(t, err)
+}
+
+func (s *AdapterTestSuite) TestNewEnforcerFromConn() {
+	e, a, err := NewEnforcerFromConn(os.Getenv("PG_CONN"), "examples/rbac_model.conf")
+	s.Require().NoError(err)
+	defer a.Close()
+
+	ok, err := e.Enforce("alice", "data2", "read")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+}
+
+func TestNewEnforcerFromConnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	before := webhookGoroutines()
+	_, _, err := NewEnforcerFromConn("postgres://postgres@127.0.0.1:1/casbin?sslmode=disable&connect_timeout=1",
+		"examples/rbac_model.conf", WithWebhook(srv.URL, "key"))
+	require.Error(t, err)
+	require.Eventually(t, func() bool { return webhookGoroutines() == before }, time.Second, 10*time.Millisecond,
+		"the webhook of the failed adapter is stopped")
+}
+
+func (s *AdapterTestSuite) TestNewEnforcerFromConnWebhook() {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	before := webhookGoroutines()
+	_, a, err := NewEnforcerFromConn(os.Getenv("PG_CONN"), "examples/rbac_model.conf", WithWebhook(srv.URL, "key"))
+	s.Require().NoError(err)
+	s.Require().NoError(a.Close())
+	// The options are applied once, so no webhook is left running.
+	s.Require().Eventually(func() bool { return webhookGoroutines() == before }, time.Second, 10*time.Millisecond)
+}
+
+// webhookGoroutines returns the number of running webhook deliveries. The goroutines of go-pg are left out,
+// its pools stop their reaper only at the next tick after being closed.
+func webhookGoroutines() int {
+	buf := make([]byte, 1<<20)
+	return strings.Count(string(buf[:runtime.Stack(buf, true)]), "(*webhook).run(")
+}
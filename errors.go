@@ -0,0 +1,168 @@
+package pgadapter
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Failure classes of the errors returned by the adapter, test for them with errors.Is.
+// The underlying pg error stays reachable through errors.As.
+var (
+	ErrDatabaseCreate  = errors.New("database could not be created")
+	ErrTableMissing    = errors.New("rules table does not exist")
+	ErrPolicyExists    = errors.New("policy already exists")
+	ErrNotFound        = errors.New("policy not found")
+	ErrTooManyFields   = errors.New("too many fields, should not exceed 6 values")
+	ErrConnUnavailable = errors.New("database connection unavailable")
+	ErrConflict        = errors.New("policy has been changed by another writer")
+	ErrQuotaExceeded   = errors.New("policy quota exceeded")
+	ErrPtypeNotAllowed = errors.New("ptype is not allowed")
+	ErrSelfApproval    = errors.New("changes must be approved by someone else than their proposer")
+	ErrInvalidRule     = errors.New("invalid rule")
+
+	// ErrReadOnly is returned by every write while the adapter is in maintenance mode.
+	ErrReadOnly = errors.New("policy writes are disabled (maintenance mode)")
+)
+
+// Postgres error codes the adapter reacts to.
+const (
+	pgCodeUniqueViolation = "23505"
+	pgCodeUndefinedTable  = "42P01"
+	pgCodeUndefinedColumn = "42703"
+	pgCodeDuplicateDB     = "42P04"
+
+	pgCodeInsufficientPrivilege = "42501"
+
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlock             = "40P01"
+
+	// pgCodeCannotConnectNow is returned while the server is starting up or shutting down.
+	pgCodeCannotConnectNow = "57P03"
+)
+
+// Error is the error returned by the adapter operations.
+type Error struct {
+	// Op is the adapter operation that failed, e.g. "AddPolicy".
+	Op string
+	// Kind is one of the Err* failure classes, it is nil when the failure is not classified.
+	Kind error
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Kind == nil || e.Kind == e.Err {
+		return "pgadapter." + e.Op + ": " + e.Err.Error()
+	}
+	return "pgadapter." + e.Op + ": " + e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error belongs to the target failure class.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+// ErrorHook is called with the failed operation, the returned error and some context about the operation:
+// "table" is always set, "ptype" and "rule_count" are set when known.
+type ErrorHook func(op string, err error, meta map[string]interface{})
+
+// WithErrorHook registers a hook called whenever an adapter operation fails,
+// for example to report the errors to Sentry.
+func WithErrorHook(hook ErrorHook) Option {
+	return func(a *Adapter) {
+		a.errorHook = hook
+	}
+}
+
+// handleError wraps *err like wrapError and reports it to the error hook, it is meant to be deferred.
+func (a *Adapter) handleError(op string, ptype string, ruleCount int, err *error) {
+	if *err == nil {
+		return
+	}
+	wrapError(op, err)
+
+	if a.logger != nil {
+		a.logger.Printf("%v", *err)
+	}
+	if a.errorHook == nil {
+		return
+	}
+	meta := map[string]interface{}{"table": a.tableName}
+	if ptype != "" {
+		meta["ptype"] = ptype
+	}
+	if ruleCount > 0 {
+		meta["rule_count"] = ruleCount
+	}
+	a.errorHook(op, *err, meta)
+}
+
+// wrapError classifies *err and wraps it into an *Error for op, it is meant to be deferred.
+func wrapError(op string, err *error) {
+	if *err == nil {
+		return
+	}
+	var e *Error
+	if errors.As(*err, &e) {
+		return
+	}
+	*err = &Error{Op: op, Kind: errorKind(*err), Err: *err}
+}
+
+func errorKind(err error) error {
+	for _, kind := range []error{
+		ErrDatabaseCreate, ErrTableMissing, ErrPolicyExists, ErrNotFound, ErrTooManyFields,
+		ErrConnUnavailable, ErrConflict, ErrQuotaExceeded, ErrPtypeNotAllowed, ErrSelfApproval, ErrInvalidRule,
+	} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	switch pgErrorCode(err) {
+	case pgCodeUndefinedTable:
+		return ErrTableMissing
+	case pgCodeUniqueViolation:
+		return ErrPolicyExists
+	case pgCodeDeadlock, pgCodeSerializationFailure:
+		return ErrConflict
+	case pgCodeCannotConnectNow:
+		return ErrConnUnavailable
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, pg.ErrNoRows):
+		return ErrNotFound
+	case errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrConnUnavailable
+	}
+
+	// go-pg keeps its pool errors internal, so they can only be recognized by message.
+	switch err.Error() {
+	case "pg: database is closed", "pg: connection pool timeout":
+		return ErrConnUnavailable
+	}
+	return nil
+}
+
+// pgErrorCode returns the SQLSTATE code of err or "" if err is not a Postgres error.
+// The errors of other drivers are recognized by their SQLState method.
+func pgErrorCode(err error) string {
+	var pgErr pg.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C')
+	}
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package pgadapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKinds(t *testing.T) {
+	err := fmt.Errorf("start DB transaction: %w", io.EOF)
+	wrapError(OpSavePolicy, &err)
+	require.ErrorIs(t, err, ErrConnUnavailable)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, "pgadapter.SavePolicy: database connection unavailable: start DB transaction: EOF", err.Error())
+
+	err = pg.ErrNoRows
+	wrapError(OpLoadPolicy, &err)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	err = errors.New("boom")
+	wrapError(OpAddPolicy, &err)
+	var adapterErr *Error
+	require.True(t, errors.As(err, &adapterErr))
+	require.Equal(t, OpAddPolicy, adapterErr.Op)
+	require.Nil(t, adapterErr.Kind)
+
+	// Already wrapped errors are left untouched.
+	wrapped := err
+	wrapError(OpRemovePolicy, &err)
+	require.Equal(t, wrapped, err)
+
+	_, err = buildQuery(nil, []string{"", "", "", "", "", "", "extra"})
+	wrapError(OpLoadFilteredPolicy, &err)
+	require.ErrorIs(t, err, ErrTooManyFields)
+}
+
+func (s *AdapterTestSuite) TestErrTableMissing() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_missing"), SkipTableCreate())
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf")
+	s.Require().NoError(err)
+	err = a.LoadPolicy(e.GetModel())
+	s.Require().ErrorIs(err, ErrTableMissing)
+}
+
+func TestErrorHook(t *testing.T) {
+	var ops []string
+	var metas []map[string]interface{}
+	a := &Adapter{tableName: DefaultTableName}
+	WithErrorHook(func(op string, err error, meta map[string]interface{}) {
+		require.ErrorIs(t, err, ErrReadOnly)
+		ops = append(ops, op)
+		metas = append(metas, meta)
+	})(a)
+	a.SetWritable(false)
+
+	err := a.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}})
+	require.ErrorIs(t, err, ErrReadOnly)
+	err = a.SavePolicy(nil)
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	require.Equal(t, []string{OpAddPolicies, OpSavePolicy}, ops)
+	require.Equal(t, map[string]interface{}{"table": DefaultTableName, "ptype": "p", "rule_count": 2}, metas[0])
+	require.Equal(t, map[string]interface{}{"table": DefaultTableName}, metas[1])
+}
@@ -0,0 +1,47 @@
+package pgadapter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const rbacPolicyExport = `g,alice,data2_admin
+p,alice,data1,read
+p,bob,data2,write
+p,data2_admin,data2,read
+p,data2_admin,data2,write
+`
+
+func TestFakeExportPolicies(t *testing.T) {
+	_, f := newFakeEnforcer(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, f.ExportPolicies(context.Background(), &buf))
+	require.Equal(t, rbacPolicyExport, buf.String())
+}
+
+func TestSortRules(t *testing.T) {
+	lines := []*CasbinRule{
+		savePolicyLine("p", []string{"b", "x"}),
+		savePolicyLine("p", []string{"a", "x", "y"}),
+		savePolicyLine("g", []string{"z"}),
+		savePolicyLine("p", []string{"a", "x"}),
+		savePolicyLine("p", []string{"B"}),
+	}
+	sortRules(lines)
+
+	var rules [][]string
+	for _, line := range lines {
+		rules = append(rules, line.ptypeRule())
+	}
+	require.Equal(t, [][]string{{"g", "z"}, {"p", "B"}, {"p", "a", "x"}, {"p", "a", "x", "y"}, {"p", "b", "x"}}, rules)
+}
+
+func (s *AdapterTestSuite) TestExportPolicies() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.a.ExportPolicies(context.Background(), &buf))
+	s.Require().Equal(rbacPolicyExport, buf.String())
+}
@@ -0,0 +1,66 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExtraColumn is an application column of the rules table, e.g. owner_service or ticket_id,
+// the adapter writes when it inserts rules and ignores when it loads them, see WithExtraColumns.
+type ExtraColumn struct {
+	// Name is the name of the column.
+	Name string
+	// Type is the Postgres type of the column, text when empty.
+	Type string
+	// Value returns the value of the column for the rules inserted by the operation of ctx,
+	// e.g. one derived from ContextWithActor, an empty value storing NULL.
+	Value func(ctx context.Context) string
+}
+
+// extraSettingPrefix prefixes the settings carrying the values of the extra columns within the write transactions.
+const extraSettingPrefix = "casbin_extra."
+
+// WithExtraColumns adds the columns to the rules table if needed and makes the adapter fill them
+// for the rules it inserts. The values are passed as settings of the write transactions read by the default
+// of the columns, so all the ways of inserting rules set them, while the updated rules keep theirs.
+// The rules inserted by other writers get NULL, unless they set the casbin_extra.<name> settings too.
+// The backends of NewSQLAdapter don't fill the columns.
+func WithExtraColumns(columns ...ExtraColumn) Option {
+	return func(a *Adapter) {
+		a.extraColumns = append(a.extraColumns, columns...)
+	}
+}
+
+func (a *Adapter) createExtraColumns() error {
+	for _, column := range a.extraColumns {
+		if column.Name == "" || containsString(ruleColumns, column.Name) {
+			return fmt.Errorf("invalid extra column %q", column.Name)
+		}
+	}
+	table := a.ruleView().table
+	for _, column := range a.extraColumns {
+		typ := column.Type
+		if typ == "" {
+			typ = "text"
+		}
+		def := fmt.Sprintf("NULLIF(current_setting(%s, true), '')::%s", quoteLiteral(extraSettingPrefix+column.Name), typ)
+		_, err := a.conn().Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", quoteQualified(table), quoteIdent(column.Name), typ))
+		if err != nil {
+			return err
+		}
+		_, err = a.conn().Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", quoteQualified(table), quoteIdent(column.Name), def))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extraSettings adds the values of the extra columns for the operation of ctx to settings.
+func (a *Adapter) extraSettings(ctx context.Context, settings map[string]string) {
+	for _, column := range a.extraColumns {
+		if column.Value != nil {
+			settings[extraSettingPrefix+column.Name] = column.Value(ctx)
+		}
+	}
+}
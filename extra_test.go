@@ -0,0 +1,60 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+type ticketKey struct{}
+
+func TestExtraSettings(t *testing.T) {
+	a := &Adapter{}
+	WithExtraColumns(
+		ExtraColumn{Name: "owner_service", Value: func(context.Context) string { return "billing" }},
+		ExtraColumn{Name: "ticket_id", Type: "bigint", Value: func(ctx context.Context) string {
+			id, _ := ctx.Value(ticketKey{}).(string)
+			return id
+		}},
+	)(a)
+
+	settings := map[string]string{}
+	a.extraSettings(context.WithValue(context.Background(), ticketKey{}, "42"), settings)
+	require.Equal(t, map[string]string{"casbin_extra.owner_service": "billing", "casbin_extra.ticket_id": "42"}, settings)
+
+	WithExtraColumns(ExtraColumn{Name: "ptype"})(a)
+	require.Error(t, a.createExtraColumns())
+}
+
+func (s *AdapterTestSuite) TestExtraColumns() {
+	a, err := NewAdapterByDB(s.a.db, WithExtraColumns(
+		ExtraColumn{Name: "owner_service", Value: func(context.Context) string { return "billing" }},
+		ExtraColumn{Name: "ticket_id", Type: "bigint", Value: func(ctx context.Context) string {
+			id, _ := ctx.Value(ticketKey{}).(string)
+			return id
+		}},
+	))
+	s.Require().NoError(err)
+
+	ctx := context.WithValue(context.Background(), ticketKey{}, "42")
+	s.Require().NoError(a.AddPolicyCtx(ctx, "p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"dave", "data1", "read"}))
+
+	var owner string
+	var ticket *int64
+	_, err = s.a.db.QueryOne(pg.Scan(&owner, &ticket), "SELECT owner_service, ticket_id FROM casbin_rule WHERE v0 = 'carol'")
+	s.Require().NoError(err)
+	s.Require().Equal("billing", owner)
+	s.Require().Equal(int64(42), *ticket)
+	_, err = s.a.db.QueryOne(pg.Scan(&owner, &ticket), "SELECT owner_service, ticket_id FROM casbin_rule WHERE v0 = 'dave'")
+	s.Require().NoError(err)
+	s.Require().Nil(ticket, "an empty value is stored as NULL")
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.Require().True(e.HasPolicy("carol", "data1", "read"))
+	s.Require().NoError(a.SavePolicy(e.GetModel()))
+}
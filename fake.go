@@ -0,0 +1,494 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+// Fake is an in-memory PolicyStore with the same semantics as Adapter:
+// rules are keyed by the same ID hash so duplicates are ignored, empty values are skipped
+// the same way when loading and filtering, and rules are loaded in insertion order.
+// It is meant for unit tests that shouldn't need a database.
+type Fake struct {
+	// revision is accessed atomically and must stay 64-bit aligned.
+	revision uint64
+	readOnly int32
+	subs     subscriptions
+
+	mu       sync.Mutex
+	rules    []*CasbinRule
+	filtered bool
+}
+
+var _ PolicyStore = (*Fake)(nil)
+
+// NewFake creates an empty in-memory policy store.
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// Close closes the subscriptions of the fake.
+func (f *Fake) Close() error {
+	f.subs.close()
+	return nil
+}
+
+// Revision returns the revision of the last change made to the fake.
+func (f *Fake) Revision() uint64 {
+	return atomic.LoadUint64(&f.revision)
+}
+
+// Subscribe returns a channel receiving every PolicyChange made to the fake, see Adapter.Subscribe.
+func (f *Fake) Subscribe(ctx context.Context) (<-chan PolicyChange, error) {
+	return f.subs.subscribe(ctx)
+}
+
+// SetWritable enables or disables writes, see Adapter.SetWritable.
+func (f *Fake) SetWritable(writable bool) {
+	var readOnly int32
+	if !writable {
+		readOnly = 1
+	}
+	atomic.StoreInt32(&f.readOnly, readOnly)
+}
+
+// IsWritable reports whether writes are enabled.
+func (f *Fake) IsWritable() bool {
+	return atomic.LoadInt32(&f.readOnly) == 0
+}
+
+// SetClusterWritable behaves like SetWritable, the fake has no cluster.
+func (f *Fake) SetClusterWritable(_ context.Context, writable bool) error {
+	f.SetWritable(writable)
+	return nil
+}
+
+// Rules returns a copy of the stored rules in insertion order.
+func (f *Fake) Rules() []CasbinRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rules := make([]CasbinRule, 0, len(f.rules))
+	for _, r := range f.rules {
+		rules = append(rules, *r)
+	}
+	return rules
+}
+
+func (f *Fake) changed(change PolicyChange) {
+	change.Revision = atomic.AddUint64(&f.revision, 1)
+	f.subs.publish(change)
+}
+
+func (f *Fake) checkWritable() error {
+	if !f.IsWritable() {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// insert appends line unless a rule with the same ID is stored, like ON CONFLICT DO NOTHING,
+// and reports whether it was appended. The caller must hold f.mu.
+func (f *Fake) insert(line *CasbinRule) bool {
+	for _, r := range f.rules {
+		if r.ID == line.ID {
+			return false
+		}
+	}
+	f.rules = append(f.rules, line)
+	return true
+}
+
+// deleteWhere removes and returns the rules for which match returns true.
+// The caller must hold f.mu.
+func (f *Fake) deleteWhere(match func(*CasbinRule) bool) []*CasbinRule {
+	var removed []*CasbinRule
+	kept := f.rules[:0]
+	for _, r := range f.rules {
+		if match(r) {
+			removed = append(removed, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	f.rules = kept
+	return removed
+}
+
+// LoadPolicy loads all rules into model.
+func (f *Fake) LoadPolicy(model model.Model) (err error) {
+	defer wrapError(OpLoadPolicy, &err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, line := range f.rules {
+		if err := persist.LoadPolicyLine(line.String(), model); err != nil {
+			return err
+		}
+	}
+	f.filtered = false
+	return nil
+}
+
+// LoadPolicyCtx is like LoadPolicy, the Fake ignores ctx.
+func (f *Fake) LoadPolicyCtx(_ context.Context, model model.Model) error {
+	return f.LoadPolicy(model)
+}
+
+// SavePolicy replaces all rules with the ones of model.
+func (f *Fake) SavePolicy(model model.Model) (err error) {
+	defer wrapError(OpSavePolicy, &err)
+
+	if err := f.checkWritable(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.rules = nil
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range model[sec] {
+			for _, rule := range ast.Policy {
+				f.insert(savePolicyLine(ptype, rule))
+			}
+		}
+	}
+	f.mu.Unlock()
+
+	f.changed(PolicyChange{Operation: OpSavePolicy})
+	return nil
+}
+
+// SavePolicyCtx is like SavePolicy, the Fake ignores ctx.
+func (f *Fake) SavePolicyCtx(_ context.Context, model model.Model) error {
+	return f.SavePolicy(model)
+}
+
+// AddPolicy adds a rule, it is a no-op if the rule exists.
+func (f *Fake) AddPolicy(sec string, ptype string, rule []string) error {
+	_, err := f.addPolicies(OpAddPolicy, sec, ptype, [][]string{rule})
+	return err
+}
+
+// AddPolicyCtx is like AddPolicy, the Fake ignores ctx.
+func (f *Fake) AddPolicyCtx(_ context.Context, sec string, ptype string, rule []string) error {
+	return f.AddPolicy(sec, ptype, rule)
+}
+
+// AddPolicies adds rules, the existing ones are skipped.
+func (f *Fake) AddPolicies(sec string, ptype string, rules [][]string) error {
+	_, err := f.addPolicies(OpAddPolicies, sec, ptype, rules)
+	return err
+}
+
+// AddPoliciesReturning is like AddPolicies but returns the rules that were inserted.
+func (f *Fake) AddPoliciesReturning(sec string, ptype string, rules [][]string) ([][]string, error) {
+	return f.addPolicies(OpAddPolicies, sec, ptype, rules)
+}
+
+func (f *Fake) addPolicies(op string, sec string, ptype string, rules [][]string) (_ [][]string, err error) {
+	defer wrapError(op, &err)
+
+	if err := f.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	var inserted [][]string
+	f.mu.Lock()
+	for _, rule := range rules {
+		if f.insert(savePolicyLine(ptype, rule)) {
+			inserted = append(inserted, rule)
+		}
+	}
+	f.mu.Unlock()
+
+	f.changed(PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: rules})
+	return inserted, nil
+}
+
+// RemovePolicy removes the rule with the ID of rule.
+func (f *Fake) RemovePolicy(sec string, ptype string, rule []string) error {
+	return f.removePolicies(OpRemovePolicy, sec, ptype, [][]string{rule})
+}
+
+// RemovePolicyCtx is like RemovePolicy, the Fake ignores ctx.
+func (f *Fake) RemovePolicyCtx(_ context.Context, sec string, ptype string, rule []string) error {
+	return f.RemovePolicy(sec, ptype, rule)
+}
+
+// RemovePolicies removes the rules with the IDs of rules.
+func (f *Fake) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	return f.removePolicies(OpRemovePolicies, sec, ptype, rules)
+}
+
+func (f *Fake) removePolicies(op string, sec string, ptype string, rules [][]string) (err error) {
+	defer wrapError(op, &err)
+
+	if err := f.checkWritable(); err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		ids[policyID(ptype, rule)] = true
+	}
+
+	f.mu.Lock()
+	f.deleteWhere(func(r *CasbinRule) bool { return ids[r.ID] })
+	f.mu.Unlock()
+
+	f.changed(PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: rules})
+	return nil
+}
+
+// RemoveFilteredPolicy removes the rules matching the non-empty field values.
+func (f *Fake) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	_, err := f.RemoveFilteredPolicyReturning(sec, ptype, fieldIndex, fieldValues...)
+	return err
+}
+
+// RemoveFilteredPolicyCtx is like RemoveFilteredPolicy, the Fake ignores ctx.
+func (f *Fake) RemoveFilteredPolicyCtx(_ context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return f.RemoveFilteredPolicy(sec, ptype, fieldIndex, fieldValues...)
+}
+
+// RemoveFilteredPolicyReturning is like RemoveFilteredPolicy but returns the removed rules.
+func (f *Fake) RemoveFilteredPolicyReturning(sec string, ptype string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer wrapError(OpRemoveFilteredPolicy, &err)
+
+	if err := f.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	lines := f.deleteWhere(func(r *CasbinRule) bool {
+		if r.Ptype != ptype {
+			return false
+		}
+		for i := 0; i < 6; i++ {
+			if fieldIndex <= i && i < fieldIndex+len(fieldValues) {
+				v := fieldValues[i-fieldIndex]
+				if v != "" && r.field(i) != v {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	f.mu.Unlock()
+
+	removed := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		removed = append(removed, line.rule())
+	}
+
+	f.changed(PolicyChange{
+		Operation:  OpRemoveFilteredPolicy,
+		Sec:        sec,
+		Ptype:      ptype,
+		Rules:      [][]string{fieldValues},
+		FieldIndex: fieldIndex,
+		Removed:    removed,
+	})
+	return removed, nil
+}
+
+// GetPolicyByID returns the rule stored under id, see Adapter.GetPolicyByID.
+func (f *Fake) GetPolicyByID(_ context.Context, id string) (ptype string, rule []string, err error) {
+	defer wrapError(OpGetPolicyByID, &err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range f.rules {
+		if r.ID == id {
+			return r.Ptype, r.rule(), nil
+		}
+	}
+	return "", nil, ErrNotFound
+}
+
+// IsEmpty reports whether the fake holds no rule.
+func (f *Fake) IsEmpty(_ context.Context) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.rules) == 0, nil
+}
+
+// HasPolicy reports whether rule is stored.
+func (f *Fake) HasPolicy(_ context.Context, ptype string, rule []string) (bool, error) {
+	id := policyID(ptype, rule)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range f.rules {
+		if r.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetFilteredPolicies returns the rules of ptype matching the non-empty field values in canonical order,
+// see Adapter.GetFilteredPolicies.
+func (f *Fake) GetFilteredPolicies(ptype string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer wrapError(OpGetFilteredPolicies, &err)
+
+	// Like the query of Adapter, the values past v5 are ignored.
+	values := append(make([]string, fieldIndex), fieldValues...)
+	if len(values) > 6 {
+		values = values[:6]
+	}
+
+	f.mu.Lock()
+	var lines []*CasbinRule
+	for _, line := range f.rules {
+		if line.Ptype == ptype && line.matches(values) {
+			lines = append(lines, line)
+		}
+	}
+	f.mu.Unlock()
+
+	sortRules(lines)
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.rule())
+	}
+	return rules, nil
+}
+
+// LoadFilteredPolicy loads the rules matching filter, which must be a *Filter, see Adapter.LoadFilteredPolicy.
+func (f *Fake) LoadFilteredPolicy(model model.Model, filter interface{}) (err error) {
+	defer wrapError(OpLoadFilteredPolicy, &err)
+
+	if filter == nil {
+		return f.LoadPolicy(model)
+	}
+	filterValue, ok := filter.(*Filter)
+	if !ok {
+		return fmt.Errorf("invalid filter type")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// The fake stores no labels, so no rule carries the labels of the filter.
+	if len(filterValue.Labels) > 0 {
+		f.filtered = true
+		return nil
+	}
+
+	for _, sec := range filterValue.sections() {
+		if len(sec.values) > 6 {
+			return ErrTooManyFields
+		}
+		for _, line := range f.rules {
+			if line.Ptype == sec.ptype && line.matches(sec.values) {
+				persist.LoadPolicyLine(line.String(), model)
+			}
+		}
+	}
+	f.filtered = true
+	return nil
+}
+
+// IsFiltered returns true if the loaded policy has been filtered.
+func (f *Fake) IsFiltered() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.filtered
+}
+
+// UpdatePolicy updates a rule, see Adapter.UpdatePolicy.
+func (f *Fake) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return f.updatePolicies(OpUpdatePolicy, sec, ptype, [][]string{oldRule}, [][]string{newRule})
+}
+
+// UpdatePolicies updates rules, see Adapter.UpdatePolicies.
+func (f *Fake) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return f.updatePolicies(OpUpdatePolicies, sec, ptype, oldRules, newRules)
+}
+
+func (f *Fake) updatePolicies(op string, sec string, ptype string, oldRules, newRules [][]string) (err error) {
+	defer wrapError(op, &err)
+
+	if err := f.checkWritable(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	// The rules are updated on a copy, so a conflict leaves them unchanged like the transaction of Adapter.
+	rules := make([]*CasbinRule, len(f.rules))
+	for i, r := range f.rules {
+		line := *r
+		rules[i] = &line
+	}
+	for i, rule := range oldRules {
+		old := savePolicyLine(ptype, rule)
+		for _, r := range rules {
+			if r.Ptype != old.Ptype || !r.matches(old.values()) {
+				continue
+			}
+			// Like the UPDATE statement of Adapter, the row gets the ID of its new rule, which must not be stored.
+			line := savePolicyLine(ptype, newRules[i])
+			for _, other := range rules {
+				if other != r && other.ID == line.ID {
+					f.mu.Unlock()
+					return fmt.Errorf("%w: %v", ErrPolicyExists, newRules[i])
+				}
+			}
+			*r = *line
+		}
+	}
+	f.rules = rules
+	f.mu.Unlock()
+
+	f.changed(PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: newRules, OldRules: oldRules})
+	return nil
+}
+
+// UpdateFilteredPolicies replaces the rules matching the filter with newRules and returns the replaced rules.
+func (f *Fake) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer wrapError(OpUpdateFilteredPolicies, &err)
+
+	if err := f.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	filter := make([]string, 6)
+	for i := range filter {
+		if fieldIndex <= i && i < fieldIndex+len(fieldValues) {
+			filter[i] = fieldValues[i-fieldIndex]
+		}
+	}
+
+	f.mu.Lock()
+	removed := f.deleteWhere(func(r *CasbinRule) bool {
+		return r.Ptype == ptype && r.matches(filter)
+	})
+	for _, rule := range newRules {
+		f.insert(savePolicyLine(ptype, rule))
+	}
+	f.mu.Unlock()
+
+	oldRules := make([][]string, 0, len(removed))
+	for _, r := range removed {
+		oldRules = append(oldRules, r.rule())
+	}
+
+	f.changed(PolicyChange{
+		Operation:  OpUpdateFilteredPolicies,
+		Sec:        sec,
+		Ptype:      ptype,
+		Rules:      newRules,
+		OldRules:   oldRules,
+		FieldIndex: fieldIndex,
+	})
+	return oldRules, nil
+}
@@ -0,0 +1,107 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeEnforcer(t *testing.T) (*casbin.Enforcer, *Fake) {
+	t.Helper()
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	require.NoError(t, err)
+	f := NewFake()
+	require.NoError(t, f.SavePolicy(e.GetModel()))
+
+	e, err = casbin.NewEnforcer("examples/rbac_model.conf", f)
+	require.NoError(t, err)
+	return e, f
+}
+
+func requirePolicy(t *testing.T, expected, res [][]string) {
+	t.Helper()
+	require.True(t, util.Array2DEquals(expected, res), "Policy Got: %v, supposed to be %v", res, expected)
+}
+
+func TestFake(t *testing.T) {
+	e, f := newFakeEnforcer(t)
+
+	_, err := e.AddPolicies([][]string{{"alice", "data1", "write"}, {"bob", "data1", "read"}})
+	require.NoError(t, err)
+	// Duplicates are ignored like ON CONFLICT DO NOTHING.
+	require.NoError(t, f.AddPolicy("p", "p", []string{"alice", "data1", "write"}))
+
+	_, err = e.RemoveFilteredPolicy(0, "", "data2")
+	require.NoError(t, err)
+	require.NoError(t, e.LoadPolicy())
+	requirePolicy(t, [][]string{{"alice", "data1", "read"}, {"alice", "data1", "write"}, {"bob", "data1", "read"}}, e.GetPolicy())
+
+	_, err = e.UpdatePolicy([]string{"bob", "data1", "read"}, []string{"bob", "data3", "read"})
+	require.NoError(t, err)
+	require.NoError(t, e.LoadPolicy())
+	requirePolicy(t, [][]string{{"alice", "data1", "read"}, {"alice", "data1", "write"}, {"bob", "data3", "read"}}, e.GetPolicy())
+
+	require.NoError(t, e.LoadFilteredPolicy(&Filter{P: []string{"", "", "read"}, G: []string{"alice"}}))
+	require.True(t, e.IsFiltered())
+	requirePolicy(t, [][]string{{"alice", "data1", "read"}, {"bob", "data3", "read"}}, e.GetPolicy())
+	requirePolicy(t, [][]string{{"alice", "data2_admin"}}, e.GetGroupingPolicy())
+
+	err = e.LoadFilteredPolicy(&Filter{P: []string{"", "", "", "", "", "", "x"}})
+	require.ErrorIs(t, err, ErrTooManyFields)
+
+	removed, err := f.UpdateFilteredPolicies("p", "p", [][]string{{"carol", "data1", "read"}}, 0, "alice")
+	require.NoError(t, err)
+	requirePolicy(t, [][]string{{"alice", "data1", "read"}, {"alice", "data1", "write"}}, removed)
+
+	require.Equal(t, uint64(6), f.Revision())
+}
+
+func TestFakeUpdatePolicyID(t *testing.T) {
+	e, f := newFakeEnforcer(t)
+
+	_, err := e.UpdatePolicy([]string{"alice", "data1", "read"}, []string{"alice", "data3", "read"})
+	require.NoError(t, err)
+
+	// The updated row gets the ID of its new rule, like with Adapter.
+	ptype, rule, err := f.GetPolicyByID(context.Background(), PolicyIDFor("p", []string{"alice", "data3", "read"}))
+	require.NoError(t, err)
+	require.Equal(t, "p", ptype)
+	require.Equal(t, []string{"alice", "data3", "read"}, rule)
+	_, _, err = f.GetPolicyByID(context.Background(), PolicyIDFor("p", []string{"alice", "data1", "read"}))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, f.RemovePolicy("p", "p", []string{"alice", "data3", "read"}))
+	require.NoError(t, e.LoadPolicy())
+	requirePolicy(t, [][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, e.GetPolicy())
+}
+
+func TestFakeUpdatePolicyConflict(t *testing.T) {
+	_, f := newFakeEnforcer(t)
+	before := f.Rules()
+
+	err := f.UpdatePolicies("p", "p",
+		[][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}},
+		[][]string{{"carol", "data1", "read"}, {"data2_admin", "data2", "read"}})
+	require.ErrorIs(t, err, ErrPolicyExists)
+	require.Equal(t, before, f.Rules(), "the rules are left unchanged")
+}
+
+func TestFakeRemoveFilteredPolicyReturning(t *testing.T) {
+	_, f := newFakeEnforcer(t)
+
+	removed, err := f.RemoveFilteredPolicyReturning("p", "p", 1, "data2")
+	require.NoError(t, err)
+	requirePolicy(t, [][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, removed)
+}
+
+func TestFakeAddPoliciesReturning(t *testing.T) {
+	_, f := newFakeEnforcer(t)
+
+	inserted, err := f.AddPoliciesReturning("p", "p", [][]string{{"alice", "data1", "read"}, {"carol", "data1", "read"}, {"carol", "data1", "read"}})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"carol", "data1", "read"}}, inserted)
+}
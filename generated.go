@@ -0,0 +1,89 @@
+package pgadapter
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// WithGeneratedID makes the id column of the rules table a generated column holding the MD5 hash of the rule,
+// see MD5PolicyID, so Postgres keeps the IDs consistent with the rules, e.g. when rows are inserted or updated
+// by raw SQL. The adapter computes the IDs with MD5PolicyID, WithIDFunc is ignored. The adapter works on
+// a view of the table named <table>_mapped, whose writes are forwarded to the table by triggers leaving out
+// the id. The table must have this layout if it exists already, which requires Postgres 12 or later.
+// It can't be combined with the other options working on a view, like WithColumnMapping or WithJSONBStorage.
+func WithGeneratedID() Option {
+	return func(a *Adapter) {
+		a.generatedID = true
+	}
+}
+
+// MD5PolicyID is an IDFunc returning the hex encoded MD5 hash of "ptype,v0,v1,v2,v3,v4,v5",
+// with the missing values empty. It is the ID Postgres computes with WithGeneratedID.
+func MD5PolicyID(ptype string, rule []string) string {
+	values := make([]string, 7)
+	values[0] = ptype
+	copy(values[1:], rule)
+	sum := md5.Sum([]byte(strings.Join(values, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *Adapter) checkGeneratedID() error {
+	if !a.generatedID {
+		return nil
+	}
+	if len(a.columns) > 0 || a.softDelete || a.tenantScoped || a.partitioning != nil || a.surrogateKey != 0 || a.jsonb {
+		return errors.New("WithGeneratedID can't be combined with WithColumnMapping, WithSoftDelete, " +
+			"WithTenant, WithPartitioning, WithSurrogateKey nor WithJSONBStorage")
+	}
+	a.idFunc = MD5PolicyID
+	return nil
+}
+
+// generatedIDSQL returns the statements creating the table with the generated id column and its view,
+// the table is created by the first one.
+func (v ruleView) generatedIDSQL() []string {
+	table := quoteQualified(v.table)
+	view := quoteQualified(v.name())
+	writeFunction := quoteQualified(v.table + "_write")
+
+	// The expression must be immutable, unlike concat_ws.
+	values := []string{"coalesce(ptype, '')"}
+	cols := make([]string, 0, len(ruleColumns))
+	newValues := make([]string, 0, len(ruleColumns)-1)
+	sets := make([]string, 0, len(ruleColumns)-1)
+	for _, column := range ruleColumns[1:] {
+		if column != "ptype" {
+			values = append(values, "coalesce("+column+", '')")
+		}
+		cols = append(cols, column+" text")
+		newValues = append(newValues, "NEW."+column)
+		sets = append(sets, column+" = NEW."+column)
+	}
+	id := "md5(" + strings.Join(values, " || ',' || ") + ")"
+
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + table + " (id text GENERATED ALWAYS AS (" + id + ") STORED, " +
+			strings.Join(cols, ", ") + ", PRIMARY KEY (id))",
+		"CREATE OR REPLACE VIEW " + view + " AS SELECT " + strings.Join(ruleColumns, ", ") + " FROM " + table,
+		// Returning NULL skips the row like ON CONFLICT DO NOTHING, so it isn't counted as affected.
+		"CREATE OR REPLACE FUNCTION " + writeFunction + "() RETURNS trigger AS $$\n" +
+			"BEGIN\n" +
+			"\tIF TG_OP = 'DELETE' THEN\n" +
+			"\t\tDELETE FROM " + table + " WHERE id = OLD.id;\n" +
+			"\t\tIF NOT FOUND THEN RETURN NULL; END IF;\n" +
+			"\t\tRETURN OLD;\n" +
+			"\tELSIF TG_OP = 'INSERT' THEN\n" +
+			"\t\tINSERT INTO " + table + " (" + strings.Join(ruleColumns[1:], ", ") + ") VALUES (" +
+			strings.Join(newValues, ", ") + ") ON CONFLICT DO NOTHING RETURNING id INTO NEW.id;\n" +
+			"\tELSE\n" +
+			"\t\tUPDATE " + table + " SET " + strings.Join(sets, ", ") + " WHERE id = OLD.id RETURNING id INTO NEW.id;\n" +
+			"\tEND IF;\n" +
+			"\tIF NOT FOUND THEN RETURN NULL; END IF;\n" +
+			"\tRETURN NEW;\n" +
+			"END $$ LANGUAGE plpgsql",
+		"DROP TRIGGER IF EXISTS write ON " + view,
+		"CREATE TRIGGER write INSTEAD OF INSERT OR UPDATE OR DELETE ON " + view + " FOR EACH ROW EXECUTE PROCEDURE " + writeFunction + "()",
+	}
+}
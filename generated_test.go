@@ -0,0 +1,53 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedIDSQL(t *testing.T) {
+	require.Equal(t, "67c00b9423785812f07f08f0a9eaacf6", MD5PolicyID("p", []string{"alice", "data1", "read"}))
+	require.Equal(t, MD5PolicyID("p", []string{"alice", "data1", "read"}), MD5PolicyID("p", []string{"alice", "data1", "read", ""}))
+
+	a := &Adapter{tableName: "casbin_rule"}
+	WithGeneratedID()(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "casbin_rule_mapped", a.tableName)
+	require.Equal(t, MD5PolicyID("p", []string{"alice"}), a.ruleID("p", []string{"alice"}))
+	require.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "casbin_rule" (id text GENERATED ALWAYS AS (md5(coalesce(ptype, '') || ',' || coalesce(v0, '') || ',' || `+
+			`coalesce(v1, '') || ',' || coalesce(v2, '') || ',' || coalesce(v3, '') || ',' || coalesce(v4, '') || ',' || coalesce(v5, ''))) STORED, `+
+			`ptype text, v0 text, v1 text, v2 text, v3 text, v4 text, v5 text, PRIMARY KEY (id))`,
+		a.ruleView().sql()[0])
+
+	a = &Adapter{tableName: "casbin_rule"}
+	WithGeneratedID()(a)
+	WithSoftDelete()(a)
+	require.Error(t, a.mapColumns())
+}
+
+func (s *AdapterTestSuite) TestGeneratedID() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_gen"), WithGeneratedID())
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	var id string
+	_, err = s.a.db.QueryOne(pg.Scan(&id), "SELECT id FROM casbin_rule_gen WHERE v0 = 'alice'")
+	s.Require().NoError(err)
+	s.Require().Equal(MD5PolicyID("p", []string{"alice", "data1", "read"}), id)
+
+	_, err = s.a.db.Exec("UPDATE casbin_rule_gen SET v2 = 'write' WHERE v0 = 'alice'")
+	s.Require().NoError(err)
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"alice", "data1", "write"}, []string{"alice", "data1", "delete"}))
+	_, err = s.a.db.QueryOne(pg.Scan(&id), "SELECT id FROM casbin_rule_gen WHERE v0 = 'alice'")
+	s.Require().NoError(err)
+	s.Require().Equal(MD5PolicyID("p", []string{"alice", "data1", "delete"}), id)
+
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data1", "delete"}))
+	rules, err := a.GetFilteredPolicies("p", 0, "alice")
+	s.Require().NoError(err)
+	s.Require().Empty(rules)
+}
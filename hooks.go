@@ -0,0 +1,190 @@
+package pgadapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BeforeWriteHook is called before the adapter writes rules to the database.
+// For the remove operations rules are the rules to remove, for RemoveFilteredPolicy it holds the field values,
+// for the update operations the new rules, and SavePolicy calls it once per ptype of the model.
+// Returning an error vetoes the operation and the error is returned to the caller.
+// The hook may modify the values of rules in place, the modified values are written.
+type BeforeWriteHook func(op string, sec, ptype string, rules [][]string) error
+
+// WithBeforeWrite registers a hook called before every write, e.g. to forbid wildcard subjects in production.
+// It can be used several times, the hooks are called in registration order.
+func WithBeforeWrite(hook BeforeWriteHook) Option {
+	return func(a *Adapter) {
+		a.beforeWriteHooks = append(a.beforeWriteHooks, hook)
+	}
+}
+
+// WithAllowedPtypes restricts the ptypes the adapter works with.
+// Writes of rules with any other ptype fail with ErrPtypeNotAllowed and loads skip them,
+// which protects against typos and stale sections left by old models. The skipped rules are reported
+// once per load to the error hook and the logger, the load itself succeeds.
+func WithAllowedPtypes(ptypes ...string) Option {
+	return func(a *Adapter) {
+		a.allowedPtypes = make(map[string]bool, len(ptypes))
+		for _, ptype := range ptypes {
+			a.allowedPtypes[ptype] = true
+		}
+	}
+}
+
+func (a *Adapter) ptypeAllowed(ptype string) bool {
+	return a.allowedPtypes == nil || a.allowedPtypes[ptype]
+}
+
+// skippedRules counts the rules of each ptype a load skipped, see WithAllowedPtypes.
+type skippedRules map[string]int
+
+// loadAllowed reports whether a rule of ptype is loaded, and counts it in skipped otherwise.
+func (a *Adapter) loadAllowed(ptype string, skipped skippedRules) bool {
+	if a.ptypeAllowed(ptype) {
+		return true
+	}
+	skipped[ptype]++
+	return false
+}
+
+// reportSkipped reports the rules skipped by the load op, if any, as an ErrPtypeNotAllowed error.
+func (a *Adapter) reportSkipped(op string, skipped skippedRules) {
+	if len(skipped) == 0 {
+		return
+	}
+	ptypes := make([]string, 0, len(skipped))
+	count := 0
+	for ptype, n := range skipped {
+		ptypes = append(ptypes, ptype)
+		count += n
+	}
+	sort.Strings(ptypes)
+	err := fmt.Errorf("%w: %d rules of the ptypes %s skipped", ErrPtypeNotAllowed, count, strings.Join(ptypes, ", "))
+	a.handleError(op, "", count, &err)
+}
+
+// ptypeSec returns the section of the rules of ptype, "p" or "g", the rules read from files or dumps
+// may have any ptype.
+func ptypeSec(ptype string) (string, error) {
+	if ptype == "" || (ptype[0] != 'p' && ptype[0] != 'g') {
+		return "", fmt.Errorf("%w: invalid ptype %q, it must start with p or g", ErrInvalidRule, ptype)
+	}
+	return ptype[:1], nil
+}
+
+func (a *Adapter) beforeWrite(op string, sec, ptype string, rules [][]string) error {
+	if !a.ptypeAllowed(ptype) {
+		return fmt.Errorf("%w: %q", ErrPtypeNotAllowed, ptype)
+	}
+	for _, hook := range a.beforeWriteHooks {
+		if err := hook(op, sec, ptype, rules); err != nil {
+			return err
+		}
+	}
+	if removeOps[op] {
+		return nil
+	}
+	for _, rule := range rules {
+		for _, validate := range a.validators {
+			if err := validate(ptype, rule); err != nil {
+				return &RuleError{Ptype: ptype, Rule: rule, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// RuleValidator checks a rule of ptype before it is written, returning an error rejects the write.
+type RuleValidator func(ptype string, rule []string) error
+
+// WithRuleValidator registers a validator called for every rule inserted or written by an update, after the
+// BeforeWriteHooks, e.g. to reject empty subjects, enforce the format of domains or cap the arity of a ptype.
+// The write fails with a *RuleError carrying the rejected rule, which errors.Is matches with ErrInvalidRule.
+// It can be used several times, the validators are called in registration order.
+func WithRuleValidator(validator RuleValidator) Option {
+	return func(a *Adapter) {
+		a.validators = append(a.validators, validator)
+	}
+}
+
+// RuleError is the error of a rule rejected by a RuleValidator.
+type RuleError struct {
+	Ptype string
+	Rule  []string
+	// Err is the error returned by the validator.
+	Err error
+}
+
+func (e *RuleError) Error() string {
+	return ErrInvalidRule.Error() + " " + strings.Join(append([]string{e.Ptype}, e.Rule...), ", ") + ": " + e.Err.Error()
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrInvalidRule.
+func (e *RuleError) Is(target error) bool {
+	return target == ErrInvalidRule
+}
+
+// removeOps are the operations removing the rules passed to the BeforeWriteHooks, their rules aren't validated.
+var removeOps = map[string]bool{OpRemovePolicy: true, OpRemovePolicies: true, OpRemoveFilteredPolicy: true}
+
+// Hooks receives the operations of the adapter, see WithHooks. The Operation carries the ptype and the rules,
+// and its Result and Rows once the operation ran.
+type Hooks interface {
+	// OnBeforeWrite is called before a write, returning an error vetoes it and the error is returned to the caller.
+	OnBeforeWrite(op *Operation) error
+	// OnAfterWrite is called after a write with its error, e.g. to invalidate caches.
+	OnAfterWrite(op *Operation, err error)
+	// OnAfterLoad is called after a load with its error.
+	OnAfterLoad(op *Operation, err error)
+}
+
+// writeOps are the operations changing the stored rules, see Hooks.
+var writeOps = map[string]bool{
+	OpSavePolicy: true, OpAddPolicy: true, OpAddPolicies: true, OpRemovePolicy: true, OpRemovePolicies: true,
+	OpRemoveFilteredPolicy: true, OpUpdatePolicy: true, OpUpdatePolicies: true, OpUpdateFilteredPolicies: true,
+	OpPromotePolicies: true, OpReorderPolicies: true, OpSetPolicyLabels: true, OpSetBundleEnabled: true,
+	OpImportPolicies: true, OpApprove: true, OpRepairPolicyIDs: true, OpSyncPolicy: true, OpPurgeDeleted: true,
+	OpLoadSnapshot: true, OpRestorePolicyAt: true, OpRestorePolicy: true,
+}
+
+// loadOps are the operations loading rules into a model, see Hooks.
+var loadOps = map[string]bool{
+	OpLoadPolicy: true, OpLoadFilteredPolicy: true, OpLoadIncrementalPolicy: true, OpLoadPolicyAt: true,
+}
+
+// WithHooks registers hooks called around the writes and after the loads of the adapter, e.g. for custom auditing,
+// cache invalidation or metrics. It adds a middleware to the chain, see Use, the other operations pass through.
+// It can be used several times.
+func WithHooks(hooks Hooks) Option {
+	return func(a *Adapter) {
+		a.middleware = append(a.middleware, hooksMiddleware(hooks))
+	}
+}
+
+func hooksMiddleware(hooks Hooks) Middleware {
+	return func(next Handler) Handler {
+		return func(op *Operation) error {
+			switch {
+			case writeOps[op.Name]:
+				if err := hooks.OnBeforeWrite(op); err != nil {
+					return err
+				}
+				err := next(op)
+				hooks.OnAfterWrite(op, err)
+				return err
+			case loadOps[op.Name]:
+				err := next(op)
+				hooks.OnAfterLoad(op, err)
+				return err
+			}
+			return next(op)
+		}
+	}
+}
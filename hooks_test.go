@@ -0,0 +1,163 @@
+package pgadapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var errWildcard = errors.New("wildcard subjects are forbidden")
+
+func forbidWildcards(op string, sec, ptype string, rules [][]string) error {
+	for _, rule := range rules {
+		if len(rule) > 0 && rule[0] == "*" {
+			return errWildcard
+		}
+	}
+	return nil
+}
+
+func TestBeforeWriteVeto(t *testing.T) {
+	a := &Adapter{}
+	WithBeforeWrite(forbidWildcards)(a)
+
+	err := a.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"*", "data1", "read"}})
+	require.ErrorIs(t, err, errWildcard)
+}
+
+func (s *AdapterTestSuite) TestBeforeWriteMutate() {
+	lowercase := func(op string, sec, ptype string, rules [][]string) error {
+		for _, rule := range rules {
+			for i := range rule {
+				rule[i] = strings.ToLower(rule[i])
+			}
+		}
+		return nil
+	}
+	a, err := NewAdapterByDB(s.a.db, WithBeforeWrite(forbidWildcards), WithBeforeWrite(lowercase))
+	s.Require().NoError(err)
+
+	err = a.AddPolicy("p", "p", []string{"Carol", "Data3", "READ"})
+	s.Require().NoError(err)
+	err = a.AddPolicy("p", "p", []string{"*", "data3", "read"})
+	s.Require().ErrorIs(err, errWildcard)
+
+	err = s.e.LoadPolicy()
+	s.Require().NoError(err)
+	s.Require().True(s.e.HasPolicy("carol", "data3", "read"))
+	s.Require().False(s.e.HasPolicy("*", "data3", "read"))
+}
+
+func TestAllowedPtypes(t *testing.T) {
+	a := &Adapter{}
+	WithAllowedPtypes("p", "g")(a)
+
+	err := a.AddPolicy("p", "p2", []string{"alice", "data1", "read"})
+	require.ErrorIs(t, err, ErrPtypeNotAllowed)
+	require.Contains(t, err.Error(), `"p2"`)
+}
+
+func TestReportSkipped(t *testing.T) {
+	var reported []error
+	a := &Adapter{}
+	WithAllowedPtypes("p", "g")(a)
+	WithErrorHook(func(op string, err error, meta map[string]interface{}) {
+		require.Equal(t, OpLoadPolicy, op)
+		require.Equal(t, 3, meta["rule_count"])
+		reported = append(reported, err)
+	})(a)
+
+	skipped := skippedRules{}
+	for _, ptype := range []string{"p", "p2", "g", "g2", "p2"} {
+		a.loadAllowed(ptype, skipped)
+	}
+	a.reportSkipped(OpLoadPolicy, skipped)
+	require.Len(t, reported, 1)
+	require.ErrorIs(t, reported[0], ErrPtypeNotAllowed)
+	require.Contains(t, reported[0].Error(), "3 rules of the ptypes g2, p2 skipped")
+
+	a.reportSkipped(OpLoadPolicy, skippedRules{})
+	require.Len(t, reported, 1, "nothing is reported without skipped rules")
+}
+
+func (s *AdapterTestSuite) TestAllowedPtypesLoad() {
+	err := s.a.AddPolicy("p", "p2", []string{"alice", "data1", "read"})
+	s.Require().NoError(err)
+
+	var reported []string
+	a, err := NewAdapterByDB(s.a.db, WithAllowedPtypes("p", "g"), WithErrorHook(func(op string, err error, _ map[string]interface{}) {
+		s.Require().ErrorIs(err, ErrPtypeNotAllowed)
+		reported = append(reported, op)
+	}))
+	s.Require().NoError(err)
+	// The model has no p2 section, loading the rule would fail without the allow-list.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.assertPolicy(
+		[][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}},
+		e.GetPolicy(),
+	)
+	s.Require().Equal([]string{OpLoadPolicy}, reported, "the skipped rule is reported once")
+}
+
+type recordingHooks struct {
+	veto   error
+	events []string
+}
+
+func (h *recordingHooks) OnBeforeWrite(op *Operation) error {
+	h.events = append(h.events, "before "+op.Name+" "+op.Ptype)
+	return h.veto
+}
+
+func (h *recordingHooks) OnAfterWrite(op *Operation, err error) {
+	h.events = append(h.events, fmt.Sprintf("after %s %v", op.Name, err))
+}
+
+func (h *recordingHooks) OnAfterLoad(op *Operation, err error) {
+	h.events = append(h.events, fmt.Sprintf("load %s %d %v", op.Name, op.Rows, err))
+}
+
+func TestHooks(t *testing.T) {
+	hooks := &recordingHooks{}
+	handler := hooksMiddleware(hooks)(func(op *Operation) error {
+		if op.Name == OpLoadPolicy {
+			op.Rows = 4
+		}
+		return nil
+	})
+	require.NoError(t, handler(&Operation{Name: OpAddPolicy, Ptype: "p"}))
+	require.NoError(t, handler(&Operation{Name: OpLoadPolicy}))
+	require.NoError(t, handler(&Operation{Name: OpPing}))
+	require.Equal(t, []string{"before AddPolicy p", "after AddPolicy <nil>", "load LoadPolicy 4 <nil>"}, hooks.events)
+
+	a := &Adapter{}
+	WithHooks(&recordingHooks{veto: errWildcard})(a)
+	require.ErrorIs(t, a.AddPolicy("p", "p", []string{"*", "data1", "read"}), errWildcard)
+}
+
+func TestRuleValidator(t *testing.T) {
+	nonEmptySubject := func(ptype string, rule []string) error {
+		if len(rule) == 0 || rule[0] == "" {
+			return errors.New("empty subject")
+		}
+		return nil
+	}
+	a := &Adapter{}
+	WithRuleValidator(nonEmptySubject)(a)
+
+	err := a.AddPolicy("p", "p", []string{"", "data1", "read"})
+	require.ErrorIs(t, err, ErrInvalidRule)
+	var ruleErr *RuleError
+	require.ErrorAs(t, err, &ruleErr)
+	require.Equal(t, []string{"", "data1", "read"}, ruleErr.Rule)
+	require.EqualError(t, ruleErr, "invalid rule p, , data1, read: empty subject")
+
+	err = a.UpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"", "data1", "read"})
+	require.ErrorIs(t, err, ErrInvalidRule)
+	require.NoError(t, a.beforeWrite(OpRemovePolicy, "p", "p", [][]string{{"", "data1", "read"}}), "removals aren't validated")
+}
@@ -0,0 +1,104 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Analyze updates the planner statistics of the rules table, e.g. after a large import.
+func (a *Adapter) Analyze(ctx context.Context) (err error) {
+	defer a.handleError(OpAnalyze, "", 0, &err)
+
+	_, err = a.conn().ExecContext(ctx, "ANALYZE ?", pg.Ident(a.tableName))
+	return err
+}
+
+// Reindex rebuilds the indexes of the rules table. It locks the table against writes while running.
+func (a *Adapter) Reindex(ctx context.Context) (err error) {
+	defer a.handleError(OpReindex, "", 0, &err)
+
+	_, err = a.conn().ExecContext(ctx, "REINDEX TABLE ?", pg.Ident(a.tableName))
+	return err
+}
+
+// Vacuum reclaims the space of the rows deleted from the rules table, e.g. by SavePolicy, and updates its statistics.
+// A full vacuum rewrites the table and returns the space to the operating system,
+// but locks the table against reads and writes while running.
+// Vacuum can't run within a transaction, so it always uses the connection pool, even with WithTx.
+func (a *Adapter) Vacuum(ctx context.Context, full bool) (err error) {
+	defer a.handleError(OpVacuum, "", 0, &err)
+
+	query := "VACUUM (ANALYZE) ?"
+	if full {
+		query = "VACUUM (FULL, ANALYZE) ?"
+	}
+	if a.db == nil {
+		return errNoPool
+	}
+	_, err = a.db.ExecContext(ctx, query, pg.Ident(a.tableName))
+	return err
+}
+
+// RepairPolicyIDs recomputes the IDs of the rows whose ID doesn't match their rule, e.g. the rows changed by
+// UpdatePolicy before it updated the IDs, which RemovePolicy can't find, and returns the number of repaired rows.
+// The IDs are computed from the rules as loaded by LoadPolicy, without trailing empty values.
+// Rows duplicating the rule of another row are deleted.
+func (a *Adapter) RepairPolicyIDs(ctx context.Context) (_ int, err error) {
+	defer a.handleError(OpRepairPolicyIDs, "", 0, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	var repaired int
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		var lines []*CasbinRule
+		if err := tx.ModelContext(ctx, &lines).Table(a.tableName).Order("id").For("UPDATE").Select(); err != nil {
+			return err
+		}
+
+		ids := make(map[string]bool, len(lines))
+		var broken []*CasbinRule
+		for _, line := range lines {
+			if a.ruleID(line.Ptype, line.rule()) == line.ID {
+				ids[line.ID] = true
+			} else {
+				broken = append(broken, line)
+			}
+		}
+		repaired = len(broken)
+		if repaired == 0 {
+			return nil
+		}
+
+		// The broken rows are moved out of the way first, a broken row may hold the ID of another.
+		brokenIDs := make([]string, 0, len(broken))
+		for _, line := range broken {
+			brokenIDs = append(brokenIDs, line.ID)
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE ? SET id = 'repair:' || id WHERE id IN (?)",
+			pg.Ident(a.tableName), pg.In(brokenIDs))
+		if err != nil {
+			return err
+		}
+
+		for _, line := range broken {
+			id := a.ruleID(line.Ptype, line.rule())
+			if ids[id] {
+				_, err = tx.ExecContext(ctx, "DELETE FROM ? WHERE id = ?", pg.Ident(a.tableName), "repair:"+line.ID)
+			} else {
+				_, err = tx.ExecContext(ctx, "UPDATE ? SET id = ? WHERE id = ?", pg.Ident(a.tableName), id, "repair:"+line.ID)
+			}
+			if err != nil {
+				return err
+			}
+			ids[id] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return repaired, nil
+}
@@ -0,0 +1,36 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestHousekeeping() {
+	ctx := context.Background()
+	s.Require().NoError(s.a.Analyze(ctx))
+	s.Require().NoError(s.a.Reindex(ctx))
+	s.Require().NoError(s.a.Vacuum(ctx, false))
+	s.Require().NoError(s.a.Vacuum(ctx, true))
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().Len(s.e.GetPolicy(), 4)
+}
+
+func (s *AdapterTestSuite) TestRepairPolicyIDs() {
+	ctx := context.Background()
+	_, err := s.a.db.Exec("UPDATE casbin_rule SET v0 = 'carol' WHERE v0 = 'alice'")
+	s.Require().NoError(err)
+	_, err = s.a.db.Exec("UPDATE casbin_rule SET v0 = 'data2_admin' WHERE v0 = 'bob'")
+	s.Require().NoError(err)
+
+	n, err := s.a.RepairPolicyIDs(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(3, n, "the two carol rules and the duplicate of data2_admin's rule")
+
+	s.Require().NoError(s.a.RemovePolicyCtx(ctx, "p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy([][]string{{"data2_admin", "data2", "write"}, {"data2_admin", "data2", "read"}}, s.e.GetPolicy())
+
+	n, err = s.a.RepairPolicyIDs(ctx)
+	s.Require().NoError(err)
+	s.Require().Zero(n)
+}
@@ -0,0 +1,34 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDFunc(t *testing.T) {
+	rule := []string{"alice", "data1", "read"}
+	require.Equal(t, "c52f59939156265f2c189c4909d41dcca3aa8acffa950416465716c7777c0ded", SHA256PolicyID("p", rule))
+
+	a := &Adapter{}
+	require.Equal(t, policyID("p", rule), a.ruleLine("p", rule).ID)
+	WithIDFunc(SHA256PolicyID)(a)
+	require.Equal(t, SHA256PolicyID("p", rule), a.ruleLine("p", rule).ID)
+	require.Equal(t, SHA256PolicyID("p", rule), a.sibling().ruleID("p", rule))
+}
+
+func (s *AdapterTestSuite) TestWithIDFunc() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_sha256"), WithIDFunc(SHA256PolicyID))
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	var id string
+	_, err = s.a.db.QueryOne(pg.Scan(&id), "SELECT id FROM casbin_rule_sha256")
+	s.Require().NoError(err)
+	s.Require().Equal(SHA256PolicyID("p", []string{"alice", "data1", "read"}), id)
+
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+	_, err = s.a.db.QueryOne(pg.Scan(&id), "SELECT id FROM casbin_rule_sha256")
+	s.Require().ErrorIs(err, pg.ErrNoRows)
+}
@@ -0,0 +1,55 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// GetImplicitPermissionsSQL returns the p rules of user and of the roles it has directly or through other roles,
+// like the GetImplicitPermissionsForUser of the enforcer, but resolving the g hierarchy with a recursive query
+// in Postgres instead of loading the whole policy. With a domain, only the g rules and p rules of domain are used,
+// the domain being the third value of the g rules and the second one of the p rules.
+// Loops in the hierarchy are harmless. The rules are in canonical order and the disabled bundles are skipped.
+func (a *Adapter) GetImplicitPermissionsSQL(user, domain string) ([][]string, error) {
+	return a.GetImplicitPermissionsSQLCtx(context.Background(), user, domain)
+}
+
+// GetImplicitPermissionsSQLCtx is like GetImplicitPermissionsSQL, ctx bounds the database calls.
+func (a *Adapter) GetImplicitPermissionsSQLCtx(ctx context.Context, user, domain string) (_ [][]string, err error) {
+	defer a.handleError(OpGetImplicitPermissions, "p", 0, &err)
+
+	folded := a.fold([]string{user, domain})
+	user, domain = folded[0], folded[1]
+
+	// UNION drops the roles already found, which ends the recursion on loops.
+	roles := "WITH RECURSIVE roles (name) AS (SELECT ?::text UNION " +
+		"SELECT g.v1 FROM ? g JOIN roles ON g.v0 = roles.name WHERE g.ptype = 'g'"
+	args := []interface{}{user, pg.Ident(a.tableName)}
+	if domain != "" {
+		roles += " AND g.v2 = ?"
+		args = append(args, domain)
+	}
+	if a.bundles {
+		roles += " AND (g.bundle IS NULL OR g.bundle IN (SELECT name FROM ? WHERE rule_table = ? AND enabled))"
+		args = append(args, pg.Ident(DefaultBundleTableName), a.tableName)
+	}
+	roles += ") SELECT name FROM roles"
+
+	var lines []*CasbinRule
+	query := a.enabledBundles(a.conn().ModelContext(ctx, &lines).Table(a.tableName)).
+		Where("ptype = 'p'").
+		Where("v0 IN ("+roles+")", args...)
+	if domain != "" {
+		query = query.Where("v1 = ?", domain)
+	}
+	if err := query.OrderExpr(canonicalOrder).Select(); err != nil {
+		return nil, err
+	}
+
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.rule())
+	}
+	return rules, nil
+}
@@ -0,0 +1,30 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestGetImplicitPermissionsSQL() {
+	// A loop in the hierarchy must not make the query recurse forever.
+	s.Require().NoError(s.a.AddPolicy("g", "g", []string{"data2_admin", "alice"}))
+
+	rules, err := s.a.GetImplicitPermissionsSQL("alice", "")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"alice", "data1", "read"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, rules)
+
+	rules, err = s.a.GetImplicitPermissionsSQL("bob", "")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"bob", "data2", "write"}}, rules)
+
+	a, err := s.a.WithTable("casbin_rule_domains")
+	s.Require().NoError(err)
+	err = SeedPoliciesFromFile(context.Background(), s.a.db, "examples/rbac_with_domains_policy.csv", WithTableName("casbin_rule_domains"))
+	s.Require().NoError(err)
+
+	rules, err = a.GetImplicitPermissionsSQL("alice", "domain1")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"admin", "domain1", "data1", "read"}, {"admin", "domain1", "data1", "write"}}, rules)
+	rules, err = a.GetImplicitPermissionsSQL("alice", "domain2")
+	s.Require().NoError(err)
+	s.Require().Empty(rules)
+}
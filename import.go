@@ -0,0 +1,208 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// ImportOptions controls ImportPolicies.
+type ImportOptions struct {
+	// Arity maps a ptype to the number of values its rules must have,
+	// the rules of the other ptypes may have 1 to 6 values.
+	Arity map[string]int
+	// Normalize is applied to every value after the surrounding white space has been trimmed,
+	// e.g. strings.ToLower. Trailing empty values are dropped after normalization.
+	Normalize func(string) string
+	// DryRun only validates the rules and reports what would be imported.
+	DryRun bool
+	// SkipInvalid imports the valid rules even if some rules are invalid,
+	// by default nothing is written when a rule is invalid.
+	SkipInvalid bool
+}
+
+// ImportIssue describes a rule of the batch that is not imported.
+type ImportIssue struct {
+	Ptype string
+	// Index is the position of the rule in the batch for its ptype.
+	Index int
+	// Rule holds the normalized values.
+	Rule []string
+	Err  error
+}
+
+// ImportReport describes the outcome of ImportPolicies, each rule starts with its ptype.
+type ImportReport struct {
+	// Imported holds the rules that are, or would be with DryRun, written.
+	Imported [][]string
+	// Existing holds the rules that are already stored.
+	Existing [][]string
+	// Duplicates holds the rules repeating an earlier rule of the batch.
+	Duplicates []ImportIssue
+	// Invalid holds the rules failing the validation.
+	Invalid []ImportIssue
+	// Applied is true if the rules have been written.
+	Applied bool
+}
+
+// ImportPolicies validates and normalizes rules, keyed by ptype, and adds the new ones in a single transaction,
+// e.g. for bulk uploads from an admin screen. Rules are invalid if their arity doesn't match opts.Arity,
+// if their ptype is not allowed or if a before write hook rejects them. The report lists the rules
+// already stored and the duplicates within the batch, which are not written either.
+// With opts.DryRun the report can be shown before applying the import. The new rules are published
+// as a single ImportPolicies change.
+func (a *Adapter) ImportPolicies(ctx context.Context, rules map[string][][]string, opts ImportOptions) (*ImportReport, error) {
+	var report *ImportReport
+	err := a.doContext(ctx, &Operation{Name: OpImportPolicies}, func(ctx context.Context) error {
+		var err error
+		report, err = a.importPolicies(ctx, rules, opts)
+		return err
+	})
+	return report, err
+}
+
+func (a *Adapter) importPolicies(ctx context.Context, rules map[string][][]string, opts ImportOptions) (_ *ImportReport, err error) {
+	defer a.handleError(OpImportPolicies, "", 0, &err)
+
+	report, lines := a.checkImport(rules, opts)
+	if opts.DryRun || len(report.Invalid) > 0 && !opts.SkipInvalid {
+		if err := a.splitExisting(ctx, a.conn(), report, lines); err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+
+	if err := a.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	change := PolicyChange{Operation: OpImportPolicies}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := a.splitExisting(ctx, tx, report, lines); err != nil {
+			return err
+		}
+		added := make([]*CasbinRule, 0, len(report.Imported))
+		for _, rule := range report.Imported {
+			added = append(added, a.ruleLine(rule[0], rule[1:]))
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		if _, err := tx.Model(&added).Table(a.tableName).OnConflict("DO NOTHING").Insert(); err != nil {
+			return err
+		}
+		if err := a.checkQuota(tx, added); err != nil {
+			return err
+		}
+		change.Rules = report.Imported
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Applied = true
+	if len(change.Rules) > 0 {
+		a.changed(ctx, change)
+	}
+	return report, nil
+}
+
+// checkImport normalizes and validates rules and returns the report without the stored rules
+// along with the lines of the valid, unique rules.
+func (a *Adapter) checkImport(rules map[string][][]string, opts ImportOptions) (*ImportReport, []*CasbinRule) {
+	ptypes := make([]string, 0, len(rules))
+	for ptype := range rules {
+		ptypes = append(ptypes, ptype)
+	}
+	sort.Strings(ptypes)
+
+	report := &ImportReport{}
+	var lines []*CasbinRule
+	seen := make(map[string]bool)
+	for _, ptype := range ptypes {
+		for i, rule := range rules[ptype] {
+			rule = normalizeRule(rule, opts.Normalize)
+			if err := a.checkImportRule(ptype, rule, opts.Arity); err != nil {
+				report.Invalid = append(report.Invalid, ImportIssue{Ptype: ptype, Index: i, Rule: rule, Err: err})
+				continue
+			}
+			line := a.ruleLine(ptype, rule)
+			if seen[line.ID] {
+				report.Duplicates = append(report.Duplicates, ImportIssue{
+					Ptype: ptype, Index: i, Rule: rule, Err: fmt.Errorf("%w: repeated in the batch", ErrPolicyExists),
+				})
+				continue
+			}
+			seen[line.ID] = true
+			lines = append(lines, line)
+		}
+	}
+	return report, lines
+}
+
+func (a *Adapter) checkImportRule(ptype string, rule []string, arity map[string]int) error {
+	if ptype == "" {
+		return fmt.Errorf("empty ptype")
+	}
+	if len(rule) == 0 {
+		return fmt.Errorf("empty rule")
+	}
+	if len(rule) > 6 {
+		return ErrTooManyFields
+	}
+	if n, ok := arity[ptype]; ok && len(rule) != n {
+		return fmt.Errorf("%s rules need %d values, got %d", ptype, n, len(rule))
+	}
+	// The hooks may modify the values, they are called with a copy.
+	return a.beforeWrite(OpImportPolicies, ptype[:1], ptype, [][]string{append([]string(nil), rule...)})
+}
+
+// normalizeRule trims and normalizes the values of rule and drops the trailing empty ones.
+func normalizeRule(rule []string, normalize func(string) string) []string {
+	values := make([]string, len(rule))
+	for i, v := range rule {
+		v = strings.TrimSpace(v)
+		if normalize != nil {
+			v = normalize(v)
+		}
+		values[i] = v
+	}
+	for len(values) > 0 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+
+// splitExisting sorts lines into the Existing and Imported rules of report.
+func (a *Adapter) splitExisting(ctx context.Context, db orm.DB, report *ImportReport, lines []*CasbinRule) error {
+	stored := make(map[string]bool)
+	if len(lines) > 0 {
+		ids := make([]string, 0, len(lines))
+		for _, line := range lines {
+			ids = append(ids, line.ID)
+		}
+		var existing []string
+		_, err := db.QueryContext(ctx, &existing, "SELECT id FROM ? WHERE id IN (?)", pg.Ident(a.tableName), pg.In(ids))
+		if err != nil {
+			return err
+		}
+		for _, id := range existing {
+			stored[id] = true
+		}
+	}
+
+	report.Imported, report.Existing = nil, nil
+	for _, line := range lines {
+		if stored[line.ID] {
+			report.Existing = append(report.Existing, line.ptypeRule())
+		} else {
+			report.Imported = append(report.Imported, line.ptypeRule())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckImport(t *testing.T) {
+	a := &Adapter{
+		beforeWriteHooks: []BeforeWriteHook{func(op string, sec, ptype string, rules [][]string) error {
+			if rules[0][0] == "*" {
+				return errors.New("wildcard subject")
+			}
+			return nil
+		}},
+	}
+
+	report, lines := a.checkImport(map[string][][]string{
+		"p": {
+			{" Alice ", "data1", "read"},
+			{"alice", "data1", "read", ""},
+			{"bob", "data2"},
+			{"*", "data2", "read"},
+		},
+		"g": {{"alice", "admin"}, {}},
+	}, ImportOptions{Arity: map[string]int{"p": 3}, Normalize: strings.ToLower})
+
+	require.Len(t, lines, 2)
+	require.Equal(t, "g", lines[0].Ptype)
+	require.Equal(t, []string{"alice", "data1", "read"}, lines[1].rule())
+
+	require.Len(t, report.Duplicates, 1)
+	require.Equal(t, 1, report.Duplicates[0].Index)
+	require.True(t, errors.Is(report.Duplicates[0].Err, ErrPolicyExists))
+
+	require.Len(t, report.Invalid, 3)
+	require.Equal(t, "g", report.Invalid[0].Ptype)
+	require.Equal(t, []string{"bob", "data2"}, report.Invalid[1].Rule)
+	require.EqualError(t, report.Invalid[2].Err, "wildcard subject")
+}
+
+func (s *AdapterTestSuite) TestImportPolicies() {
+	rules := map[string][][]string{
+		"p": {{"alice", "data1", "read"}, {"carol", "data1", "read"}, {"carol", "data1", "read"}, {"dave"}},
+	}
+
+	report, err := s.a.ImportPolicies(context.Background(), rules, ImportOptions{Arity: map[string]int{"p": 3}})
+	s.Require().NoError(err)
+	s.Require().False(report.Applied, "nothing is written while a rule is invalid")
+	s.Require().Equal([][]string{{"p", "carol", "data1", "read"}}, report.Imported)
+	s.Require().Equal([][]string{{"p", "alice", "data1", "read"}}, report.Existing)
+	s.Require().Len(report.Duplicates, 1)
+	s.Require().Len(report.Invalid, 1)
+
+	report, err = s.a.ImportPolicies(context.Background(), rules, ImportOptions{Arity: map[string]int{"p": 3}, SkipInvalid: true})
+	s.Require().NoError(err)
+	s.Require().True(report.Applied)
+
+	ok, err := s.a.HasPolicy(context.Background(), "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok)
+}
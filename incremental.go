@@ -0,0 +1,101 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/casbin/casbin/v2/model"
+)
+
+// IncrementalLoad is the result of LoadIncrementalPolicy.
+type IncrementalLoad struct {
+	// Watermark is the time of the last change loaded, or since if there is none,
+	// to be passed to the next call.
+	Watermark time.Time
+	// Changes is the number of changes applied to the model.
+	Changes int
+	// Reloaded reports whether the whole policy was reloaded, because a change like SavePolicy
+	// couldn't be applied incrementally.
+	Reloaded bool
+}
+
+// LoadIncrementalPolicy applies to model the rules added, updated and removed after since, which is usually the
+// Watermark of the previous call, instead of reloading the whole policy. The changes are read from the
+// operation log, so it requires WithOperationLog and only sees the changes made by adapters logging them.
+// The enforcer's role links must be rebuilt with BuildRoleLinks when grouping rules changed.
+// Entries are stamped with the start time of their transaction, so a write committing after a later started one
+// can be missed if since is exactly the last watermark; applying a change twice is harmless, so callers
+// with concurrent writers can pass a watermark moved back by the duration of their longest write.
+func (a *Adapter) LoadIncrementalPolicy(ctx context.Context, model model.Model, since time.Time) (*IncrementalLoad, error) {
+	var load *IncrementalLoad
+	err := a.doContext(ctx, &Operation{Name: OpLoadIncrementalPolicy}, func(ctx context.Context) error {
+		var err error
+		load, err = a.loadIncrementalPolicy(ctx, model, since)
+		return err
+	})
+	return load, err
+}
+
+func (a *Adapter) loadIncrementalPolicy(ctx context.Context, model model.Model, since time.Time) (_ *IncrementalLoad, err error) {
+	defer a.handleError(OpLoadIncrementalPolicy, "", 0, &err)
+
+	if !a.operationLog {
+		return nil, errors.New("the operation log is not enabled, see WithOperationLog")
+	}
+
+	var ops []LoggedOperation
+	err = a.conn().ModelContext(ctx, &ops).
+		Where("rule_table = ?", a.tableName).
+		Where("created_at > ?", since).
+		Order("id").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	load := &IncrementalLoad{Watermark: since}
+	for _, op := range ops {
+		if op.CreatedAt.After(load.Watermark) {
+			load.Watermark = op.CreatedAt
+		}
+		if load.Reloaded || (op.Change.Ptype != "" && !a.ptypeAllowed(op.Change.Ptype)) {
+			continue
+		}
+		if !applyChange(model, op.Change) {
+			// The reload sees the later changes as well.
+			model.ClearPolicy()
+			if err := a.loadPolicy(ctx, model); err != nil {
+				return nil, err
+			}
+			load.Reloaded = true
+		}
+		load.Changes++
+	}
+	return load, nil
+}
+
+// applyChange applies change to model like replayChange, so that applying it twice has no effect.
+// It returns false if the change can't be applied incrementally.
+func applyChange(model model.Model, change PolicyChange) bool {
+	if _, ok := model[change.Sec][change.Ptype]; !ok && change.Ptype != "" {
+		// The model doesn't define the ptype, like persist.LoadPolicyLine ignores such rules.
+		return true
+	}
+	switch change.Operation {
+	case OpAddPolicy, OpAddPolicies:
+		model.AddPoliciesWithAffected(change.Sec, change.Ptype, change.Rules)
+	case OpRemovePolicy, OpRemovePolicies:
+		model.RemovePoliciesWithAffected(change.Sec, change.Ptype, change.Rules)
+	case OpRemoveFilteredPolicy:
+		model.RemovePoliciesWithAffected(change.Sec, change.Ptype, change.Removed)
+	case OpUpdatePolicy, OpUpdatePolicies, OpUpdateFilteredPolicies:
+		model.RemovePoliciesWithAffected(change.Sec, change.Ptype, change.OldRules)
+		model.AddPoliciesWithAffected(change.Sec, change.Ptype, change.Rules)
+	case OpSetPolicyLabels:
+		// Labels don't change the policy.
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyChange(t *testing.T) {
+	m, err := model.NewModelFromFile("examples/rbac_model.conf")
+	require.NoError(t, err)
+
+	add := PolicyChange{Operation: OpAddPolicies, Sec: "p", Ptype: "p", Rules: [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}}
+	require.True(t, applyChange(m, add))
+	require.True(t, applyChange(m, add), "applying a change twice has no effect")
+	require.Equal(t, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}, m.GetPolicy("p", "p"))
+
+	update := PolicyChange{Operation: OpUpdatePolicy, Sec: "p", Ptype: "p", OldRules: [][]string{{"bob", "data2", "write"}}, Rules: [][]string{{"bob", "data3", "write"}}}
+	require.True(t, applyChange(m, update))
+	require.True(t, applyChange(m, PolicyChange{Operation: OpRemoveFilteredPolicy, Sec: "p", Ptype: "p", Removed: [][]string{{"alice", "data1", "read"}}}))
+	require.Equal(t, [][]string{{"bob", "data3", "write"}}, m.GetPolicy("p", "p"))
+
+	require.True(t, applyChange(m, PolicyChange{Operation: OpAddPolicy, Sec: "p", Ptype: "p2", Rules: [][]string{{"carol"}}}))
+	require.False(t, applyChange(m, PolicyChange{Operation: OpSavePolicy}))
+}
+
+func (s *AdapterTestSuite) TestLoadIncrementalPolicy() {
+	ctx := context.Background()
+	a, err := NewAdapterByDB(s.a.db, WithOperationLog())
+	s.Require().NoError(err)
+
+	m, err := model.NewModelFromFile("examples/rbac_model.conf")
+	s.Require().NoError(err)
+	s.Require().NoError(a.LoadPolicy(m))
+
+	load, err := a.LoadIncrementalPolicy(ctx, m, time.Now().Add(-time.Minute))
+	s.Require().NoError(err)
+	s.Require().Zero(load.Changes)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+	load, err = a.LoadIncrementalPolicy(ctx, m, load.Watermark)
+	s.Require().NoError(err)
+	s.Require().Equal(2, load.Changes)
+	s.Require().False(load.Reloaded)
+	s.Require().ElementsMatch([][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data1", "read"}}, m.GetPolicy("p", "p"))
+
+	watermark := load.Watermark
+	load, err = a.LoadIncrementalPolicy(ctx, m, watermark)
+	s.Require().NoError(err)
+	s.Require().Zero(load.Changes)
+	s.Require().Equal(watermark, load.Watermark)
+}
@@ -0,0 +1,45 @@
+package pgadapter
+
+import (
+	"strings"
+)
+
+// SkipDefaultIndexes skips the creation of the default indexes on (ptype) and (ptype, v0, v1) of the rules table,
+// e.g. when the table is indexed differently. Creating the indexes on a large existing table blocks the writes
+// to it for a while, create them concurrently beforehand or skip them in this case.
+func SkipDefaultIndexes() Option {
+	return func(a *Adapter) {
+		a.skipDefaultIndexes = true
+	}
+}
+
+// defaultIndexes are the columns of the default indexes of the rules table, they serve the ptype and
+// leading value predicates of LoadFilteredPolicy and RemoveFilteredPolicy.
+var defaultIndexes = [][]string{{"ptype"}, {"ptype", "v0", "v1"}}
+
+// defaultIndexesSQL returns the statements creating the default indexes of table with the mapped column names.
+func defaultIndexesSQL(table string, columns map[string]string) []string {
+	stmts := make([]string, 0, len(defaultIndexes))
+	for _, index := range defaultIndexes {
+		cols := make([]string, 0, len(index))
+		for _, column := range index {
+			cols = append(cols, quoteIdent(mappedColumn(columns, column)))
+		}
+		name := unqualified(table) + "_" + strings.Join(index, "_") + "_idx"
+		stmts = append(stmts, "CREATE INDEX IF NOT EXISTS "+quoteIdent(name)+" ON "+quoteQualified(table)+
+			" ("+strings.Join(cols, ", ")+")")
+	}
+	return stmts
+}
+
+func (a *Adapter) createDefaultIndexes(table string) error {
+	if a.skipDefaultIndexes {
+		return nil
+	}
+	for _, stmt := range defaultIndexesSQL(table, a.columns) {
+		if _, err := a.conn().Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
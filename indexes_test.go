@@ -0,0 +1,28 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultIndexesSQL(t *testing.T) {
+	require.Equal(t, []string{
+		`CREATE INDEX IF NOT EXISTS "rules_ptype_idx" ON "auth"."rules" ("p_type")`,
+		`CREATE INDEX IF NOT EXISTS "rules_ptype_v0_v1_idx" ON "auth"."rules" ("p_type", "v0", "v1")`,
+	}, defaultIndexesSQL("auth.rules", map[string]string{"ptype": "p_type"}))
+}
+
+func (s *AdapterTestSuite) TestDefaultIndexes() {
+	var indexes []string
+	_, err := s.a.db.Query(&indexes, "SELECT indexname FROM pg_indexes WHERE tablename = ? ORDER BY indexname", DefaultTableName)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"casbin_rule_pkey", "casbin_rule_ptype_idx", "casbin_rule_ptype_v0_v1_idx"}, indexes)
+
+	_, err = NewAdapterByDB(s.a.db, WithTableName("casbin_rule_bare"), SkipDefaultIndexes())
+	s.Require().NoError(err)
+	indexes = nil
+	_, err = s.a.db.Query(&indexes, "SELECT indexname FROM pg_indexes WHERE tablename = ?", "casbin_rule_bare")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"casbin_rule_bare_pkey"}, indexes)
+}
@@ -0,0 +1,82 @@
+package pgadapter
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// WithJSONBStorage stores each rule as a single jsonb array in the rule column of the rules table, next to its id
+// and ptype, instead of the v0 to v5 columns, e.g. ["alice","data1","read"]. The column has a GIN index, so rules
+// can be searched by their values with the jsonb operators, e.g. rule @> '["alice"]', and the table can hold rules
+// of any arity written by other tools, the adapter itself reads and writes the first six values.
+// The adapter works on a view of the table exposing the usual columns, named <table>_mapped,
+// whose writes are forwarded to the table by triggers. It can't be combined with the other options
+// working on a view, like WithColumnMapping or WithSoftDelete, nor with options adding columns to the rules table.
+func WithJSONBStorage() Option {
+	return func(a *Adapter) {
+		a.jsonb = true
+	}
+}
+
+func (a *Adapter) checkJSONBStorage() error {
+	if a.jsonb && (len(a.columns) > 0 || a.softDelete || a.tenantScoped || a.partitioning != nil || a.surrogateKey != 0) {
+		return errors.New("WithJSONBStorage can't be combined with WithColumnMapping, WithSoftDelete, " +
+			"WithTenant, WithPartitioning nor WithSurrogateKey")
+	}
+	return nil
+}
+
+// jsonbSQL returns the statements creating the table storing the rules as jsonb, its indexes and its view,
+// the table is created by the first one.
+func (v ruleView) jsonbSQL() []string {
+	table := quoteQualified(v.table)
+	view := quoteQualified(v.name())
+	ruleFunction := quoteQualified(v.table + "_rule")
+	writeFunction := quoteQualified(v.table + "_write")
+	index := func(name string) string {
+		return quoteIdent(unqualified(v.table) + "_" + name + "_idx")
+	}
+
+	values := make([]string, 0, 6)
+	newValues := make([]string, 0, 6)
+	for i := 0; i < 6; i++ {
+		values = append(values, "rule->>"+strconv.Itoa(i)+" AS v"+strconv.Itoa(i))
+		newValues = append(newValues, "NEW.v"+strconv.Itoa(i))
+	}
+	rule := ruleFunction + "(" + strings.Join(newValues, ", ") + ")"
+
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + table + " (id text, ptype text, rule jsonb NOT NULL DEFAULT '[]', PRIMARY KEY (id))",
+		"CREATE INDEX IF NOT EXISTS " + index("ptype") + " ON " + table + " (ptype)",
+		"CREATE INDEX IF NOT EXISTS " + index("ptype_v0_v1") + " ON " + table + " (ptype, (rule->>0), (rule->>1))",
+		"CREATE INDEX IF NOT EXISTS " + index("rule") + " ON " + table + " USING gin (rule jsonb_path_ops)",
+		// The array holds the values up to the last non-empty one, empty values in between are null like in the columns.
+		"CREATE OR REPLACE FUNCTION " + ruleFunction + "(VARIADIC vs text[]) RETURNS jsonb AS $$\n" +
+			"DECLARE n int := coalesce(array_length(vs, 1), 0);\n" +
+			"BEGIN\n" +
+			"\tWHILE n > 0 AND coalesce(vs[n], '') = '' LOOP\n" +
+			"\t\tn := n - 1;\n" +
+			"\tEND LOOP;\n" +
+			"\tRETURN to_jsonb(vs[1:n]);\n" +
+			"END $$ LANGUAGE plpgsql IMMUTABLE",
+		"CREATE OR REPLACE VIEW " + view + " AS SELECT id, ptype, " + strings.Join(values, ", ") + " FROM " + table,
+		// Returning NULL skips the row like ON CONFLICT DO NOTHING, so it isn't counted as affected.
+		"CREATE OR REPLACE FUNCTION " + writeFunction + "() RETURNS trigger AS $$\n" +
+			"BEGIN\n" +
+			"\tIF TG_OP = 'DELETE' THEN\n" +
+			"\t\tDELETE FROM " + table + " WHERE id = OLD.id;\n" +
+			"\t\tIF NOT FOUND THEN RETURN NULL; END IF;\n" +
+			"\t\tRETURN OLD;\n" +
+			"\tELSIF TG_OP = 'INSERT' THEN\n" +
+			"\t\tINSERT INTO " + table + " (id, ptype, rule) VALUES (NEW.id, NEW.ptype, " + rule + ") ON CONFLICT DO NOTHING;\n" +
+			"\tELSE\n" +
+			"\t\tUPDATE " + table + " SET id = NEW.id, ptype = NEW.ptype, rule = " + rule + " WHERE id = OLD.id;\n" +
+			"\tEND IF;\n" +
+			"\tIF NOT FOUND THEN RETURN NULL; END IF;\n" +
+			"\tRETURN NEW;\n" +
+			"END $$ LANGUAGE plpgsql",
+		"DROP TRIGGER IF EXISTS write ON " + view,
+		"CREATE TRIGGER write INSTEAD OF INSERT OR UPDATE OR DELETE ON " + view + " FOR EACH ROW EXECUTE PROCEDURE " + writeFunction + "()",
+	}
+}
@@ -0,0 +1,29 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONBStorageSQL(t *testing.T) {
+	a := &Adapter{tableName: "casbin_rule"}
+	WithJSONBStorage()(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "casbin_rule_mapped", a.tableName)
+
+	stmts := a.ruleView().sql()
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS "casbin_rule" (id text, ptype text, rule jsonb NOT NULL DEFAULT '[]', PRIMARY KEY (id))`, stmts[0])
+	require.Contains(t, stmts, `CREATE OR REPLACE VIEW "casbin_rule_mapped" AS SELECT id, ptype, `+
+		`rule->>0 AS v0, rule->>1 AS v1, rule->>2 AS v2, rule->>3 AS v3, rule->>4 AS v4, rule->>5 AS v5 FROM "casbin_rule"`)
+
+	a = &Adapter{tableName: "casbin_rule"}
+	WithJSONBStorage()(a)
+	WithSoftDelete()(a)
+	require.Error(t, a.mapColumns())
+}
+
+// TestJSONBStorageSuite runs the tests of the adapter on the table storing the rules as jsonb.
+func TestJSONBStorageSuite(t *testing.T) {
+	runCoreTests(t, &AdapterTestSuite{opts: []Option{WithTableName("casbin_rule_jsonb"), WithJSONBStorage()}})
+}
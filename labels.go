@@ -0,0 +1,85 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// WithLabels adds a labels jsonb column to the rules table holding the labels of the rules,
+// see PolicyMeta.Labels, SetPolicyLabels and Filter.Labels.
+func WithLabels() Option {
+	return func(a *Adapter) {
+		a.labels = true
+	}
+}
+
+func (a *Adapter) createLabelsColumn() error {
+	_, err := a.conn().Exec("ALTER TABLE ? ADD COLUMN IF NOT EXISTS labels jsonb NOT NULL DEFAULT '{}'", pg.Ident(a.tableName))
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? USING gin (labels jsonb_path_ops)",
+		pg.Ident(unqualified(a.tableName)+"_labels_idx"), pg.Ident(a.tableName))
+	return err
+}
+
+// labelQuery restricts query to the rules carrying all labels.
+func labelQuery(query *orm.Query, labels map[string]string) *orm.Query {
+	if len(labels) == 0 {
+		return query
+	}
+	return query.Where("labels @> ?", labels)
+}
+
+// SetPolicyLabels merges labels into the labels of the given rules of ptype, an empty value removes the label.
+// It requires WithLabels. Rules that aren't stored are ignored.
+func (a *Adapter) SetPolicyLabels(ctx context.Context, sec string, ptype string, rules [][]string, labels map[string]string) error {
+	op := &Operation{Name: OpSetPolicyLabels, Sec: sec, Ptype: ptype, Rules: rules}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.setPolicyLabels(ctx, sec, ptype, rules, labels)
+	})
+}
+
+func (a *Adapter) setPolicyLabels(ctx context.Context, sec string, ptype string, rules [][]string, labels map[string]string) (err error) {
+	defer a.handleError(OpSetPolicyLabels, ptype, len(rules), &err)
+
+	if !a.labels {
+		return errors.New("labels are not enabled, see WithLabels")
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	set := make(map[string]string, len(labels))
+	unset := []string{}
+	for k, v := range labels {
+		if v == "" {
+			unset = append(unset, k)
+		} else {
+			set[k] = v
+		}
+	}
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, a.ruleID(ptype, rule))
+	}
+
+	change := PolicyChange{Operation: OpSetPolicyLabels, Sec: sec, Ptype: ptype, Rules: rules}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE ? SET labels = (labels - ?::text[]) || ? WHERE id IN (?)",
+			pg.Ident(a.tableName), pg.Array(unset), set, pg.In(ids))
+		if err != nil {
+			return err
+		}
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.changed(ctx, change)
+	return nil
+}
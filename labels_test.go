@@ -0,0 +1,31 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestLabels() {
+	a, err := NewAdapterByDB(s.a.db, WithLabels())
+	s.Require().NoError(err)
+
+	ctx := context.Background()
+	meta := PolicyMeta{Labels: map[string]string{"team": "billing", "ticket": "JIRA-1234"}}
+	s.Require().NoError(a.AddPolicyWithMeta(ctx, "p", "p", []string{"carol", "data3", "read"}, meta))
+	s.Require().NoError(a.SetPolicyLabels(ctx, "p", "p", [][]string{{"alice", "data1", "read"}}, map[string]string{"team": "billing"}))
+	s.Require().NoError(a.SetPolicyLabels(ctx, "p", "p", [][]string{{"carol", "data3", "read"}}, map[string]string{"ticket": "", "env": "prod"}))
+
+	policies, err := a.ListPolicies(ctx, &Filter{P: []string{}, Labels: map[string]string{"team": "billing"}})
+	s.Require().NoError(err)
+	s.Require().Equal([]Policy{
+		{Ptype: "p", Rule: []string{"alice", "data1", "read"}, Meta: PolicyMeta{Labels: map[string]string{"team": "billing"}}},
+		{Ptype: "p", Rule: []string{"carol", "data3", "read"}, Meta: PolicyMeta{Labels: map[string]string{"team": "billing", "env": "prod"}}},
+	}, policies)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	err = e.LoadFilteredPolicy(&Filter{P: []string{}, Labels: map[string]string{"env": "prod"}})
+	s.Require().NoError(err)
+	s.assertPolicy([][]string{{"carol", "data3", "read"}}, e.GetPolicy())
+}
@@ -0,0 +1,72 @@
+package pgadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Logger is the logger of the adapter, *log.Logger implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithLogger makes the adapter log its failed operations to l, see also WithDebugLogging.
+func WithLogger(l Logger) Option {
+	return func(a *Adapter) {
+		a.logger = l
+	}
+}
+
+// WithDebugLogging makes the adapter log the SQL statements of its operations with their duration
+// to the logger of WithLogger, to diagnose which rows the operations affect. The statements are logged
+// with their placeholders, the values aren't logged. The statements run within a caller's transaction, see WithTx,
+// and the ones creating the tables aren't logged.
+func WithDebugLogging() Option {
+	return func(a *Adapter) {
+		a.debugLogging = true
+	}
+}
+
+// setupLogging adds the hook logging the statements to the connection pool with WithDebugLogging.
+func (a *Adapter) setupLogging() {
+	if a.debugLogging && a.logger != nil && a.db != nil {
+		a.queryLog = &queryLogHook{logger: a.logger}
+		a.db.AddQueryHook(a.queryLog)
+	}
+}
+
+type queryLogKey struct{}
+
+// queryLogHook logs the statements run with a context carrying it, the pool may be shared
+// with other adapters and the application.
+type queryLogHook struct {
+	logger Logger
+}
+
+var _ pg.QueryHook = (*queryLogHook)(nil)
+
+func (h *queryLogHook) BeforeQuery(ctx context.Context, _ *pg.QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *queryLogHook) AfterQuery(ctx context.Context, evt *pg.QueryEvent) error {
+	if ctx.Value(queryLogKey{}) != h {
+		return nil
+	}
+	query, err := evt.UnformattedQuery()
+	if err != nil {
+		return nil
+	}
+	var name string
+	if op := operationFrom(ctx); op != nil {
+		name = op.Name
+	}
+	if evt.Err != nil {
+		h.logger.Printf("pgadapter.%s: %s (%s): %v", name, query, time.Since(evt.StartTime), evt.Err)
+	} else {
+		h.logger.Printf("pgadapter.%s: %s (%s)", name, query, time.Since(evt.StartTime))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger []string
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func TestQueryLogHook(t *testing.T) {
+	var logger testLogger
+	h := &queryLogHook{logger: &logger}
+	evt := &pg.QueryEvent{
+		StartTime: time.Now(),
+		Query:     "DELETE FROM casbin_rule WHERE v0 = ?",
+		Params:    []interface{}{"alice"},
+	}
+
+	// The statements of other adapters and of the application aren't logged.
+	require.NoError(t, h.AfterQuery(context.Background(), evt))
+	require.Empty(t, logger)
+
+	ctx := context.WithValue(context.Background(), queryLogKey{}, h)
+	ctx = context.WithValue(ctx, operationKey{}, &Operation{Name: OpRemovePolicy})
+	require.NoError(t, h.AfterQuery(ctx, evt))
+	require.Len(t, logger, 1)
+	require.Contains(t, logger[0], "pgadapter.RemovePolicy: DELETE FROM casbin_rule WHERE v0 = ?")
+	require.NotContains(t, logger[0], "alice", "the values aren't logged")
+}
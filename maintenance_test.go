@@ -0,0 +1,92 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWritable(t *testing.T) {
+	a := &Adapter{}
+	require.True(t, a.IsWritable())
+
+	a.SetWritable(false)
+	require.False(t, a.IsWritable())
+	require.ErrorIs(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}), ErrReadOnly)
+	require.ErrorIs(t, a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}), ErrReadOnly)
+	_, err := a.UpdateFilteredPolicies("p", "p", nil, 0, "alice")
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	a.SetWritable(true)
+	require.True(t, a.IsWritable())
+}
+
+func TestSetWritableWithTx(t *testing.T) {
+	a := &Adapter{}
+	b := a.WithTx(&pg.Tx{})
+
+	a.SetWritable(false)
+	require.False(t, b.IsWritable())
+	require.ErrorIs(t, b.AddPolicy("p", "p", []string{"alice", "data1", "read"}), ErrReadOnly)
+
+	a.SetWritable(true)
+	require.True(t, b.IsWritable())
+}
+
+func TestSetWritableTransaction(t *testing.T) {
+	a := &Adapter{}
+	a.SetWritable(false)
+
+	err := a.Transaction(context.Background(), func(*Adapter) error {
+		t.Fatal("the transaction must not run while writes are disabled")
+		return nil
+	})
+	require.ErrorIs(t, err, ErrReadOnly)
+}
+
+func (s *AdapterTestSuite) TestSetWritableWithTx() {
+	s.a.SetWritable(false)
+	defer s.a.SetWritable(true)
+
+	tx, err := s.a.db.Begin()
+	s.Require().NoError(err)
+	defer tx.Rollback()
+	err = s.a.WithTx(tx).AddPolicy("p", "p", []string{"alice", "data1", "write"})
+	s.Require().ErrorIs(err, ErrReadOnly)
+}
+
+func (s *AdapterTestSuite) TestSetWritableTransaction() {
+	ctx := context.Background()
+	err := s.a.Transaction(ctx, func(a *Adapter) error {
+		s.a.SetWritable(false)
+		return a.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "write"})
+	})
+	s.a.SetWritable(true)
+	s.Require().ErrorIs(err, ErrReadOnly)
+
+	s.a.SetWritable(false)
+	defer s.a.SetWritable(true)
+	err = s.a.Transaction(ctx, func(a *Adapter) error { return nil })
+	s.Require().ErrorIs(err, ErrReadOnly)
+}
+
+func (s *AdapterTestSuite) TestClusterMaintenanceMode() {
+	a, err := NewAdapterByDB(s.a.db, WithClusterMaintenanceMode())
+	s.Require().NoError(err)
+
+	err = a.SetClusterWritable(context.Background(), false)
+	s.Require().NoError(err)
+	err = a.AddPolicy("p", "p", []string{"alice", "data1", "write"})
+	s.Require().ErrorIs(err, ErrReadOnly)
+
+	// Adapters not opted into cluster maintenance mode are unaffected.
+	err = s.a.AddPolicy("p", "p", []string{"alice", "data1", "write"})
+	s.Require().NoError(err)
+
+	err = a.SetClusterWritable(context.Background(), true)
+	s.Require().NoError(err)
+	err = a.RemovePolicy("p", "p", []string{"alice", "data1", "write"})
+	s.Require().NoError(err)
+}
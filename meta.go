@@ -0,0 +1,168 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// PolicyMeta is the metadata stored next to a rule.
+type PolicyMeta struct {
+	// Description records why the rule exists, e.g. "JIRA-1234 temporary access for migration".
+	// It requires WithDescriptions.
+	Description string
+	// Labels group rules, e.g. by team, environment or ticket, see Filter.Labels. They require WithLabels.
+	Labels map[string]string
+	// Bundle is the bundle the rule belongs to, see WithBundles.
+	Bundle string
+}
+
+// Policy is a stored rule with its metadata.
+type Policy struct {
+	Ptype string
+	Rule  []string
+	Meta  PolicyMeta
+	// CreatedAt and UpdatedAt are the times the rule was added and last changed, they require WithTimestamps.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// policyRow is a row of the rules table including the optional metadata columns.
+type policyRow struct {
+	tableName struct{} `pg:"_"`
+	CasbinRule
+	Description string
+	Labels      map[string]string
+	Bundle      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (r *policyRow) policy() Policy {
+	return Policy{
+		Ptype:     r.Ptype,
+		Rule:      r.rule(),
+		Meta:      PolicyMeta{Description: r.Description, Labels: r.Labels, Bundle: r.Bundle},
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// WithDescriptions adds a description column to the rules table, see AddPolicyWithMeta.
+func WithDescriptions() Option {
+	return func(a *Adapter) {
+		a.descriptions = true
+	}
+}
+
+func (a *Adapter) createDescriptionColumn() error {
+	_, err := a.conn().Exec("ALTER TABLE ? ADD COLUMN IF NOT EXISTS description text", pg.Ident(a.tableName))
+	return err
+}
+
+// AddPolicyWithMeta adds a rule like AddPolicy and stores meta with it.
+// If the rule exists its metadata is replaced.
+func (a *Adapter) AddPolicyWithMeta(ctx context.Context, sec string, ptype string, rule []string, meta PolicyMeta) error {
+	op := &Operation{Name: OpAddPolicy, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.addPolicy(ctx, sec, ptype, rule, &meta)
+	})
+}
+
+// setMeta stores meta for the rule with id within tx.
+func (a *Adapter) setMeta(tx *pg.Tx, id string, meta *PolicyMeta) error {
+	if meta.Description != "" && !a.descriptions {
+		return errors.New("descriptions are not enabled, see WithDescriptions")
+	}
+	if len(meta.Labels) > 0 && !a.labels {
+		return errors.New("labels are not enabled, see WithLabels")
+	}
+	if meta.Bundle != "" && !a.bundles {
+		return errors.New("bundles are not enabled, see WithBundles")
+	}
+
+	if a.descriptions {
+		_, err := tx.Exec("UPDATE ? SET description = ? WHERE id = ?", pg.Ident(a.tableName), meta.Description, id)
+		if err != nil {
+			return err
+		}
+	}
+	if a.labels {
+		labels := meta.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		_, err := tx.Exec("UPDATE ? SET labels = ? WHERE id = ?", pg.Ident(a.tableName), labels, id)
+		if err != nil {
+			return err
+		}
+	}
+	if a.bundles {
+		// An empty bundle is stored as NULL, the rule doesn't belong to a bundle.
+		_, err := tx.Exec("UPDATE ? SET bundle = NULLIF(?, '') WHERE id = ?", pg.Ident(a.tableName), meta.Bundle, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPolicies returns the rules matching filter with their metadata in canonical order, see ExportPolicies.
+// A nil filter selects all rules, otherwise the filter works like the one of LoadFilteredPolicy.
+func (a *Adapter) ListPolicies(ctx context.Context, filter *Filter) (_ []Policy, err error) {
+	defer a.handleError(OpListPolicies, "", 0, &err)
+
+	var rows []*policyRow
+	query := a.conn().ModelContext(ctx, &rows).Table(a.tableName).OrderExpr(canonicalOrder)
+	if !a.descriptions {
+		query = query.ExcludeColumn("description")
+	}
+	if !a.labels {
+		query = query.ExcludeColumn("labels")
+	}
+	if !a.bundles {
+		query = query.ExcludeColumn("bundle")
+	}
+	if !a.timestamps {
+		query = query.ExcludeColumn("created_at", "updated_at")
+	}
+	query, err = applyFilter(query, a.foldFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rows))
+	for _, row := range rows {
+		policies = append(policies, row.policy())
+	}
+	return policies, nil
+}
+
+// applyFilter restricts query to the rules matching filter, a nil filter matches all rules.
+func applyFilter(query *orm.Query, filter *Filter) (*orm.Query, error) {
+	if filter == nil {
+		return query, nil
+	}
+	query = labelQuery(query, filter.Labels)
+
+	var err error
+	query = query.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+		// Matches nothing when the filter has no sections, like LoadFilteredPolicy.
+		q = q.WhereOr("false")
+		for _, sec := range filter.sections() {
+			q = q.WhereOrGroup(func(q *orm.Query) (*orm.Query, error) {
+				q = q.Where("ptype = ?", sec.ptype)
+				q, err = buildQuery(q, sec.values)
+				return q, err
+			})
+		}
+		return q, nil
+	})
+	return query, err
+}
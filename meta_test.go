@@ -0,0 +1,53 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFilter(t *testing.T) {
+	for _, tc := range []struct {
+		filter *Filter
+		where  string
+	}{
+		{nil, ""},
+		{&Filter{}, ` WHERE ((false))`},
+		{&Filter{P: []string{"", "domain1"}}, ` WHERE ((false) OR ((ptype = 'p') AND (v1 = 'domain1')))`},
+		{&Filter{P: []string{"alice"}, G: []string{}}, ` WHERE ((false) OR ((ptype = 'p') AND (v0 = 'alice')) OR ((ptype = 'g')))`},
+		{&Filter{P: []string{"alice"}, Ptypes: map[string][]string{"p": {"bob"}, "g2": {"alice"}, "p2": nil}}, ` WHERE ((false) OR ((ptype = 'p') AND (v0 = 'alice')) OR ((ptype = 'g2') AND (v0 = 'alice')))`},
+	} {
+		var lines []*CasbinRule
+		query, err := applyFilter(orm.NewQuery(nil, &lines).Table("casbin_rule").Column("id"), tc.filter)
+		require.NoError(t, err)
+		b, err := orm.NewSelectQuery(query).AppendQuery(orm.NewFormatter(), nil)
+		require.NoError(t, err)
+		require.Equal(t, `SELECT "id" FROM "casbin_rule" AS "casbin_rule"`+tc.where, string(b))
+	}
+
+	_, err := applyFilter(orm.NewQuery(nil), &Filter{G: make([]string, 7)})
+	require.NoError(t, err, "empty values are not filtered on")
+	_, err = applyFilter(orm.NewQuery(nil), &Filter{G: []string{"", "", "", "", "", "", "x"}})
+	require.ErrorIs(t, err, ErrTooManyFields)
+}
+
+func (s *AdapterTestSuite) TestAddPolicyWithMeta() {
+	a, err := NewAdapterByDB(s.a.db, WithDescriptions())
+	s.Require().NoError(err)
+
+	ctx := context.Background()
+	meta := PolicyMeta{Description: "JIRA-1234 temporary access for migration"}
+	s.Require().NoError(a.AddPolicyWithMeta(ctx, "p", "p", []string{"carol", "data3", "read"}, meta))
+
+	policies, err := a.ListPolicies(ctx, &Filter{P: []string{"carol"}})
+	s.Require().NoError(err)
+	s.Require().Equal([]Policy{{Ptype: "p", Rule: []string{"carol", "data3", "read"}, Meta: meta}}, policies)
+
+	// The adapters without descriptions still list the rules.
+	policies, err = s.a.ListPolicies(ctx, nil)
+	s.Require().NoError(err)
+	s.Require().Len(policies, 6)
+	s.Require().Error(s.a.AddPolicyWithMeta(ctx, "p", "p", []string{"dave", "data3", "read"}, meta))
+}
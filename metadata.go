@@ -0,0 +1,59 @@
+package pgadapter
+
+import (
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// DB returns the database handle used by the adapter.
+func (a *Adapter) DB() *pg.DB {
+	return a.db
+}
+
+// TableName returns the name of the table the adapter works on, see WithTableName. With the options making
+// the adapter work on a view of the rules table, like WithColumnMapping or WithSoftDelete, it is the name of the view.
+func (a *Adapter) TableName() string {
+	return a.tableName
+}
+
+// FullTableName returns the quoted, schema-qualified name of the rules table, e.g. "public"."casbin_rule",
+// ready to be used in SQL. The schema is the one of the configured table name or, if it has none, the one
+// the table was found in by the search path when the adapter was created.
+func (a *Adapter) FullTableName() string {
+	schema, table := a.schema, a.tableName
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		schema, table = table[:i], table[i+1:]
+	}
+	if schema == "" {
+		schema = "public"
+	}
+	return quoteIdent(schema) + "." + quoteIdent(table)
+}
+
+// resolveSchema looks up the schema of the table, see FullTableName. If the table doesn't exist,
+// e.g. with SkipTableCreate, it is the schema the table would be created in.
+func (a *Adapter) resolveSchema() error {
+	if strings.IndexByte(a.tableName, '.') >= 0 {
+		return nil
+	}
+	_, err := a.conn().QueryOne(pg.Scan(&a.schema), "SELECT coalesce((SELECT n.nspname FROM pg_class c "+
+		"JOIN pg_namespace n ON n.oid = c.relnamespace WHERE c.oid = to_regclass(?)), current_schema())", quoteIdent(a.tableName))
+	return err
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteQualified quotes each part of the possibly schema-qualified name.
+func quoteQualified(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return quoteIdent(name[:i]) + "." + quoteIdent(name[i+1:])
+	}
+	return quoteIdent(name)
+}
@@ -0,0 +1,61 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullTableName(t *testing.T) {
+	a := &Adapter{tableName: DefaultTableName}
+	require.Equal(t, DefaultTableName, a.TableName())
+	require.Equal(t, `"public"."casbin_rule"`, a.FullTableName())
+
+	WithTableName(`authz.my"rules`)(a)
+	require.Equal(t, `authz.my"rules`, a.TableName())
+	require.Equal(t, `"authz"."my""rules"`, a.FullTableName())
+
+	a = &Adapter{tableName: DefaultTableName, schema: "authz"}
+	require.Equal(t, `"authz"."casbin_rule"`, a.FullTableName())
+}
+
+func (s *AdapterTestSuite) TestFullTableNameSearchPath() {
+	tx, err := s.a.db.Begin()
+	s.Require().NoError(err)
+	defer tx.Rollback()
+	_, err = tx.Exec("CREATE SCHEMA casbin_test_schema; SET LOCAL search_path TO casbin_test_schema")
+	s.Require().NoError(err)
+
+	a, err := NewAdapterByTx(tx)
+	s.Require().NoError(err)
+	s.Require().Equal(`"casbin_test_schema"."casbin_rule"`, a.FullTableName())
+}
+
+func (s *AdapterTestSuite) TestFullTableNameQuery() {
+	s.Require().Same(s.a.db, s.a.DB())
+
+	var count int
+	_, err := s.a.DB().QueryOne(pg.Scan(&count), "SELECT count(*) FROM "+s.a.FullTableName())
+	s.Require().NoError(err)
+	s.Require().Equal(5, count)
+}
+
+func (s *AdapterTestSuite) TestWithTable() {
+	b, err := s.a.WithTable("casbin_rule_other")
+	s.Require().NoError(err)
+	s.Require().Same(s.a.DB(), b.DB())
+	s.Require().Equal("casbin_rule_other", b.TableName())
+
+	s.Require().NoError(b.AddPolicy("p", "p", []string{"carol", "data3", "read"}))
+
+	var rules []CasbinRule
+	s.Require().NoError(b.DB().Model(&rules).Table(b.TableName()).Select())
+	s.Require().Len(rules, 1)
+	s.Require().Equal([]string{"carol", "data3", "read"}, rules[0].rule())
+
+	// Closing the sibling leaves the shared pool open.
+	s.Require().NoError(b.Close())
+	s.Require().NoError(s.a.DB().Ping(context.Background()))
+}
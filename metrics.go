@@ -0,0 +1,81 @@
+package pgadapter
+
+import (
+	"errors"
+	"time"
+)
+
+// Metrics receives the measurements of the adapter operations, implementations export them,
+// e.g. as Prometheus histograms of the durations and counters of the rules and errors labeled by operation.
+type Metrics interface {
+	// ObserveOperation is called once the operation op returned, with its duration, the number of rules
+	// it loaded or wrote, see Operation.Rows, and its error, which ErrorKind turns into a label.
+	ObserveOperation(op string, duration time.Duration, rows int, err error)
+}
+
+// WithMetrics reports every adapter operation to m, through a middleware wrapping the ones added by Use.
+func WithMetrics(m Metrics) Option {
+	return func(a *Adapter) {
+		a.middleware = append(a.middleware, metricsMiddleware(m))
+	}
+}
+
+func metricsMiddleware(m Metrics) Middleware {
+	return func(next Handler) Handler {
+		return func(op *Operation) error {
+			start := time.Now()
+			err := next(op)
+			m.ObserveOperation(op.Name, time.Since(start), operationRows(op), err)
+			return err
+		}
+	}
+}
+
+// operationRows returns the number of rules loaded or written by op.
+func operationRows(op *Operation) int {
+	if op.Rows > 0 {
+		return op.Rows
+	}
+	switch op.Name {
+	case OpRemoveFilteredPolicy:
+		// Rules holds the field values of the filter.
+		return len(op.Result)
+	case OpUpdateFilteredPolicies:
+		return len(op.Result) + len(op.Rules)
+	case OpAddPolicy, OpAddPolicies, OpRemovePolicy, OpRemovePolicies, OpUpdatePolicy, OpUpdatePolicies:
+		return len(op.Rules)
+	}
+	return 0
+}
+
+// errorKindLabels are the labels of the failure classes returned by ErrorKind.
+var errorKindLabels = []struct {
+	kind  error
+	label string
+}{
+	{ErrDatabaseCreate, "database_create"},
+	{ErrTableMissing, "table_missing"},
+	{ErrPolicyExists, "policy_exists"},
+	{ErrNotFound, "not_found"},
+	{ErrTooManyFields, "too_many_fields"},
+	{ErrConnUnavailable, "conn_unavailable"},
+	{ErrConflict, "conflict"},
+	{ErrQuotaExceeded, "quota_exceeded"},
+	{ErrPtypeNotAllowed, "ptype_not_allowed"},
+	{ErrSelfApproval, "self_approval"},
+	{ErrReadOnly, "read_only"},
+}
+
+// ErrorKind returns a label of the failure class of err for metrics, e.g. "conn_unavailable",
+// "other" if err isn't classified and "" if err is nil.
+func ErrorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, k := range errorKindLabels {
+		if errors.Is(err, k.kind) {
+			return k.label
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,43 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordedMetrics struct {
+	ops  []string
+	rows []int
+	errs []string
+}
+
+func (m *recordedMetrics) ObserveOperation(op string, duration time.Duration, rows int, err error) {
+	m.ops = append(m.ops, op)
+	m.rows = append(m.rows, rows)
+	m.errs = append(m.errs, ErrorKind(err))
+}
+
+func TestMetrics(t *testing.T) {
+	m := &recordedMetrics{}
+	a := &Adapter{}
+	WithMetrics(m)(a)
+
+	err := a.doContext(context.Background(), &Operation{Name: OpAddPolicies, Rules: [][]string{{"alice"}, {"bob"}}}, func(_ context.Context) error {
+		return nil
+	})
+	require.NoError(t, err)
+	err = a.doContext(context.Background(), &Operation{Name: OpLoadPolicy}, func(ctx context.Context) error {
+		countRows(ctx, 5)
+		return &Error{Op: OpLoadPolicy, Kind: ErrTableMissing, Err: errors.New("missing")}
+	})
+	require.Error(t, err)
+
+	require.Equal(t, []string{OpAddPolicies, OpLoadPolicy}, m.ops)
+	require.Equal(t, []int{2, 5}, m.rows)
+	require.Equal(t, []string{"", "table_missing"}, m.errs)
+	require.Equal(t, "other", ErrorKind(errors.New("boom")))
+}
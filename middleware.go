@@ -0,0 +1,69 @@
+package pgadapter
+
+import "context"
+
+// Operation describes an adapter operation passed through the middleware chain.
+type Operation struct {
+	// Name is one of the Op* constants.
+	Name  string
+	Sec   string
+	Ptype string
+	// Rules are the rules written or removed, the new rules of updates and
+	// the field values of RemoveFilteredPolicy and UpdateFilteredPolicies.
+	Rules [][]string
+	// OldRules are the rules replaced by UpdatePolicy and UpdatePolicies.
+	OldRules   [][]string
+	FieldIndex int
+	// Result holds the rules returned by the operation, i.e. the rules replaced by UpdateFilteredPolicies.
+	// It is set once the next handler returns.
+	Result [][]string
+	// Rows is the number of rules loaded by the loads and written by SavePolicy, it is set once the next handler returns.
+	Rows int
+	// Settings are Postgres settings applied with SET LOCAL in the transactions of the operation,
+	// e.g. {"app.actor": "jane"} for database audit triggers reading current_setting('app.actor').
+	// Middleware can add settings before calling next, see also WithSettings.
+	Settings map[string]string
+}
+
+type operationKey struct{}
+
+// operationFrom returns the operation carried by ctx, or nil.
+func operationFrom(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+	return op
+}
+
+// countRows sets the Rows of the operation carried by ctx.
+func countRows(ctx context.Context, rows int) {
+	if op := operationFrom(ctx); op != nil {
+		op.Rows = rows
+	}
+}
+
+// Handler executes an operation.
+type Handler func(op *Operation) error
+
+// Middleware wraps the execution of the adapter operations, it can act before and after calling next,
+// e.g. to record metrics or purge caches based on the operation and its error.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the chain wrapping every adapter operation.
+// The first middleware is the outermost one. Use must not be called concurrently with adapter operations.
+func (a *Adapter) Use(mw ...Middleware) {
+	a.middleware = append(a.middleware, mw...)
+}
+
+// doContext runs fn through the middleware chain,
+// fn receives ctx carrying the operation as passed to the innermost handler.
+func (a *Adapter) doContext(ctx context.Context, op *Operation, fn func(ctx context.Context) error) error {
+	if a.queryLog != nil {
+		ctx = context.WithValue(ctx, queryLogKey{}, a.queryLog)
+	}
+	h := func(op *Operation) error {
+		return fn(context.WithValue(ctx, operationKey{}, op))
+	}
+	for i := len(a.middleware) - 1; i >= 0; i-- {
+		h = a.middleware[i](h)
+	}
+	return h(op)
+}
@@ -0,0 +1,43 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(op *Operation) error {
+				calls = append(calls, name+" before "+op.Name)
+				err := next(op)
+				calls = append(calls, name+" after "+op.Name)
+				return err
+			}
+		}
+	}
+
+	a := &Adapter{}
+	a.Use(record("outer"), record("inner"))
+	a.SetWritable(false)
+
+	var opErr error
+	a.Use(func(next Handler) Handler {
+		return func(op *Operation) error {
+			opErr = next(op)
+			return opErr
+		}
+	})
+
+	err := a.RemovePolicies("p", "p", [][]string{{"alice", "data1", "read"}})
+	require.ErrorIs(t, err, ErrReadOnly)
+	require.ErrorIs(t, opErr, ErrReadOnly)
+	require.Equal(t, []string{
+		"outer before RemovePolicies",
+		"inner before RemovePolicies",
+		"inner after RemovePolicies",
+		"outer after RemovePolicies",
+	}, calls)
+}
@@ -0,0 +1,96 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// LegacyTableName is the rules table of the v0.1.x releases, see Migrate01x.
+const LegacyTableName = "casbin_rules"
+
+// MigrateReport is the result of Migrate01x.
+type MigrateReport struct {
+	// Read is the number of rows of the legacy table.
+	Read int
+	// Migrated is the number of rules inserted into the rules table.
+	Migrated int
+	// Skipped is the number of rows whose rule was repeated in the legacy table or already stored.
+	Skipped int
+}
+
+// legacyRule is a row of the rules table of the v0.1.x releases.
+type legacyRule struct {
+	tableName struct{} `pg:"_"`
+	PType     string   `pg:"p_type"`
+	V0        string
+	V1        string
+	V2        string
+	V3        string
+	V4        string
+	V5        string
+}
+
+// Migrate01x copies the rules of the table created by the v0.1.x releases, source or LegacyTableName if empty,
+// with its p_type column, into the rules table, which it creates if needed, opts can be used to select the table,
+// e.g. WithTableName. The IDs are computed like the adapter does, from the rules without their trailing
+// empty values, so RemovePolicy finds the migrated rules. Rules already stored are left untouched.
+// The copy runs in a single transaction, which checks that every rule of the source is stored before committing.
+// The source table is left in place, it can be dropped once the application runs on the new table.
+func Migrate01x(ctx context.Context, db *pg.DB, source string, opts ...Option) (_ *MigrateReport, err error) {
+	defer wrapError(OpMigrate01x, &err)
+
+	if source == "" {
+		source = LegacyTableName
+	}
+	a, err := NewAdapterByDB(db, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrateReport{}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		var rows []*legacyRule
+		if err := tx.ModelContext(ctx, &rows).Table(source).For("SHARE").Select(); err != nil {
+			return err
+		}
+		report.Read = len(rows)
+		if len(rows) == 0 {
+			return nil
+		}
+
+		lines := make([]*CasbinRule, 0, len(rows))
+		for _, row := range rows {
+			line := &CasbinRule{Ptype: row.PType, V0: row.V0, V1: row.V1, V2: row.V2, V3: row.V3, V4: row.V4, V5: row.V5}
+			lines = append(lines, a.ruleLine(line.Ptype, line.rule()))
+		}
+		sortRules(lines)
+		lines = uniqueRules(lines)
+
+		res, err := tx.ModelContext(ctx, &lines).Table(a.tableName).OnConflict("DO NOTHING").Insert()
+		if err != nil {
+			return err
+		}
+		report.Migrated = res.RowsAffected()
+		report.Skipped = report.Read - report.Migrated
+
+		ids := make([]string, 0, len(lines))
+		for _, line := range lines {
+			ids = append(ids, line.ID)
+		}
+		var stored int
+		_, err = tx.QueryOneContext(ctx, pg.Scan(&stored), "SELECT count(*) FROM ? WHERE id IN (?)", pg.Ident(a.tableName), pg.In(ids))
+		if err != nil {
+			return err
+		}
+		if stored != len(lines) {
+			return fmt.Errorf("migration check failed, %d of the %d rules of %s are stored", stored, len(lines), source)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,27 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestMigrate01x() {
+	_, err := s.a.db.Exec(`
+		CREATE TABLE casbin_rules (p_type text, v0 text, v1 text, v2 text, v3 text, v4 text, v5 text);
+		INSERT INTO casbin_rules VALUES
+			('p', 'carol', 'data1', 'read', '', '', ''),
+			('p', 'carol', 'data1', 'read', NULL, NULL, NULL),
+			('g', 'carol', 'data2_admin', NULL, NULL, NULL, NULL),
+			('p', 'alice', 'data1', 'read', '', '', '');
+	`)
+	s.Require().NoError(err)
+
+	report, err := Migrate01x(context.Background(), s.a.db, "")
+	s.Require().NoError(err)
+	s.Require().Equal(&MigrateReport{Read: 4, Migrated: 2, Skipped: 2}, report)
+
+	// The migrated rules have the IDs the adapter computes.
+	s.Require().NoError(s.a.RemovePolicy("p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().True(s.e.HasGroupingPolicy("carol", "data2_admin"))
+	s.Require().False(s.e.HasPolicy("carol", "data1", "read"))
+}
@@ -0,0 +1,490 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/casbin/casbin-pg-adapter (interfaces: PolicyStore)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	pgadapter "github.com/casbin/casbin-pg-adapter"
+	model "github.com/casbin/casbin/v2/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPolicyStore is a mock of PolicyStore interface.
+type MockPolicyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyStoreMockRecorder
+}
+
+// MockPolicyStoreMockRecorder is the mock recorder for MockPolicyStore.
+type MockPolicyStoreMockRecorder struct {
+	mock *MockPolicyStore
+}
+
+// NewMockPolicyStore creates a new mock instance.
+func NewMockPolicyStore(ctrl *gomock.Controller) *MockPolicyStore {
+	mock := &MockPolicyStore{ctrl: ctrl}
+	mock.recorder = &MockPolicyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyStore) EXPECT() *MockPolicyStoreMockRecorder {
+	return m.recorder
+}
+
+// AddPolicies mocks base method.
+func (m *MockPolicyStore) AddPolicies(arg0, arg1 string, arg2 [][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPolicies", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPolicies indicates an expected call of AddPolicies.
+func (mr *MockPolicyStoreMockRecorder) AddPolicies(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPolicies", reflect.TypeOf((*MockPolicyStore)(nil).AddPolicies), arg0, arg1, arg2)
+}
+
+// AddPoliciesReturning mocks base method.
+func (m *MockPolicyStore) AddPoliciesReturning(arg0, arg1 string, arg2 [][]string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPoliciesReturning", arg0, arg1, arg2)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPoliciesReturning indicates an expected call of AddPoliciesReturning.
+func (mr *MockPolicyStoreMockRecorder) AddPoliciesReturning(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPoliciesReturning", reflect.TypeOf((*MockPolicyStore)(nil).AddPoliciesReturning), arg0, arg1, arg2)
+}
+
+// AddPolicy mocks base method.
+func (m *MockPolicyStore) AddPolicy(arg0, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPolicy indicates an expected call of AddPolicy.
+func (mr *MockPolicyStoreMockRecorder) AddPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPolicy", reflect.TypeOf((*MockPolicyStore)(nil).AddPolicy), arg0, arg1, arg2)
+}
+
+// AddPolicyCtx mocks base method.
+func (m *MockPolicyStore) AddPolicyCtx(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPolicyCtx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPolicyCtx indicates an expected call of AddPolicyCtx.
+func (mr *MockPolicyStoreMockRecorder) AddPolicyCtx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPolicyCtx", reflect.TypeOf((*MockPolicyStore)(nil).AddPolicyCtx), arg0, arg1, arg2, arg3)
+}
+
+// Close mocks base method.
+func (m *MockPolicyStore) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockPolicyStoreMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockPolicyStore)(nil).Close))
+}
+
+// ExportPolicies mocks base method.
+func (m *MockPolicyStore) ExportPolicies(arg0 context.Context, arg1 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPolicies", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportPolicies indicates an expected call of ExportPolicies.
+func (mr *MockPolicyStoreMockRecorder) ExportPolicies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPolicies", reflect.TypeOf((*MockPolicyStore)(nil).ExportPolicies), arg0, arg1)
+}
+
+// GetFilteredPolicies mocks base method.
+func (m *MockPolicyStore) GetFilteredPolicies(arg0 string, arg1 int, arg2 ...string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFilteredPolicies", varargs...)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilteredPolicies indicates an expected call of GetFilteredPolicies.
+func (mr *MockPolicyStoreMockRecorder) GetFilteredPolicies(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilteredPolicies", reflect.TypeOf((*MockPolicyStore)(nil).GetFilteredPolicies), varargs...)
+}
+
+// GetPolicyByID mocks base method.
+func (m *MockPolicyStore) GetPolicyByID(arg0 context.Context, arg1 string) (string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicyByID", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPolicyByID indicates an expected call of GetPolicyByID.
+func (mr *MockPolicyStoreMockRecorder) GetPolicyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicyByID", reflect.TypeOf((*MockPolicyStore)(nil).GetPolicyByID), arg0, arg1)
+}
+
+// HasPolicy mocks base method.
+func (m *MockPolicyStore) HasPolicy(arg0 context.Context, arg1 string, arg2 []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPolicy indicates an expected call of HasPolicy.
+func (mr *MockPolicyStoreMockRecorder) HasPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPolicy", reflect.TypeOf((*MockPolicyStore)(nil).HasPolicy), arg0, arg1, arg2)
+}
+
+// IsEmpty mocks base method.
+func (m *MockPolicyStore) IsEmpty(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmpty", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmpty indicates an expected call of IsEmpty.
+func (mr *MockPolicyStoreMockRecorder) IsEmpty(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmpty", reflect.TypeOf((*MockPolicyStore)(nil).IsEmpty), arg0)
+}
+
+// IsFiltered mocks base method.
+func (m *MockPolicyStore) IsFiltered() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsFiltered")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsFiltered indicates an expected call of IsFiltered.
+func (mr *MockPolicyStoreMockRecorder) IsFiltered() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsFiltered", reflect.TypeOf((*MockPolicyStore)(nil).IsFiltered))
+}
+
+// IsWritable mocks base method.
+func (m *MockPolicyStore) IsWritable() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsWritable")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsWritable indicates an expected call of IsWritable.
+func (mr *MockPolicyStoreMockRecorder) IsWritable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWritable", reflect.TypeOf((*MockPolicyStore)(nil).IsWritable))
+}
+
+// LoadFilteredPolicy mocks base method.
+func (m *MockPolicyStore) LoadFilteredPolicy(arg0 model.Model, arg1 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadFilteredPolicy", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadFilteredPolicy indicates an expected call of LoadFilteredPolicy.
+func (mr *MockPolicyStoreMockRecorder) LoadFilteredPolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadFilteredPolicy", reflect.TypeOf((*MockPolicyStore)(nil).LoadFilteredPolicy), arg0, arg1)
+}
+
+// LoadPolicy mocks base method.
+func (m *MockPolicyStore) LoadPolicy(arg0 model.Model) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPolicy", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadPolicy indicates an expected call of LoadPolicy.
+func (mr *MockPolicyStoreMockRecorder) LoadPolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPolicy", reflect.TypeOf((*MockPolicyStore)(nil).LoadPolicy), arg0)
+}
+
+// LoadPolicyCtx mocks base method.
+func (m *MockPolicyStore) LoadPolicyCtx(arg0 context.Context, arg1 model.Model) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPolicyCtx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadPolicyCtx indicates an expected call of LoadPolicyCtx.
+func (mr *MockPolicyStoreMockRecorder) LoadPolicyCtx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPolicyCtx", reflect.TypeOf((*MockPolicyStore)(nil).LoadPolicyCtx), arg0, arg1)
+}
+
+// RemoveFilteredPolicy mocks base method.
+func (m *MockPolicyStore) RemoveFilteredPolicy(arg0, arg1 string, arg2 int, arg3 ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveFilteredPolicy", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFilteredPolicy indicates an expected call of RemoveFilteredPolicy.
+func (mr *MockPolicyStoreMockRecorder) RemoveFilteredPolicy(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFilteredPolicy", reflect.TypeOf((*MockPolicyStore)(nil).RemoveFilteredPolicy), varargs...)
+}
+
+// RemoveFilteredPolicyCtx mocks base method.
+func (m *MockPolicyStore) RemoveFilteredPolicyCtx(arg0 context.Context, arg1, arg2 string, arg3 int, arg4 ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveFilteredPolicyCtx", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFilteredPolicyCtx indicates an expected call of RemoveFilteredPolicyCtx.
+func (mr *MockPolicyStoreMockRecorder) RemoveFilteredPolicyCtx(arg0, arg1, arg2, arg3 interface{}, arg4 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFilteredPolicyCtx", reflect.TypeOf((*MockPolicyStore)(nil).RemoveFilteredPolicyCtx), varargs...)
+}
+
+// RemoveFilteredPolicyReturning mocks base method.
+func (m *MockPolicyStore) RemoveFilteredPolicyReturning(arg0, arg1 string, arg2 int, arg3 ...string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveFilteredPolicyReturning", varargs...)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveFilteredPolicyReturning indicates an expected call of RemoveFilteredPolicyReturning.
+func (mr *MockPolicyStoreMockRecorder) RemoveFilteredPolicyReturning(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFilteredPolicyReturning", reflect.TypeOf((*MockPolicyStore)(nil).RemoveFilteredPolicyReturning), varargs...)
+}
+
+// RemovePolicies mocks base method.
+func (m *MockPolicyStore) RemovePolicies(arg0, arg1 string, arg2 [][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePolicies", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePolicies indicates an expected call of RemovePolicies.
+func (mr *MockPolicyStoreMockRecorder) RemovePolicies(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePolicies", reflect.TypeOf((*MockPolicyStore)(nil).RemovePolicies), arg0, arg1, arg2)
+}
+
+// RemovePolicy mocks base method.
+func (m *MockPolicyStore) RemovePolicy(arg0, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePolicy indicates an expected call of RemovePolicy.
+func (mr *MockPolicyStoreMockRecorder) RemovePolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePolicy", reflect.TypeOf((*MockPolicyStore)(nil).RemovePolicy), arg0, arg1, arg2)
+}
+
+// RemovePolicyCtx mocks base method.
+func (m *MockPolicyStore) RemovePolicyCtx(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePolicyCtx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePolicyCtx indicates an expected call of RemovePolicyCtx.
+func (mr *MockPolicyStoreMockRecorder) RemovePolicyCtx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePolicyCtx", reflect.TypeOf((*MockPolicyStore)(nil).RemovePolicyCtx), arg0, arg1, arg2, arg3)
+}
+
+// Revision mocks base method.
+func (m *MockPolicyStore) Revision() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revision")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// Revision indicates an expected call of Revision.
+func (mr *MockPolicyStoreMockRecorder) Revision() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revision", reflect.TypeOf((*MockPolicyStore)(nil).Revision))
+}
+
+// SavePolicy mocks base method.
+func (m *MockPolicyStore) SavePolicy(arg0 model.Model) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePolicy", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePolicy indicates an expected call of SavePolicy.
+func (mr *MockPolicyStoreMockRecorder) SavePolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePolicy", reflect.TypeOf((*MockPolicyStore)(nil).SavePolicy), arg0)
+}
+
+// SavePolicyCtx mocks base method.
+func (m *MockPolicyStore) SavePolicyCtx(arg0 context.Context, arg1 model.Model) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePolicyCtx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePolicyCtx indicates an expected call of SavePolicyCtx.
+func (mr *MockPolicyStoreMockRecorder) SavePolicyCtx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePolicyCtx", reflect.TypeOf((*MockPolicyStore)(nil).SavePolicyCtx), arg0, arg1)
+}
+
+// SetClusterWritable mocks base method.
+func (m *MockPolicyStore) SetClusterWritable(arg0 context.Context, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetClusterWritable", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClusterWritable indicates an expected call of SetClusterWritable.
+func (mr *MockPolicyStoreMockRecorder) SetClusterWritable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClusterWritable", reflect.TypeOf((*MockPolicyStore)(nil).SetClusterWritable), arg0, arg1)
+}
+
+// SetWritable mocks base method.
+func (m *MockPolicyStore) SetWritable(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetWritable", arg0)
+}
+
+// SetWritable indicates an expected call of SetWritable.
+func (mr *MockPolicyStoreMockRecorder) SetWritable(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWritable", reflect.TypeOf((*MockPolicyStore)(nil).SetWritable), arg0)
+}
+
+// Subscribe mocks base method.
+func (m *MockPolicyStore) Subscribe(arg0 context.Context) (<-chan pgadapter.PolicyChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", arg0)
+	ret0, _ := ret[0].(<-chan pgadapter.PolicyChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockPolicyStoreMockRecorder) Subscribe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockPolicyStore)(nil).Subscribe), arg0)
+}
+
+// UpdateFilteredPolicies mocks base method.
+func (m *MockPolicyStore) UpdateFilteredPolicies(arg0, arg1 string, arg2 [][]string, arg3 int, arg4 ...string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateFilteredPolicies", varargs...)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFilteredPolicies indicates an expected call of UpdateFilteredPolicies.
+func (mr *MockPolicyStoreMockRecorder) UpdateFilteredPolicies(arg0, arg1, arg2, arg3 interface{}, arg4 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilteredPolicies", reflect.TypeOf((*MockPolicyStore)(nil).UpdateFilteredPolicies), varargs...)
+}
+
+// UpdatePolicies mocks base method.
+func (m *MockPolicyStore) UpdatePolicies(arg0, arg1 string, arg2, arg3 [][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePolicies", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePolicies indicates an expected call of UpdatePolicies.
+func (mr *MockPolicyStoreMockRecorder) UpdatePolicies(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePolicies", reflect.TypeOf((*MockPolicyStore)(nil).UpdatePolicies), arg0, arg1, arg2, arg3)
+}
+
+// UpdatePolicy mocks base method.
+func (m *MockPolicyStore) UpdatePolicy(arg0, arg1 string, arg2, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePolicy", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePolicy indicates an expected call of UpdatePolicy.
+func (mr *MockPolicyStoreMockRecorder) UpdatePolicy(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePolicy", reflect.TypeOf((*MockPolicyStore)(nil).UpdatePolicy), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,103 @@
+package pgadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DefaultModelTableName is the table holding the model configurations, see WithModelStorage.
+const DefaultModelTableName = "casbin_model"
+
+// storedModel is a version of a model configuration.
+type storedModel struct {
+	tableName struct{}  `pg:"casbin_model"`
+	Name      string    `pg:",pk"`
+	Version   int       `pg:",pk"`
+	Text      string    `pg:",notnull"`
+	CreatedAt time.Time `pg:"default:now(),notnull"`
+}
+
+// WithModelStorage creates the model table, so model configurations can be stored next to the policy,
+// see SaveModelText and NewEnforcerFromDB.
+func WithModelStorage() Option {
+	return func(a *Adapter) {
+		a.modelStorage = true
+	}
+}
+
+func (a *Adapter) createModelTable() error {
+	return a.conn().Model((*storedModel)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+}
+
+// SaveModelText stores text as a new version of the model configuration name and returns the version,
+// versions start at 1. It requires WithModelStorage.
+func (a *Adapter) SaveModelText(ctx context.Context, name string, text string) (version int, err error) {
+	defer a.handleError(OpSaveModelText, "", 0, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	m := &storedModel{Name: name, Text: text}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		// Serialize the writers of name so they don't compute the same version.
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext(?))", DefaultModelTableName+"."+name); err != nil {
+			return err
+		}
+		_, err := tx.QueryOneContext(ctx, pg.Scan(&m.Version),
+			"SELECT coalesce(max(version), 0) + 1 FROM ? WHERE name = ?", pg.Ident(DefaultModelTableName), name)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ModelContext(ctx, m).Insert()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return m.Version, nil
+}
+
+// LoadModelText returns the latest version of the model configuration name and its version.
+// It returns an error matching ErrNotFound if there is no such model.
+func (a *Adapter) LoadModelText(ctx context.Context, name string) (text string, version int, err error) {
+	return a.LoadModelTextVersion(ctx, name, 0)
+}
+
+// LoadModelTextVersion returns the given version of the model configuration name, 0 meaning the latest.
+func (a *Adapter) LoadModelTextVersion(ctx context.Context, name string, version int) (text string, _ int, err error) {
+	defer a.handleError(OpLoadModelText, "", 0, &err)
+
+	m := &storedModel{}
+	query := a.conn().ModelContext(ctx, m).Where("name = ?", name)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	} else {
+		query = query.Order("version DESC").Limit(1)
+	}
+	if err := query.Select(); err != nil {
+		return "", 0, err
+	}
+	return m.Text, m.Version, nil
+}
+
+// NewEnforcerFromDB creates an enforcer using the latest version of the model configuration name
+// stored with SaveModelText and the policy of a.
+func NewEnforcerFromDB(ctx context.Context, a *Adapter, name string) (*casbin.Enforcer, error) {
+	text, _, err := a.LoadModelText(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := model.NewModelFromString(text)
+	if err != nil {
+		return nil, err
+	}
+	return casbin.NewEnforcer(m, a)
+}
@@ -0,0 +1,38 @@
+package pgadapter
+
+import (
+	"context"
+	"os"
+)
+
+func (s *AdapterTestSuite) TestModelStorage() {
+	a, err := NewAdapterByDB(s.a.db, WithModelStorage())
+	s.Require().NoError(err)
+
+	ctx := context.Background()
+	_, _, err = a.LoadModelText(ctx, "rbac")
+	s.Require().ErrorIs(err, ErrNotFound)
+
+	text, err := os.ReadFile("examples/rbac_model.conf")
+	s.Require().NoError(err)
+	version, err := a.SaveModelText(ctx, "rbac", "outdated")
+	s.Require().NoError(err)
+	s.Require().Equal(1, version)
+	version, err = a.SaveModelText(ctx, "rbac", string(text))
+	s.Require().NoError(err)
+	s.Require().Equal(2, version)
+
+	loaded, version, err := a.LoadModelText(ctx, "rbac")
+	s.Require().NoError(err)
+	s.Require().Equal(string(text), loaded)
+	s.Require().Equal(2, version)
+	loaded, _, err = a.LoadModelTextVersion(ctx, "rbac", 1)
+	s.Require().NoError(err)
+	s.Require().Equal("outdated", loaded)
+
+	e, err := NewEnforcerFromDB(ctx, a, "rbac")
+	s.Require().NoError(err)
+	ok, err := e.Enforce("alice", "data2", "read")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+}
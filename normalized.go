@@ -0,0 +1,169 @@
+package pgadapter
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Normalization configures the normalized storage of the rules, see WithNormalizedStorage.
+type Normalization struct {
+	// Ptypes are the types of the rules stored normalized, "p" if empty.
+	Ptypes []string
+	// Subject, Object and Action are the entities referenced by the rules, they are the values 0, 1 and 2
+	// of the rules unless an Index is set.
+	Subject Entity
+	Object  Entity
+	Action  Entity
+}
+
+// Entity is a table referenced by the normalized rules, see Normalization.
+type Entity struct {
+	// Index is the position of the value of the entity in the rules.
+	Index int
+	// Table is an existing table holding the entities, e.g. "users". If empty, the adapter creates the table
+	// <table>_subjects, <table>_objects or <table>_actions and adds the entities of the stored rules to it.
+	Table string
+	// Column is the column of Table the values of the rules reference, "name" if empty.
+	// It must have a unique or primary key constraint.
+	Column string
+}
+
+// WithNormalizedStorage stores the rules of the ptypes of n in the <table>_permissions table, whose subject,
+// object and action columns are foreign keys to the tables of the entities, instead of the v0 to v5 columns.
+// The other values of the rules are kept in the v columns of the table, and the rules of the other ptypes,
+// e.g. the role assignments, in the rules table. With existing tables, e.g. the users and resources of an
+// application, rules referencing unknown entities can't be stored and entities referenced by rules can't be removed.
+// The adapter works on a view of both tables named <table>_mapped, whose writes are forwarded to the tables
+// by triggers. It can't be combined with the other options working on a view, like WithColumnMapping or
+// WithJSONBStorage.
+func WithNormalizedStorage(n Normalization) Option {
+	return func(a *Adapter) {
+		a.normalization = &n
+	}
+}
+
+// checkNormalization validates the normalization and sets its defaults.
+func (a *Adapter) checkNormalization() error {
+	if a.normalization == nil {
+		return nil
+	}
+	if len(a.columns) > 0 || a.softDelete || a.tenantScoped || a.partitioning != nil || a.surrogateKey != 0 || a.jsonb || a.generatedID {
+		return errors.New("WithNormalizedStorage can't be combined with WithColumnMapping, WithSoftDelete, " +
+			"WithTenant, WithPartitioning, WithSurrogateKey, WithJSONBStorage nor WithGeneratedID")
+	}
+
+	n := *a.normalization
+	if len(n.Ptypes) == 0 {
+		n.Ptypes = []string{"p"}
+	}
+	if n.Subject.Index == 0 && n.Object.Index == 0 && n.Action.Index == 0 {
+		n.Subject.Index, n.Object.Index, n.Action.Index = 0, 1, 2
+	}
+	entities := []*Entity{&n.Subject, &n.Object, &n.Action}
+	for i, e := range entities {
+		if e.Index < 0 || e.Index > 5 {
+			return errors.New("invalid normalization, the entities must be values 0 to 5 of the rules")
+		}
+		for _, other := range entities[:i] {
+			if e.Index == other.Index {
+				return errors.New("invalid normalization, the entities must be different values of the rules")
+			}
+		}
+		if e.Column == "" {
+			e.Column = "name"
+		}
+	}
+	a.normalization = &n
+	return nil
+}
+
+// normalizedSQL returns the statements creating the rules table, the tables of the normalized rules and
+// their view, the rules table is created by the first one.
+func (v ruleView) normalizedSQL() []string {
+	n := v.normalization
+	table := quoteQualified(v.table)
+	permissions := quoteQualified(v.table + "_permissions")
+	view := quoteQualified(v.name())
+	writeFunction := quoteQualified(v.table + "_write")
+	index := func(name string) string {
+		return quoteIdent(unqualified(v.table) + "_permissions_" + name + "_idx")
+	}
+
+	stmts := []string{createRulesTableSQL(v.table, nil, 0)}
+	// The column of each value of the normalized rules, and the statements adding the entities of a new rule.
+	columns := []string{"v0", "v1", "v2", "v3", "v4", "v5"}
+	defs := []string{"id text", "ptype text"}
+	var addEntities []string
+	for _, e := range []struct {
+		Entity
+		name string
+	}{{n.Subject, "subject"}, {n.Object, "object"}, {n.Action, "action"}} {
+		entityTable := quoteQualified(e.Table)
+		if e.Table == "" {
+			entityTable = quoteQualified(v.table + "_" + e.name + "s")
+			stmts = append(stmts, "CREATE TABLE IF NOT EXISTS "+entityTable+" ("+quoteIdent(e.Column)+" text PRIMARY KEY)")
+			addEntities = append(addEntities, "\tINSERT INTO "+entityTable+" ("+quoteIdent(e.Column)+") SELECT NEW.v"+strconv.Itoa(e.Index)+
+				" WHERE NEW.v"+strconv.Itoa(e.Index)+" IS NOT NULL ON CONFLICT DO NOTHING;\n")
+		}
+		columns[e.Index] = e.name
+		defs = append(defs, e.name+" text REFERENCES "+entityTable+" ("+quoteIdent(e.Column)+")")
+	}
+	values := make([]string, 0, len(columns))
+	newValues := make([]string, 0, len(columns))
+	for i, column := range columns {
+		if column == "v"+strconv.Itoa(i) {
+			defs = append(defs, column+" text")
+		}
+		values = append(values, column+" AS v"+strconv.Itoa(i))
+		newValues = append(newValues, "NEW.v"+strconv.Itoa(i))
+	}
+	defs = append(defs, "PRIMARY KEY (id)")
+
+	ptypes := make([]string, 0, len(n.Ptypes))
+	for _, ptype := range n.Ptypes {
+		ptypes = append(ptypes, quoteLiteral(ptype))
+	}
+	// An update fails on a stored rule, instead of being skipped like an insert.
+	insert := func(indent, onConflict string) string {
+		var adds string
+		for _, add := range addEntities {
+			adds += indent + add
+		}
+		return indent + "IF NEW.ptype IN (" + strings.Join(ptypes, ", ") + ") THEN\n" + adds +
+			indent + "\tINSERT INTO " + permissions + " (id, ptype, " + strings.Join(columns, ", ") + ") VALUES (NEW.id, NEW.ptype, " +
+			strings.Join(newValues, ", ") + ")" + onConflict + ";\n" +
+			indent + "ELSE\n" +
+			indent + "\tINSERT INTO " + table + " (" + strings.Join(ruleColumns, ", ") + ") VALUES (NEW.id, NEW.ptype, " +
+			strings.Join(newValues, ", ") + ")" + onConflict + ";\n" +
+			indent + "END IF;\n"
+	}
+
+	return append(stmts,
+		"CREATE TABLE IF NOT EXISTS "+permissions+" ("+strings.Join(defs, ", ")+")",
+		"CREATE INDEX IF NOT EXISTS "+index("subject")+" ON "+permissions+" (subject)",
+		"CREATE INDEX IF NOT EXISTS "+index("object")+" ON "+permissions+" (object)",
+		"CREATE INDEX IF NOT EXISTS "+index("action")+" ON "+permissions+" (action)",
+		"CREATE OR REPLACE VIEW "+view+" AS SELECT "+strings.Join(ruleColumns, ", ")+" FROM "+table+
+			" UNION ALL SELECT id, ptype, "+strings.Join(values, ", ")+" FROM "+permissions,
+		// Returning NULL skips the row like ON CONFLICT DO NOTHING, so it isn't counted as affected.
+		"CREATE OR REPLACE FUNCTION "+writeFunction+"() RETURNS trigger AS $$\n"+
+			"BEGIN\n"+
+			"\tIF TG_OP IN ('DELETE', 'UPDATE') THEN\n"+
+			"\t\tDELETE FROM "+table+" WHERE id = OLD.id;\n"+
+			"\t\tIF NOT FOUND THEN\n"+
+			"\t\t\tDELETE FROM "+permissions+" WHERE id = OLD.id;\n"+
+			"\t\t\tIF NOT FOUND THEN RETURN NULL; END IF;\n"+
+			"\t\tEND IF;\n"+
+			"\t\tIF TG_OP = 'DELETE' THEN RETURN OLD; END IF;\n"+
+			insert("\t\t", "")+
+			"\t\tRETURN NEW;\n"+
+			"\tEND IF;\n"+
+			insert("\t", " ON CONFLICT DO NOTHING")+
+			"\tIF NOT FOUND THEN RETURN NULL; END IF;\n"+
+			"\tRETURN NEW;\n"+
+			"END $$ LANGUAGE plpgsql",
+		"DROP TRIGGER IF EXISTS write ON "+view,
+		"CREATE TRIGGER write INSTEAD OF INSERT OR UPDATE OR DELETE ON "+view+" FOR EACH ROW EXECUTE PROCEDURE "+writeFunction+"()",
+	)
+}
@@ -0,0 +1,78 @@
+package pgadapter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-pg/pg/v10"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizedSQL(t *testing.T) {
+	a := &Adapter{tableName: "casbin_rule"}
+	WithNormalizedStorage(Normalization{Subject: Entity{Table: "users", Column: "login"}})(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "casbin_rule_mapped", a.tableName)
+	require.Equal(t, []string{"p"}, a.normalization.Ptypes)
+	require.Equal(t, 2, a.normalization.Action.Index)
+
+	stmts := a.ruleView().sql()
+	require.Equal(t, createRulesTableSQL("casbin_rule", nil, 0), stmts[0])
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS "casbin_rule_objects" ("name" text PRIMARY KEY)`, stmts[1])
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS "casbin_rule_actions" ("name" text PRIMARY KEY)`, stmts[2])
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS "casbin_rule_permissions" (id text, ptype text, `+
+		`subject text REFERENCES "users" ("login"), object text REFERENCES "casbin_rule_objects" ("name"), `+
+		`action text REFERENCES "casbin_rule_actions" ("name"), v3 text, v4 text, v5 text, PRIMARY KEY (id))`, stmts[3])
+	view := stmts[7]
+	require.True(t, strings.HasSuffix(view, `UNION ALL SELECT id, ptype, subject AS v0, object AS v1, action AS v2, v3 AS v3, v4 AS v4, v5 AS v5 FROM "casbin_rule_permissions"`), view)
+
+	a = &Adapter{tableName: "casbin_rule"}
+	WithNormalizedStorage(Normalization{Subject: Entity{Index: 1}, Object: Entity{Index: 1}})(a)
+	require.Error(t, a.mapColumns())
+
+	a = &Adapter{tableName: "casbin_rule"}
+	WithNormalizedStorage(Normalization{})(a)
+	WithJSONBStorage()(a)
+	require.Error(t, a.mapColumns())
+}
+
+func (s *AdapterTestSuite) TestNormalizedStorage() {
+	_, err := s.a.db.Exec("CREATE TABLE users (login text PRIMARY KEY)")
+	s.Require().NoError(err)
+	_, err = s.a.db.Exec("INSERT INTO users VALUES ('alice'), ('bob'), ('data2_admin')")
+	s.Require().NoError(err)
+
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_norm"),
+		WithNormalizedStorage(Normalization{Subject: Entity{Table: "users", Column: "login"}}))
+	s.Require().NoError(err)
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	s.Require().NoError(err)
+	s.Require().NoError(a.SavePolicy(e.GetModel()))
+
+	var objects []string
+	_, err = s.a.db.Query(pg.Array(&objects), "SELECT array_agg(name ORDER BY name) FROM casbin_rule_norm_objects")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"data1", "data2"}, objects)
+	var roles int
+	_, err = s.a.db.QueryOne(pg.Scan(&roles), "SELECT count(*) FROM casbin_rule_norm WHERE ptype = 'g'")
+	s.Require().NoError(err)
+	s.Require().Equal(1, roles, "the role assignments are stored in the rules table")
+
+	e, err = casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.assertPolicy(
+		[][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}},
+		e.GetPolicy(),
+	)
+
+	s.Require().Error(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}), "carol is not a user")
+	_, err = s.a.db.Exec("DELETE FROM users WHERE login = 'alice'")
+	s.Require().Error(err, "alice is referenced by a rule")
+
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data3", "read"}))
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data3", "read"}))
+	_, err = s.a.db.Exec("DELETE FROM users WHERE login = 'alice'")
+	s.Require().NoError(err)
+}
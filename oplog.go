@@ -0,0 +1,143 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DefaultOperationLogTableName is the table holding the operation log, see WithOperationLog.
+const DefaultOperationLogTableName = "casbin_operation_log"
+
+// replayBatchSize is the number of operations read at once by ReplayOperations.
+const replayBatchSize = 1000
+
+// LoggedOperation is an entry of the operation log.
+type LoggedOperation struct {
+	tableName struct{}     `pg:"casbin_operation_log"`
+	ID        int64        `pg:",pk"`
+	RuleTable string       `pg:",notnull"`
+	Change    PolicyChange `pg:"type:jsonb,notnull"`
+	CreatedAt time.Time    `pg:"default:now(),notnull"`
+}
+
+// WithOperationLog creates the operation log table and makes every write append its PolicyChange to it
+// within the transaction of the write, so the log holds exactly the committed changes in commit order per table:
+// the appends to the log of a table are serialized with an advisory lock held until the write commits,
+// so an entry is never committed with a lower ID than an entry already visible to the readers.
+// The log can be read with ReadOperations or applied to a casbin.DistributedEnforcer with ReplayOperations,
+// which lets clusters ordering their writes with a consensus layer persist and replay the policy through Postgres.
+// Entries are never deleted by the adapter.
+func WithOperationLog() Option {
+	return func(a *Adapter) {
+		a.operationLog = true
+	}
+}
+
+func (a *Adapter) createOperationLogTable() error {
+	err := a.conn().Model((*LoggedOperation)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? (rule_table, id)",
+		pg.Ident(DefaultOperationLogTableName+"_rule_table_idx"), pg.Ident(DefaultOperationLogTableName))
+	return err
+}
+
+// recordChange appends change to the operation log and the audit log within tx and bumps the version of the table,
+// every write calls it last in its transaction.
+func (a *Adapter) recordChange(ctx context.Context, tx *pg.Tx, change PolicyChange) error {
+	change.Actor = a.actorOf(ctx)
+	if a.operationLog {
+		// The IDs are allocated in insertion order, the lock makes it the commit order.
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext(?))", DefaultOperationLogTableName+"."+a.tableName); err != nil {
+			return err
+		}
+		_, err := tx.ModelContext(ctx, &LoggedOperation{RuleTable: a.tableName, Change: change}).Insert()
+		if err != nil {
+			return err
+		}
+	}
+	if err := a.audit(tx, change); err != nil {
+		return err
+	}
+	if err := a.notify(tx, change); err != nil {
+		return err
+	}
+	return a.bumpVersion(tx)
+}
+
+// ReadOperations returns at most limit entries of the operation log of the table with an ID greater than afterID,
+// ordered by ID. It requires WithOperationLog.
+func (a *Adapter) ReadOperations(ctx context.Context, afterID int64, limit int) (_ []LoggedOperation, err error) {
+	defer a.handleError(OpReadOperations, "", 0, &err)
+
+	if !a.operationLog {
+		return nil, errors.New("the operation log is not enabled, see WithOperationLog")
+	}
+
+	var ops []LoggedOperation
+	err = a.conn().ModelContext(ctx, &ops).
+		Where("rule_table = ?", a.tableName).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// ReplayOperations applies the entries of the operation log with an ID greater than afterID to e
+// with the *Self methods of the DistributedEnforcer, without writing them back, and returns the ID of the last
+// applied entry, or afterID if there is none. Operations that can't be applied incrementally, like SavePolicy,
+// make e reload the policy. It requires WithOperationLog.
+func (a *Adapter) ReplayOperations(ctx context.Context, e casbin.IDistributedEnforcer, afterID int64) (lastID int64, err error) {
+	lastID = afterID
+	for {
+		ops, err := a.ReadOperations(ctx, lastID, replayBatchSize)
+		if err != nil {
+			return lastID, err
+		}
+		for _, op := range ops {
+			if err := replayChange(e, op.Change); err != nil {
+				wrapError(OpReplayOperations, &err)
+				return lastID, err
+			}
+			lastID = op.ID
+		}
+		if len(ops) < replayBatchSize {
+			return lastID, nil
+		}
+	}
+}
+
+func replayChange(e casbin.IDistributedEnforcer, change PolicyChange) error {
+	var err error
+	switch change.Operation {
+	case OpAddPolicy, OpAddPolicies:
+		_, err = e.AddPoliciesSelf(nil, change.Sec, change.Ptype, change.Rules)
+	case OpRemovePolicy, OpRemovePolicies:
+		_, err = e.RemovePoliciesSelf(nil, change.Sec, change.Ptype, change.Rules)
+	case OpRemoveFilteredPolicy:
+		_, err = e.RemovePoliciesSelf(nil, change.Sec, change.Ptype, change.Removed)
+	case OpUpdatePolicy, OpUpdatePolicies:
+		_, err = e.UpdatePoliciesSelf(nil, change.Sec, change.Ptype, change.OldRules, change.Rules)
+	case OpUpdateFilteredPolicies:
+		if _, err = e.RemovePoliciesSelf(nil, change.Sec, change.Ptype, change.OldRules); err == nil {
+			_, err = e.AddPoliciesSelf(nil, change.Sec, change.Ptype, change.Rules)
+		}
+	case OpSetPolicyLabels:
+		// Labels don't change the policy.
+	default:
+		err = e.LoadPolicy()
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package pgadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestReplayOperations() {
+	a, err := NewAdapterByDB(s.a.db, WithOperationLog(), WithActor("node-1"))
+	s.Require().NoError(err)
+
+	replica, err := casbin.NewDistributedEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"bob", "data2", "write"}, []string{"bob", "data3", "write"}))
+	_, err = a.RemoveFilteredPolicyReturning("p", "p", 0, "alice")
+	s.Require().NoError(err)
+
+	ops, err := a.ReadOperations(context.Background(), 0, 10)
+	s.Require().NoError(err)
+	s.Require().Len(ops, 3)
+	s.Require().Equal(OpAddPolicy, ops[0].Change.Operation)
+	s.Require().Equal("node-1", ops[0].Change.Actor)
+	s.Require().Equal([][]string{{"alice", "data1", "read"}}, ops[2].Change.Removed)
+
+	lastID, err := a.ReplayOperations(context.Background(), replica, 0)
+	s.Require().NoError(err)
+	s.Require().Equal(ops[2].ID, lastID)
+	s.Require().ElementsMatch([][]string{
+		{"carol", "data1", "read"},
+		{"bob", "data3", "write"},
+		{"data2_admin", "data2", "read"},
+		{"data2_admin", "data2", "write"},
+	}, replica.GetPolicy())
+
+	lastID, err = a.ReplayOperations(context.Background(), replica, lastID)
+	s.Require().NoError(err)
+	s.Require().Equal(ops[2].ID, lastID)
+
+	_, err = s.a.ReadOperations(context.Background(), 0, 10)
+	s.Require().Error(err)
+}
+
+func (s *AdapterTestSuite) TestOperationLogCommitOrder() {
+	a, err := NewAdapterByDB(s.a.db, WithOperationLog())
+	s.Require().NoError(err)
+
+	tx, err := s.a.db.Begin()
+	s.Require().NoError(err)
+	defer tx.Close()
+	s.Require().NoError(a.WithTx(tx).AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+
+	// The second write gets the next ID, it must not be visible before the first one.
+	done := make(chan error, 1)
+	go func() { done <- a.AddPolicy("p", "p", []string{"dave", "data1", "read"}) }()
+	select {
+	case err := <-done:
+		s.Failf("the write committed before the pending one", "%v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	ops, err := a.ReadOperations(context.Background(), 0, 10)
+	s.Require().NoError(err)
+	s.Require().Empty(ops)
+
+	s.Require().NoError(tx.Commit())
+	s.Require().NoError(<-done)
+	ops, err = a.ReadOperations(context.Background(), 0, 10)
+	s.Require().NoError(err)
+	s.Require().Len(ops, 2)
+	s.Require().Equal([][]string{{"carol", "data1", "read"}}, ops[0].Change.Rules)
+	s.Require().Equal([][]string{{"dave", "data1", "read"}}, ops[1].Change.Rules)
+}
@@ -0,0 +1,99 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// WithRuleOrder adds a seq column to the rules table recording the insertion order of the rules,
+// and loads the rules in that order. Models using priority(p.eft) depend on the order of the rules,
+// which the hash keyed table doesn't preserve otherwise. SavePolicy stores the rules in the order of the model,
+// ReorderPolicies changes the order of stored rules.
+func WithRuleOrder() Option {
+	return func(a *Adapter) {
+		a.ruleOrder = true
+	}
+}
+
+func (a *Adapter) createSeqColumn() error {
+	_, err := a.conn().Exec("ALTER TABLE ? ADD COLUMN IF NOT EXISTS seq bigserial", pg.Ident(a.tableName))
+	return err
+}
+
+// ordered orders query by seq if WithRuleOrder is set.
+func (a *Adapter) ordered(query *orm.Query) *orm.Query {
+	if a.ruleOrder {
+		return query.Order("seq")
+	}
+	return query
+}
+
+// ReorderPolicies changes the order of the given rules of ptype to the order of rules,
+// the rules keep the positions they occupy together, e.g. reordering the first and the last rule swaps them.
+// It requires WithRuleOrder. Rules that aren't stored are ignored.
+func (a *Adapter) ReorderPolicies(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	op := &Operation{Name: OpReorderPolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	return a.doContext(ctx, op, func(ctx context.Context) error {
+		return a.reorderPolicies(ctx, sec, ptype, rules)
+	})
+}
+
+func (a *Adapter) reorderPolicies(ctx context.Context, sec string, ptype string, rules [][]string) (err error) {
+	defer a.handleError(OpReorderPolicies, ptype, len(rules), &err)
+
+	if !a.ruleOrder {
+		return errors.New("rule order is not enabled, see WithRuleOrder")
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, a.ruleID(ptype, rule))
+	}
+
+	change := PolicyChange{Operation: OpReorderPolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		var slots []struct {
+			ID  string
+			Seq int64
+		}
+		_, err := tx.QueryContext(ctx, &slots, "SELECT id, seq FROM ? WHERE id IN (?) FOR UPDATE",
+			pg.Ident(a.tableName), pg.In(ids))
+		if err != nil {
+			return err
+		}
+		stored := make(map[string]bool, len(slots))
+		seqs := make([]int64, 0, len(slots))
+		for _, slot := range slots {
+			stored[slot.ID] = true
+			seqs = append(seqs, slot.Seq)
+		}
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+		i := 0
+		for _, id := range ids {
+			if !stored[id] {
+				continue
+			}
+			delete(stored, id)
+			_, err := tx.ExecContext(ctx, "UPDATE ? SET seq = ? WHERE id = ?", pg.Ident(a.tableName), seqs[i], id)
+			if err != nil {
+				return err
+			}
+			i++
+		}
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.changed(ctx, change)
+	return nil
+}
@@ -0,0 +1,46 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestRuleOrder() {
+	a, err := NewAdapterByDB(s.a.db, WithRuleOrder())
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	_, err = e.AddPolicy("carol", "data3", "read")
+	s.Require().NoError(err)
+	_, err = e.AddPolicy("alice", "data3", "read")
+	s.Require().NoError(err)
+
+	s.Require().NoError(e.LoadPolicy())
+	policy := e.GetPolicy()
+	s.Require().Equal([][]string{{"carol", "data3", "read"}, {"alice", "data3", "read"}}, policy[len(policy)-2:])
+
+	err = a.ReorderPolicies(context.Background(), "p", "p", [][]string{{"alice", "data3", "read"}, {"carol", "data3", "read"}})
+	s.Require().NoError(err)
+	s.Require().NoError(e.LoadPolicy())
+	policy = e.GetPolicy()
+	s.Require().Equal([][]string{{"alice", "data3", "read"}, {"carol", "data3", "read"}}, policy[len(policy)-2:])
+
+	// SavePolicy stores the order of the model, including with SaveShadowSwap.
+	b, err := NewAdapterByDB(s.a.db, WithRuleOrder(), WithSaveStrategy(SaveShadowSwap))
+	s.Require().NoError(err)
+	e.SetAdapter(b)
+	e.ClearPolicy()
+	e.GetModel().AddPolicy("p", "p", []string{"zoe", "data1", "read"})
+	e.GetModel().AddPolicy("p", "p", []string{"adam", "data1", "read"})
+	s.Require().NoError(e.SavePolicy())
+	s.Require().NoError(e.SavePolicy())
+	s.Require().NoError(e.LoadPolicy())
+	s.Require().Equal([][]string{{"zoe", "data1", "read"}, {"adam", "data1", "read"}}, e.GetPolicy())
+}
+
+func (s *AdapterTestSuite) TestReorderPoliciesRequiresRuleOrder() {
+	err := s.a.ReorderPolicies(context.Background(), "p", "p", [][]string{{"alice", "data1", "read"}})
+	s.Require().Error(err)
+}
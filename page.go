@@ -0,0 +1,45 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+)
+
+// PolicyPage is a page of rules, see ListPoliciesPage.
+type PolicyPage struct {
+	Rules [][]string
+	// Next is the cursor of the next page, empty on the last page.
+	Next string
+}
+
+// ListPoliciesPage returns at most limit rules of ptype following cursor, which is empty for the first page
+// and the Next field of the previous page otherwise, without loading the whole policy, e.g. for admin UIs paging
+// through large policies. The rules are ordered by ID, which keeps the pages stable while rules are added
+// or removed, the rules added behind the cursor show up on the next pages.
+func (a *Adapter) ListPoliciesPage(ctx context.Context, ptype string, cursor string, limit int) (_ *PolicyPage, err error) {
+	defer a.handleError(OpListPoliciesPage, ptype, 0, &err)
+
+	if limit <= 0 {
+		return nil, errors.New("the page limit must be positive")
+	}
+
+	var lines []*CasbinRule
+	query := a.conn().ModelContext(ctx, &lines).Table(a.tableName).Where("ptype = ?", ptype)
+	if cursor != "" {
+		query = query.Where("id > ?", cursor)
+	}
+	// One more rule is read to know whether there is a next page.
+	if err := query.Order("id").Limit(limit + 1).Select(); err != nil {
+		return nil, err
+	}
+
+	page := &PolicyPage{Rules: make([][]string, 0, limit)}
+	if len(lines) > limit {
+		lines = lines[:limit]
+		page.Next = lines[limit-1].ID
+	}
+	for _, line := range lines {
+		page.Rules = append(page.Rules, line.rule())
+	}
+	return page, nil
+}
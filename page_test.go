@@ -0,0 +1,34 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+)
+
+func (s *AdapterTestSuite) TestListPoliciesPage() {
+	ctx := context.Background()
+	rules := make([][]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		rules = append(rules, []string{fmt.Sprintf("user%d", i), "data1", "read"})
+	}
+	s.Require().NoError(s.a.AddPolicies("p", "p", rules))
+
+	var listed [][]string
+	pages := 0
+	cursor := ""
+	for {
+		page, err := s.a.ListPoliciesPage(ctx, "p", cursor, 4)
+		s.Require().NoError(err)
+		listed = append(listed, page.Rules...)
+		pages++
+		if page.Next == "" {
+			break
+		}
+		cursor = page.Next
+	}
+	s.Require().Equal(4, pages)
+	s.Require().ElementsMatch(append(rules, s.e.GetPolicy()...), listed)
+
+	_, err := s.a.ListPoliciesPage(ctx, "p", "", 0)
+	s.Require().Error(err)
+}
@@ -0,0 +1,83 @@
+package pgadapter
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Partitioning describes how the rules table is partitioned, see WithPartitioning.
+// Exactly one of its fields must be set.
+type Partitioning struct {
+	// ByPtype lists the ptypes getting their own partition of a table partitioned by LIST on ptype,
+	// e.g. {"p", "g"}, the rules of the other ptypes are stored in a default partition.
+	ByPtype []string
+	// TenantHashPartitions is the number of partitions of a table partitioned by HASH on tenant_id,
+	// it requires WithTenant.
+	TenantHashPartitions int
+}
+
+// WithPartitioning creates the rules table as a partitioned table, for tables too large to be scanned as a whole.
+// The partition key becomes part of the primary key. Postgres routes the rows to their partition and prunes
+// the partitions from the queries filtering on the key, e.g. the filtered loads on ptype and the views of WithTenant
+// on tenant_id. Existing tables are left as they are, the option only applies to the tables the adapter creates.
+func WithPartitioning(p Partitioning) Option {
+	return func(a *Adapter) {
+		a.partitioning = &p
+	}
+}
+
+func (a *Adapter) checkPartitioning() error {
+	p := a.partitioning
+	if p == nil {
+		return nil
+	}
+	switch {
+	case len(p.ByPtype) > 0 && p.TenantHashPartitions > 0, len(p.ByPtype) == 0 && p.TenantHashPartitions <= 0:
+		return errors.New("exactly one of ByPtype and TenantHashPartitions must be set")
+	case p.TenantHashPartitions > 0 && !a.tenantScoped:
+		return errors.New("tenant partitioning requires WithTenant")
+	}
+	return nil
+}
+
+// partitionedTableSQL returns the statements creating the partitioned rules table with the mapped column names
+// and its partitions, the table is created by the first one. The table has the tenant_id column if tenant is set.
+func partitionedTableSQL(table string, columns map[string]string, p *Partitioning, tenant bool) []string {
+	defs := make([]string, 0, len(ruleColumns)+2)
+	for _, column := range ruleColumns {
+		defs = append(defs, quoteIdent(mappedColumn(columns, column))+" text")
+	}
+	id, ptype := quoteIdent(mappedColumn(columns, "id")), quoteIdent(mappedColumn(columns, "ptype"))
+
+	var key []string
+	if tenant {
+		defs = append(defs, tenantColumnDef)
+		key = append(key, "tenant_id")
+	}
+	var partitionBy string
+	if p.TenantHashPartitions > 0 {
+		partitionBy = "HASH (tenant_id)"
+	} else {
+		key = append(key, ptype)
+		partitionBy = "LIST (" + ptype + ")"
+	}
+	defs = append(defs, "PRIMARY KEY ("+strings.Join(append(key, id), ", ")+")")
+
+	stmts := []string{"CREATE TABLE IF NOT EXISTS " + quoteQualified(table) + " (" + strings.Join(defs, ", ") + ")" +
+		" PARTITION BY " + partitionBy}
+	partition := func(suffix, bound string) {
+		stmts = append(stmts, "CREATE TABLE IF NOT EXISTS "+quoteQualified(table+"_"+suffix)+
+			" PARTITION OF "+quoteQualified(table)+" "+bound)
+	}
+	for i := 0; i < p.TenantHashPartitions; i++ {
+		partition("h"+strconv.Itoa(i), "FOR VALUES WITH (MODULUS "+strconv.Itoa(p.TenantHashPartitions)+", REMAINDER "+strconv.Itoa(i)+")")
+	}
+	for _, pt := range p.ByPtype {
+		partition(pt, "FOR VALUES IN ("+quoteLiteral(pt)+")")
+	}
+	if len(p.ByPtype) > 0 {
+		partition("default", "DEFAULT")
+	}
+	return stmts
+}
@@ -0,0 +1,54 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionedTableSQL(t *testing.T) {
+	stmts := partitionedTableSQL("rules", nil, &Partitioning{ByPtype: []string{"p", "g"}}, false)
+	require.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS "rules" ("id" text, "ptype" text, "v0" text, "v1" text, "v2" text, "v3" text, "v4" text, "v5" text, PRIMARY KEY ("ptype", "id")) PARTITION BY LIST ("ptype")`,
+		`CREATE TABLE IF NOT EXISTS "rules_p" PARTITION OF "rules" FOR VALUES IN ('p')`,
+		`CREATE TABLE IF NOT EXISTS "rules_g" PARTITION OF "rules" FOR VALUES IN ('g')`,
+		`CREATE TABLE IF NOT EXISTS "rules_default" PARTITION OF "rules" DEFAULT`,
+	}, stmts)
+
+	stmts = partitionedTableSQL("rules", nil, &Partitioning{TenantHashPartitions: 2}, true)
+	require.Len(t, stmts, 3)
+	require.Contains(t, stmts[0], `PRIMARY KEY (tenant_id, "id")) PARTITION BY HASH (tenant_id)`)
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS "rules_h1" PARTITION OF "rules" FOR VALUES WITH (MODULUS 2, REMAINDER 1)`, stmts[2])
+
+	a := &Adapter{}
+	WithPartitioning(Partitioning{TenantHashPartitions: 4})(a)
+	require.Error(t, a.checkPartitioning())
+	WithTenant("acme")(a)
+	require.NoError(t, a.checkPartitioning())
+	WithPartitioning(Partitioning{})(a)
+	require.Error(t, a.checkPartitioning())
+}
+
+func (s *AdapterTestSuite) TestPartitioning() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_partitioned"), WithPartitioning(Partitioning{ByPtype: []string{"p", "g"}}))
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	_, err = e.AddPolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+	_, err = e.AddGroupingPolicy("alice", "data2_admin")
+	s.Require().NoError(err)
+	_, err = e.UpdatePolicy([]string{"alice", "data1", "read"}, []string{"alice", "data1", "write"})
+	s.Require().NoError(err)
+
+	var count int
+	_, err = s.a.db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM casbin_rule_partitioned_g")
+	s.Require().NoError(err)
+	s.Require().Equal(1, count)
+
+	s.Require().NoError(e.LoadPolicy())
+	s.assertPolicy([][]string{{"alice", "data1", "write"}}, e.GetPolicy())
+}
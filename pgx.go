@@ -0,0 +1,63 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewAdapterByPgxPool creates an adapter running on a pgx v5 connection pool instead of go-pg,
+// for applications that already use pgx. The pool is shared: closing the adapter leaves it open.
+// See SQLAdapter for the supported options.
+func NewAdapterByPgxPool(pool *pgxpool.Pool, opts ...Option) (*SQLAdapter, error) {
+	return newSQLAdapter(pgxPool{pgxConn: pgxConn{q: pool}, pool: pool}, opts...)
+}
+
+// pgxQuerier is implemented by pgxpool.Pool and pgx.Tx.
+type pgxQuerier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+type pgxConn struct {
+	q pgxQuerier
+}
+
+func (c pgxConn) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := c.q.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (c pgxConn) queryRules(ctx context.Context, query string, args ...interface{}) ([]*CasbinRule, error) {
+	rows, err := c.q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*CasbinRule
+	for rows.Next() {
+		line := &CasbinRule{}
+		if err := rows.Scan(&line.ID, &line.Ptype, &line.V0, &line.V1, &line.V2, &line.V3, &line.V4, &line.V5); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+type pgxPool struct {
+	pgxConn
+	pool *pgxpool.Pool
+}
+
+func (p pgxPool) inTx(ctx context.Context, fn func(sqlConn) error) error {
+	return pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		return fn(pgxConn{q: tx})
+	})
+}
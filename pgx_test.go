@@ -0,0 +1,47 @@
+package pgadapter
+
+import (
+	"context"
+	"os"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func (s *AdapterTestSuite) TestPgxPool() {
+	cfg, err := pgxpool.ParseConfig(os.Getenv("PG_CONN"))
+	s.Require().NoError(err)
+	cfg.ConnConfig.Database = s.a.db.Options().Database
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	s.Require().NoError(err)
+	defer pool.Close()
+
+	a, err := NewAdapterByPgxPool(pool)
+	s.Require().NoError(err)
+	defer a.Close()
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.assertPolicy(s.e.GetPolicy(), e.GetPolicy())
+
+	_, err = e.AddPolicy("carol", "data1", "read")
+	s.Require().NoError(err)
+	ok, err := s.a.HasPolicy(context.Background(), "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok, "the rules are stored like go-pg stores them")
+
+	_, err = e.UpdatePolicy([]string{"carol", "data1", "read"}, []string{"carol", "data1", "write"})
+	s.Require().NoError(err)
+	_, err = e.RemoveFilteredPolicy(0, "bob")
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy(
+		[][]string{{"alice", "data1", "read"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data1", "write"}},
+		s.e.GetPolicy(),
+	)
+
+	s.Require().NoError(e.LoadFilteredPolicy(&Filter{P: []string{"alice"}}))
+	s.Require().True(e.IsFiltered())
+	s.assertPolicy([][]string{{"alice", "data1", "read"}}, e.GetPolicy())
+}
@@ -0,0 +1,68 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2/model"
+)
+
+// SavePlan lists the rules SavePolicy would insert and delete, each rule starts with its ptype.
+type SavePlan struct {
+	Inserts [][]string
+	Deletes [][]string
+}
+
+// SavePolicyPlan returns the rules SavePolicy would insert and delete to store the rules of model
+// with the SaveStrategy of the adapter, without changing anything, e.g. to have the exact change approved
+// before applying it. With SaveShadowSwap or WithRuleOrder, SavePolicy replaces the whole table, so all the stored
+// rules are deleted and all the rules of model inserted. The BeforeWriteHooks and RuleValidators are called
+// like by SavePolicy.
+// The table may change between the plan and the save, see SaveFailIfChanged.
+func (a *Adapter) SavePolicyPlan(ctx context.Context, model model.Model) (*SavePlan, error) {
+	var plan *SavePlan
+	err := a.doContext(ctx, &Operation{Name: OpSavePolicyPlan}, func(ctx context.Context) error {
+		var err error
+		plan, err = a.savePolicyPlan(ctx, model)
+		return err
+	})
+	return plan, err
+}
+
+func (a *Adapter) savePolicyPlan(ctx context.Context, model model.Model) (_ *SavePlan, err error) {
+	defer a.handleError(OpSavePolicyPlan, "", 0, &err)
+
+	var want []*CasbinRule
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range model[sec] {
+			if err := a.beforeWrite(OpSavePolicyPlan, sec, ptype, ast.Policy); err != nil {
+				return nil, err
+			}
+			for _, rule := range ast.Policy {
+				want = append(want, a.ruleLine(ptype, rule))
+			}
+		}
+	}
+	sortRules(want)
+	want = uniqueRules(want)
+
+	have, err := a.canonicalRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if a.saveStrategy == SaveShadowSwap || a.saveStrategy != SaveMergeUnion && a.ruleOrder {
+		plan := &SavePlan{}
+		for _, line := range want {
+			plan.Inserts = append(plan.Inserts, line.ptypeRule())
+		}
+		for _, line := range have {
+			plan.Deletes = append(plan.Deletes, line.ptypeRule())
+		}
+		return plan, nil
+	}
+	d := diffRules(want, have)
+	plan := &SavePlan{Inserts: d.OnlyHere}
+	if a.saveStrategy != SaveMergeUnion {
+		plan.Deletes = d.OnlyThere
+	}
+	return plan, nil
+}
@@ -0,0 +1,44 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func (s *AdapterTestSuite) TestSavePolicyPlan() {
+	ctx := context.Background()
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	s.Require().NoError(err)
+	_, err = e.RemovePolicy("bob", "data2", "write")
+	s.Require().NoError(err)
+	_, err = e.AddGroupingPolicy("carol", "data2_admin")
+	s.Require().NoError(err)
+
+	plan, err := s.a.SavePolicyPlan(ctx, e.GetModel())
+	s.Require().NoError(err)
+	s.Require().Equal(&SavePlan{
+		Inserts: [][]string{{"g", "carol", "data2_admin"}},
+		Deletes: [][]string{{"p", "bob", "data2", "write"}},
+	}, plan)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Require().True(s.e.HasPolicy("bob", "data2", "write"), "the plan changes nothing")
+
+	merge, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveMergeUnion))
+	s.Require().NoError(err)
+	plan, err = merge.SavePolicyPlan(ctx, e.GetModel())
+	s.Require().NoError(err)
+	s.Require().Empty(plan.Deletes)
+	s.Require().Len(plan.Inserts, 1)
+
+	// The shadow swap replaces the whole table.
+	swap, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveShadowSwap))
+	s.Require().NoError(err)
+	plan, err = swap.SavePolicyPlan(ctx, e.GetModel())
+	s.Require().NoError(err)
+	s.Require().Len(plan.Deletes, 5)
+	s.Require().Len(plan.Inserts, 5)
+	s.Require().Contains(plan.Deletes, []string{"p", "alice", "data1", "read"})
+	s.Require().Contains(plan.Inserts, []string{"p", "alice", "data1", "read"})
+}
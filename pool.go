@@ -0,0 +1,105 @@
+package pgadapter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// PoolOptions tunes the go-pg connection pool opened by the adapters created from a URL or *pg.Options,
+// the zero fields keep the go-pg defaults.
+type PoolOptions struct {
+	// PoolSize is the maximum number of connections.
+	PoolSize int
+	// MinIdleConns is the number of idle connections kept open.
+	MinIdleConns int
+	// MaxConnAge is the age at which connections are closed.
+	MaxConnAge time.Duration
+	// PoolTimeout is how long to wait for a free connection.
+	PoolTimeout time.Duration
+	// IdleTimeout is how long a connection stays idle before being closed.
+	IdleTimeout time.Duration
+}
+
+// poolURLParams are the query parameters of the connection URL setting the PoolOptions fields.
+var poolURLParams = []string{"pool_size", "min_idle_conns", "max_conn_age", "pool_timeout", "idle_timeout"}
+
+// WithPoolOptions tunes the connection pool opened by NewSyncedEnforcer and NewEnforcerFromConn.
+// The same settings can be passed to NewAdapter as query parameters of the URL, pool_size, min_idle_conns,
+// max_conn_age, pool_timeout and idle_timeout, the durations being written like "30s".
+// The fields set here take precedence over the query parameters.
+func WithPoolOptions(pool PoolOptions) Option {
+	return func(a *Adapter) {
+		a.pool = &pool
+	}
+}
+
+// apply sets the non-zero fields of p on opts.
+func (p *PoolOptions) apply(opts *pg.Options) {
+	if p == nil {
+		return
+	}
+	if p.PoolSize > 0 {
+		opts.PoolSize = p.PoolSize
+	}
+	if p.MinIdleConns > 0 {
+		opts.MinIdleConns = p.MinIdleConns
+	}
+	if p.MaxConnAge > 0 {
+		opts.MaxConnAge = p.MaxConnAge
+	}
+	if p.PoolTimeout > 0 {
+		opts.PoolTimeout = p.PoolTimeout
+	}
+	if p.IdleTimeout > 0 {
+		opts.IdleTimeout = p.IdleTimeout
+	}
+}
+
+// parsePoolURL removes the pool parameters from the query of connURL, which go-pg rejects,
+// and returns the URL left and the pool options they set.
+func parsePoolURL(connURL string) (string, *PoolOptions, error) {
+	u, err := url.Parse(connURL)
+	if err != nil {
+		return "", nil, err
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var pool PoolOptions
+	found := false
+	for _, param := range poolURLParams {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		found = true
+		query.Del(param)
+
+		switch param {
+		case "pool_size":
+			pool.PoolSize, err = strconv.Atoi(value)
+		case "min_idle_conns":
+			pool.MinIdleConns, err = strconv.Atoi(value)
+		case "max_conn_age":
+			pool.MaxConnAge, err = time.ParseDuration(value)
+		case "pool_timeout":
+			pool.PoolTimeout, err = time.ParseDuration(value)
+		case "idle_timeout":
+			pool.IdleTimeout, err = time.ParseDuration(value)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid %s %q: %w", param, value, err)
+		}
+	}
+	if !found {
+		return connURL, nil, nil
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), &pool, nil
+}
@@ -0,0 +1,139 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// PromoteMode selects how PromotePolicies changes the destination, the flags can be combined with |.
+type PromoteMode int
+
+const (
+	// PromoteMerge adds the rules missing from the destination, it is the default.
+	PromoteMerge PromoteMode = 0
+	// PromoteReplace also removes the rules of the destination matching the filter that the source doesn't have,
+	// so both sides store the same rules for the filter.
+	PromoteReplace PromoteMode = 1
+	// PromoteDryRun only reports the changes, the destination is left untouched.
+	PromoteDryRun PromoteMode = 2
+)
+
+// PromoteReport lists the changes made by PromotePolicies, each rule starts with its ptype.
+type PromoteReport struct {
+	Added   [][]string
+	Removed [][]string
+	// DryRun is true if the changes have only been computed.
+	DryRun bool
+}
+
+// PromotePolicies copies the rules of src matching filter to dst in a single transaction of dst,
+// e.g. to promote the policy of a tenant from staging to production. A nil filter selects all rules.
+// The filter works like the one of LoadFilteredPolicy. The writes go through the middleware,
+// hooks and checks of dst and are published as a single PromotePolicies change.
+func PromotePolicies(ctx context.Context, src, dst *Adapter, filter *Filter, mode PromoteMode) (*PromoteReport, error) {
+	var report *PromoteReport
+	err := dst.doContext(ctx, &Operation{Name: OpPromotePolicies}, func(ctx context.Context) error {
+		var err error
+		report, err = dst.promotePolicies(ctx, src, filter, mode)
+		return err
+	})
+	return report, err
+}
+
+func (a *Adapter) promotePolicies(ctx context.Context, src *Adapter, filter *Filter, mode PromoteMode) (_ *PromoteReport, err error) {
+	defer a.handleError(OpPromotePolicies, "", 0, &err)
+
+	srcLines, err := src.filteredRules(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	dstLines, err := a.filteredRules(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	d := diffRules(srcLines, dstLines)
+	report := &PromoteReport{Added: d.OnlyHere, DryRun: mode&PromoteDryRun != 0}
+	if mode&PromoteReplace != 0 {
+		report.Removed = d.OnlyThere
+	}
+	if report.DryRun || len(report.Added) == 0 && len(report.Removed) == 0 {
+		return report, nil
+	}
+
+	if err := a.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+	added, err := a.promoteLines(OpPromotePolicies, report.Added)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := a.promoteLines(OpPromotePolicies, report.Removed)
+	if err != nil {
+		return nil, err
+	}
+
+	change := PolicyChange{Operation: OpPromotePolicies, Rules: report.Added, Removed: report.Removed}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		for _, line := range removed {
+			if _, err := tx.Model(line).Table(a.tableName).WherePK().Delete(); err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			if _, err := tx.Model(&added).Table(a.tableName).OnConflict("DO NOTHING").Insert(); err != nil {
+				return err
+			}
+			if err := a.checkQuota(tx, added); err != nil {
+				return err
+			}
+		}
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.changed(ctx, change)
+	return report, nil
+}
+
+// promoteLines runs the write checks on rules, which start with their ptype, and returns them as lines.
+func (a *Adapter) promoteLines(op string, rules [][]string) ([]*CasbinRule, error) {
+	byPtype := make(map[string][][]string)
+	var ptypes []string
+	for _, rule := range rules {
+		ptype := rule[0]
+		if _, ok := byPtype[ptype]; !ok {
+			ptypes = append(ptypes, ptype)
+		}
+		byPtype[ptype] = append(byPtype[ptype], rule[1:])
+	}
+
+	var lines []*CasbinRule
+	for _, ptype := range ptypes {
+		sec, err := ptypeSec(ptype)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.beforeWrite(op, sec, ptype, byPtype[ptype]); err != nil {
+			return nil, err
+		}
+		for _, rule := range byPtype[ptype] {
+			lines = append(lines, a.ruleLine(ptype, rule))
+		}
+	}
+	return lines, nil
+}
+
+// filteredRules returns the rules matching filter in canonical order, all of them if filter is nil.
+func (a *Adapter) filteredRules(ctx context.Context, filter *Filter) ([]*CasbinRule, error) {
+	var lines []*CasbinRule
+	query, err := applyFilter(a.conn().ModelContext(ctx, &lines).Table(a.tableName).OrderExpr(canonicalOrder), filter)
+	if err != nil {
+		return nil, err
+	}
+	err = query.Select()
+	return lines, err
+}
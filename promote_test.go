@@ -0,0 +1,36 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestPromotePolicies() {
+	dst, err := s.a.WithTable("casbin_rule_prod")
+	s.Require().NoError(err)
+	s.Require().NoError(dst.AddPolicy("p", "p", []string{"bob", "data3", "read"}))
+	s.Require().NoError(dst.AddPolicy("p", "p", []string{"alice", "data9", "read"}))
+
+	filter := &Filter{P: []string{"bob"}}
+	report, err := PromotePolicies(context.Background(), s.a, dst, filter, PromoteReplace|PromoteDryRun)
+	s.Require().NoError(err)
+	s.Require().True(report.DryRun)
+	s.Require().Equal([][]string{{"p", "bob", "data2", "write"}}, report.Added)
+	s.Require().Equal([][]string{{"p", "bob", "data3", "read"}}, report.Removed)
+
+	d, err := s.a.ComparePolicies(context.Background(), dst)
+	s.Require().NoError(err)
+	s.Require().Len(d.OnlyThere, 2, "a dry run doesn't change the destination")
+
+	report, err = PromotePolicies(context.Background(), s.a, dst, filter, PromoteReplace)
+	s.Require().NoError(err)
+	s.Require().False(report.DryRun)
+
+	d, err = s.a.ComparePolicies(context.Background(), dst)
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "alice", "data9", "read"}}, d.OnlyThere, "rules outside of the filter are kept")
+
+	report, err = PromotePolicies(context.Background(), s.a, dst, nil, PromoteMerge)
+	s.Require().NoError(err)
+	s.Require().Len(report.Added, 4)
+	s.Require().Empty(report.Removed)
+}
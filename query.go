@@ -0,0 +1,93 @@
+package pgadapter
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// PolicyIDFor returns the ID under which an adapter created without WithIDFunc, WithCaseInsensitive
+// and WithGeneratedID stores rule, see Adapter.PolicyIDFor for the others.
+func PolicyIDFor(ptype string, rule []string) string {
+	return policyID(ptype, rule)
+}
+
+// PolicyIDFor returns the ID under which the adapter stores rule, computed with its options.
+func (a *Adapter) PolicyIDFor(ptype string, rule []string) string {
+	return a.ruleID(ptype, rule)
+}
+
+// GetPolicyByID returns the rule stored under id, see Adapter.PolicyIDFor.
+// It returns an error matching ErrNotFound if there is no such rule.
+func (a *Adapter) GetPolicyByID(ctx context.Context, id string) (ptype string, rule []string, err error) {
+	defer a.handleError(OpGetPolicyByID, "", 0, &err)
+
+	line := &CasbinRule{ID: id}
+	if err := a.conn().ModelContext(ctx, line).Table(a.tableName).WherePK().Select(); err != nil {
+		return "", nil, err
+	}
+	return line.Ptype, line.rule(), nil
+}
+
+// QueryRules returns the rules matching the SQL predicate sqlWhere, e.g. "v0 LIKE ? AND ptype = ?".
+// Each returned rule starts with its ptype, followed by its values.
+// sqlWhere is inserted as is into the query, values must be passed as args, see github.com/go-pg/pg for the placeholder syntax.
+func (a *Adapter) QueryRules(ctx context.Context, sqlWhere string, args ...interface{}) (_ [][]string, err error) {
+	defer a.handleError(OpQueryRules, "", 0, &err)
+
+	var lines []*CasbinRule
+	if err := a.conn().ModelContext(ctx, &lines).Table(a.tableName).Where(sqlWhere, args...).Select(); err != nil {
+		return nil, err
+	}
+
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.ptypeRule())
+	}
+	return rules, nil
+}
+
+// IsEmpty reports whether the rules table holds no rule, e.g. to decide whether to seed default rules.
+func (a *Adapter) IsEmpty(ctx context.Context) (_ bool, err error) {
+	defer a.handleError(OpIsEmpty, "", 0, &err)
+
+	var exists bool
+	_, err = a.conn().QueryOneContext(ctx, pg.Scan(&exists), "SELECT EXISTS (SELECT 1 FROM ?)", pg.Ident(a.tableName))
+	return !exists, err
+}
+
+// HasPolicy reports whether rule is stored, without loading the policy.
+func (a *Adapter) HasPolicy(ctx context.Context, ptype string, rule []string) (_ bool, err error) {
+	defer a.handleError(OpHasPolicy, ptype, 1, &err)
+
+	var exists bool
+	_, err = a.conn().QueryOneContext(ctx, pg.Scan(&exists), "SELECT EXISTS (SELECT 1 FROM ? WHERE id = ?)",
+		pg.Ident(a.tableName), a.ruleID(ptype, rule))
+	return exists, err
+}
+
+// GetFilteredPolicies returns the rules of ptype whose values from fieldIndex on are fieldValues, empty values
+// matching any value, like RemoveFilteredPolicy, without loading them into a model, e.g. to list the rules of a subject.
+// The rules are in canonical order, or in the order of WithRuleOrder, and the disabled bundles are skipped.
+func (a *Adapter) GetFilteredPolicies(ptype string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	return a.GetFilteredPoliciesCtx(context.Background(), ptype, fieldIndex, fieldValues...)
+}
+
+// GetFilteredPoliciesCtx is like GetFilteredPolicies, ctx bounds the database calls.
+func (a *Adapter) GetFilteredPoliciesCtx(ctx context.Context, ptype string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer a.handleError(OpGetFilteredPolicies, ptype, 0, &err)
+
+	var lines []*CasbinRule
+	query := a.loadQuery(a.conn().ModelContext(ctx, &lines).Table(a.tableName).Where("ptype = ?", ptype))
+	if !a.ruleOrder {
+		query = query.OrderExpr(canonicalOrder)
+	}
+	if err := fieldQuery(query, fieldIndex, a.fold(fieldValues)).Select(); err != nil {
+		return nil, err
+	}
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.rule())
+	}
+	return rules, nil
+}
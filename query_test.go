@@ -0,0 +1,138 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGetPolicyByID(t *testing.T) {
+	_, f := newFakeEnforcer(t)
+
+	ptype, rule, err := f.GetPolicyByID(context.Background(), PolicyIDFor("g", []string{"alice", "data2_admin"}))
+	require.NoError(t, err)
+	require.Equal(t, "g", ptype)
+	require.Equal(t, []string{"alice", "data2_admin"}, rule)
+
+	_, _, err = f.GetPolicyByID(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func (s *AdapterTestSuite) TestGetPolicyByID() {
+	id := PolicyIDFor("p", []string{"bob", "data2", "write"})
+	ptype, rule, err := s.a.GetPolicyByID(context.Background(), id)
+	s.Require().NoError(err)
+	s.Require().Equal("p", ptype)
+	s.Require().Equal([]string{"bob", "data2", "write"}, rule)
+
+	_, _, err = s.a.GetPolicyByID(context.Background(), PolicyIDFor("p", []string{"bob", "data2", "read"}))
+	s.Require().ErrorIs(err, ErrNotFound)
+}
+
+func TestAdapterPolicyIDFor(t *testing.T) {
+	a := &Adapter{}
+	require.Equal(t, PolicyIDFor("p", []string{"alice", "data1", "read"}), a.PolicyIDFor("p", []string{"alice", "data1", "read"}))
+
+	WithCaseInsensitive()(a)
+	WithIDFunc(SHA256PolicyID)(a)
+	require.Equal(t, SHA256PolicyID("p", []string{"alice", "data1", "read"}), a.PolicyIDFor("p", []string{"Alice", "data1", "read"}))
+}
+
+func (s *AdapterTestSuite) TestGetPolicyByIDWithIDFunc() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_sha256"), WithIDFunc(SHA256PolicyID))
+	s.Require().NoError(err)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+
+	ptype, rule, err := a.GetPolicyByID(context.Background(), a.PolicyIDFor("p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(err)
+	s.Require().Equal("p", ptype)
+	s.Require().Equal([]string{"carol", "data1", "read"}, rule)
+}
+
+func (s *AdapterTestSuite) TestQueryRules() {
+	rules, err := s.a.QueryRules(context.Background(), "v1 = ? AND v2 IN (?)", "data2", pg.In([]string{"read", "write"}))
+	s.Require().NoError(err)
+	s.assertPolicy([][]string{{"p", "bob", "data2", "write"}, {"p", "data2_admin", "data2", "read"}, {"p", "data2_admin", "data2", "write"}}, rules)
+
+	rules, err = s.a.QueryRules(context.Background(), "ptype = 'g'")
+	s.Require().NoError(err)
+	s.assertPolicy([][]string{{"g", "alice", "data2_admin"}}, rules)
+}
+
+func TestFakeHasPolicy(t *testing.T) {
+	f := NewFake()
+	ctx := context.Background()
+
+	empty, err := f.IsEmpty(ctx)
+	require.NoError(t, err)
+	require.True(t, empty)
+
+	require.NoError(t, f.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	empty, err = f.IsEmpty(ctx)
+	require.NoError(t, err)
+	require.False(t, empty)
+
+	ok, err := f.HasPolicy(ctx, "p", []string{"alice", "data1", "read"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = f.HasPolicy(ctx, "p", []string{"alice", "data1", "write"})
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func (s *AdapterTestSuite) TestHasPolicy() {
+	ctx := context.Background()
+
+	empty, err := s.a.IsEmpty(ctx)
+	s.Require().NoError(err)
+	s.Require().False(empty)
+
+	ok, err := s.a.HasPolicy(ctx, "g", []string{"alice", "data2_admin"})
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	ok, err = s.a.HasPolicy(ctx, "p", []string{"alice", "data2", "read"})
+	s.Require().NoError(err)
+	s.Require().False(ok)
+
+	s.Require().NoError(s.a.SavePolicy(model.NewModel()))
+	empty, err = s.a.IsEmpty(ctx)
+	s.Require().NoError(err)
+	s.Require().True(empty)
+}
+
+func TestFakeGetFilteredPolicies(t *testing.T) {
+	_, f := newFakeEnforcer(t)
+
+	rules, err := f.GetFilteredPolicies("p", 1, "data2", "write")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "write"}}, rules)
+
+	rules, err = f.GetFilteredPolicies("g", 0, "", "data2_admin")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"alice", "data2_admin"}}, rules)
+
+	rules, err = f.GetFilteredPolicies("p", 0, "nobody")
+	require.NoError(t, err)
+	require.Empty(t, rules)
+}
+
+func (s *AdapterTestSuite) TestGetFilteredPolicies() {
+	rules, err := s.a.GetFilteredPolicies("p", 0, "data2_admin")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, rules)
+
+	rules, err = s.a.GetFilteredPolicies("p", 1, "data2", "write")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "write"}}, rules)
+
+	rules, err = s.a.GetFilteredPolicies("g", 0, "", "data2_admin")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"alice", "data2_admin"}}, rules)
+
+	rules, err = s.a.GetFilteredPolicies("p", 0, "nobody")
+	s.Require().NoError(err)
+	s.Require().Empty(rules)
+}
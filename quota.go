@@ -0,0 +1,126 @@
+package pgadapter
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// QuotaError is returned when adding rules would exceed a limit set with WithQuota.
+// It matches ErrQuotaExceeded with errors.Is.
+type QuotaError struct {
+	// Limit is "total", "subject" or "tenant".
+	Limit string
+	// Key is the subject or tenant that exceeds its limit, it is empty for the total limit.
+	Key   string
+	Max   int
+	Count int
+}
+
+func (e *QuotaError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %d rules, the %s limit is %d", ErrQuotaExceeded, e.Count, e.Limit, e.Max)
+	}
+	return fmt.Sprintf("%s: %d rules for %s %q, the limit is %d", ErrQuotaExceeded, e.Count, e.Limit, e.Key, e.Max)
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+type quota struct {
+	maxTotal      int
+	maxPerSubject int
+	maxPerTenant  int
+}
+
+// WithQuota limits the number of stored rules, a limit of 0 means unlimited.
+// maxTotal limits the rules in the table, maxPerSubject the rules per subject (v0)
+// and maxPerTenant the rules per tenant, which is the domain of rules of models with domains:
+// v1 for the policy rules (sub, dom, obj, act) and v2 for the role assignments (user, role, dom).
+// The limits are enforced by AddPolicy and AddPolicies in the same transaction as the insert,
+// exceeding them rolls the insert back and returns a *QuotaError.
+func WithQuota(maxTotal, maxPerSubject, maxPerTenant int) Option {
+	return func(a *Adapter) {
+		a.quota = &quota{
+			maxTotal:      maxTotal,
+			maxPerSubject: maxPerSubject,
+			maxPerTenant:  maxPerTenant,
+		}
+	}
+}
+
+// checkQuota verifies the limits after lines have been inserted within tx.
+func (a *Adapter) checkQuota(tx *pg.Tx, lines []*CasbinRule) error {
+	q := a.quota
+	if q == nil {
+		return nil
+	}
+
+	// Serialize the quota checked inserts so concurrent transactions can't both pass the checks.
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", a.tableName); err != nil {
+		return err
+	}
+
+	if q.maxTotal > 0 {
+		count, err := tx.Model((*CasbinRule)(nil)).Table(a.tableName).Count()
+		if err != nil {
+			return err
+		}
+		if count > q.maxTotal {
+			return &QuotaError{Limit: "total", Max: q.maxTotal, Count: count}
+		}
+	}
+	if q.maxPerSubject > 0 {
+		if err := a.checkColumnQuota(tx, "subject", pg.Ident("v0"), q.maxPerSubject, lines, func(l *CasbinRule) string { return l.V0 }); err != nil {
+			return err
+		}
+	}
+	if q.maxPerTenant > 0 {
+		if err := a.checkColumnQuota(tx, "tenant", tenantQuotaColumn, q.maxPerTenant, lines, ruleDomain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantQuotaColumn is the domain of the stored rules, see ruleDomain.
+var tenantQuotaColumn = pg.Safe("CASE WHEN ptype LIKE 'g%' THEN v2 ELSE v1 END")
+
+// ruleDomain returns the domain of line, the third value of role assignments and the second one of the other rules.
+func ruleDomain(line *CasbinRule) string {
+	if line.Ptype != "" && line.Ptype[0] == 'g' {
+		return line.V2
+	}
+	return line.V1
+}
+
+// checkColumnQuota verifies that the rules with the values of column of lines don't exceed max,
+// column is an identifier or an expression.
+func (a *Adapter) checkColumnQuota(tx *pg.Tx, limit string, column interface{}, max int, lines []*CasbinRule, value func(*CasbinRule) string) error {
+	keys := make([]string, 0, len(lines))
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if v := value(line); v != "" && !seen[v] {
+			seen[v] = true
+			keys = append(keys, v)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var res []struct {
+		Key   string
+		Count int
+	}
+	_, err := tx.Query(&res, "SELECT ?0 AS key, count(*) AS count FROM ?1 WHERE ?0 IN (?2) GROUP BY ?0 HAVING count(*) > ?3 LIMIT 1",
+		column, pg.Ident(a.tableName), pg.In(keys), max)
+	if err != nil {
+		return err
+	}
+	if len(res) > 0 {
+		return &QuotaError{Limit: limit, Key: res[0].Key, Max: max, Count: res[0].Count}
+	}
+	return nil
+}
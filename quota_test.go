@@ -0,0 +1,67 @@
+package pgadapter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaError(t *testing.T) {
+	var err error = &QuotaError{Limit: "subject", Key: "alice", Max: 2, Count: 3}
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	require.Equal(t, `policy quota exceeded: 3 rules for subject "alice", the limit is 2`, err.Error())
+
+	wrapError(OpAddPolicy, &err)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	var quotaErr *QuotaError
+	require.True(t, errors.As(err, &quotaErr))
+	require.Equal(t, "alice", quotaErr.Key)
+}
+
+func (s *AdapterTestSuite) TestQuota() {
+	a, err := NewAdapterByDB(s.a.db, WithQuota(7, 2, 0))
+	s.Require().NoError(err)
+
+	// alice already has two rules, p and g.
+	err = a.AddPolicy("p", "p", []string{"alice", "data3", "read"})
+	s.Require().ErrorIs(err, ErrQuotaExceeded)
+
+	err = a.AddPolicies("p", "p", [][]string{{"carol", "data1", "read"}, {"carol", "data2", "read"}})
+	s.Require().NoError(err)
+
+	err = a.AddPolicies("p", "p", [][]string{{"dave", "data1", "read"}, {"erin", "data1", "read"}})
+	var quotaErr *QuotaError
+	s.Require().ErrorAs(err, &quotaErr)
+	s.Require().Equal("total", quotaErr.Limit)
+
+	// The failed inserts have been rolled back.
+	err = s.e.LoadPolicy()
+	s.Require().NoError(err)
+	s.assertPolicy(
+		[][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data1", "read"}, {"carol", "data2", "read"}},
+		s.e.GetPolicy(),
+	)
+}
+
+func TestRuleDomain(t *testing.T) {
+	require.Equal(t, "domain1", ruleDomain(savePolicyLine("p", []string{"alice", "domain1", "data1", "read"})))
+	require.Equal(t, "domain1", ruleDomain(savePolicyLine("g", []string{"alice", "admin", "domain1"})))
+	require.Equal(t, "", ruleDomain(savePolicyLine("g", []string{"alice", "admin"})))
+}
+
+func (s *AdapterTestSuite) TestQuotaPerTenant() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_domains"), WithQuota(0, 0, 3))
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicies("p", "p", [][]string{{"admin", "domain1", "data1", "read"}, {"admin", "domain2", "data1", "read"}}))
+	// The role assignments count for their domain, not for their role.
+	s.Require().NoError(a.AddPolicies("g", "g", [][]string{{"alice", "admin", "domain1"}, {"bob", "admin", "domain1"}, {"carol", "admin", "domain2"}}))
+
+	err = a.AddPolicy("g", "g", []string{"dave", "admin", "domain1"})
+	var quotaErr *QuotaError
+	s.Require().ErrorAs(err, &quotaErr)
+	s.Require().Equal("tenant", quotaErr.Limit)
+	s.Require().Equal("domain1", quotaErr.Key)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"admin", "domain2", "data2", "read"}))
+}
@@ -0,0 +1,50 @@
+package pgadapter
+
+import (
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// WithReadReplica makes LoadPolicy and LoadFilteredPolicy read the rules from a read replica,
+// while the writes and the other reads go to the primary the adapter is created with.
+// arg is a URL or *pg.Options like the ones of NewAdapter, connecting to their database as is,
+// or a *pg.DB shared with the application. The pool opened for a URL or options gets the pool, TLS and
+// statement timeout options of the adapter and is closed with it.
+// A replica lags behind the primary, so a load right after a write may not see it yet,
+// use a Watcher or the change notifications to reload once the replica caught up if needed.
+// The loads of adapters running in the caller's transaction, see WithTx, read from the primary.
+func WithReadReplica(arg interface{}) Option {
+	return func(a *Adapter) {
+		a.replicaArg = arg
+	}
+}
+
+// connectReplica connects to the read replica set by WithReadReplica, if any.
+func (a *Adapter) connectReplica() error {
+	if a.replicaArg == nil || a.replica != nil {
+		return nil
+	}
+	if db, ok := a.replicaArg.(*pg.DB); ok {
+		a.replica = db
+		return nil
+	}
+	// The failover options of the adapter are the ones of the primary.
+	cfg := &Adapter{pool: a.pool, tlsConfig: a.tlsConfig, statementTimeout: a.statementTimeout}
+	opts, _, err := connectOptions(a.replicaArg, cfg)
+	if err != nil {
+		return err
+	}
+	a.replica, a.ownsReplica = pg.Connect(opts), true
+	if a.queryLog != nil {
+		a.replica.AddQueryHook(a.queryLog)
+	}
+	return nil
+}
+
+// readConn returns the connection the loads read from, the read replica if set.
+func (a *Adapter) readConn() orm.DB {
+	if a.tx == nil && a.replica != nil {
+		return a.replica
+	}
+	return a.conn()
+}
@@ -0,0 +1,171 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/go-pg/pg/v10"
+)
+
+// RestorePolicyAt makes the table store exactly the rules it stored at the instant at, like SyncPolicy,
+// in a single transaction, and reports the changes. See LoadPolicyAt for how the rules are reconstructed.
+func (a *Adapter) RestorePolicyAt(ctx context.Context, at time.Time) (_ *SyncReport, err error) {
+	rules, err := a.policyAt(ctx, at)
+	if err != nil {
+		a.handleError(OpRestorePolicyAt, "", 0, &err)
+		return nil, err
+	}
+	return a.syncRules(ctx, rulesByPtype(rules))
+}
+
+// LoadPolicyAt loads into model the rules the table stored at the instant at, without writing anything,
+// e.g. to check what a user could do at the time of an incident.
+// The rules are reconstructed from the operation log, so it requires WithOperationLog: with WithSnapshots,
+// the changes logged after the last snapshot taken at or before at are applied to its rules, otherwise
+// the changes logged after at are undone from the current rules. Changes that don't record their rules,
+// like SavePolicy, can't be applied nor undone and make the reconstruction fail.
+// Entries are stamped with the start time of their transaction, so changes committed around at may be
+// attributed to either side of it.
+func (a *Adapter) LoadPolicyAt(ctx context.Context, model model.Model, at time.Time) (err error) {
+	defer a.handleError(OpLoadPolicyAt, "", 0, &err)
+
+	rules, err := a.policyAt(ctx, at)
+	if err != nil {
+		return err
+	}
+	skipped := skippedRules{}
+	for _, rule := range rules {
+		if !a.loadAllowed(rule[0], skipped) {
+			continue
+		}
+		if err := persist.LoadPolicyLine(savePolicyLine(rule[0], rule[1:]).String(), model); err != nil {
+			return err
+		}
+	}
+	a.reportSkipped(OpLoadPolicyAt, skipped)
+	return nil
+}
+
+// policyAt returns the rules the table stored at the instant at in canonical order, each one starting with its ptype.
+func (a *Adapter) policyAt(ctx context.Context, at time.Time) ([][]string, error) {
+	if !a.operationLog {
+		return nil, errors.New("the operation log is not enabled, see WithOperationLog")
+	}
+
+	var snapshot *Snapshot
+	if a.snapshots {
+		snapshot = &Snapshot{}
+		err := a.conn().ModelContext(ctx, snapshot).
+			Where("rule_table = ?", a.tableName).
+			Where("created_at <= ?", at).
+			Order("created_at DESC").
+			Limit(1).
+			Select()
+		if errors.Is(err, pg.ErrNoRows) {
+			snapshot = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	rules := make(ruleSet)
+	var ops []LoggedOperation
+	query := a.conn().ModelContext(ctx, &ops).Where("rule_table = ?", a.tableName)
+	if snapshot != nil {
+		// Replay the changes made after the snapshot up to at.
+		rules.add(snapshot.Rules)
+		query = query.Where("created_at > ?", snapshot.CreatedAt).Where("created_at <= ?", at).Order("id")
+	} else {
+		// Undo the changes made after at, the latest first.
+		lines, err := a.canonicalRules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			// The stored IDs may be stale, see RepairPolicyIDs, so they are recomputed.
+			rules.add([][]string{line.ptypeRule()})
+		}
+		query = query.Where("created_at > ?", at).Order("id DESC")
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if !rules.apply(op.Change, snapshot == nil) {
+			return nil, fmt.Errorf("the rules at %s can't be reconstructed, %s at %s doesn't record its rules",
+				at.Format(time.RFC3339), op.Change.Operation, op.CreatedAt.Format(time.RFC3339))
+		}
+	}
+	return rules.sorted(), nil
+}
+
+// ruleSet is a set of rules starting with their ptype, by rule ID.
+type ruleSet map[string][]string
+
+func (s ruleSet) add(rules [][]string) {
+	for _, rule := range rules {
+		line := savePolicyLine(rule[0], rule[1:])
+		s[line.ID] = line.ptypeRule()
+	}
+}
+
+func (s ruleSet) remove(rules [][]string) {
+	for _, rule := range rules {
+		delete(s, savePolicyLine(rule[0], rule[1:]).ID)
+	}
+}
+
+// apply applies change to the set, or undoes it if undo is set.
+// It returns false if the change doesn't record its rules.
+func (s ruleSet) apply(change PolicyChange, undo bool) bool {
+	withPtype := func(rules [][]string) [][]string {
+		out := make([][]string, 0, len(rules))
+		for _, rule := range rules {
+			out = append(out, append([]string{change.Ptype}, rule...))
+		}
+		return out
+	}
+
+	var added, removed [][]string
+	switch change.Operation {
+	case OpAddPolicy, OpAddPolicies:
+		added = withPtype(change.Rules)
+	case OpRemovePolicy, OpRemovePolicies:
+		removed = withPtype(change.Rules)
+	case OpRemoveFilteredPolicy:
+		removed = withPtype(change.Removed)
+	case OpUpdatePolicy, OpUpdatePolicies, OpUpdateFilteredPolicies:
+		added, removed = withPtype(change.Rules), withPtype(change.OldRules)
+	case OpSyncPolicy:
+		// The rules of SyncPolicy start with their ptype.
+		added, removed = change.Rules, change.Removed
+	case OpSetPolicyLabels:
+		// Labels don't change the rules.
+	default:
+		return false
+	}
+	if undo {
+		added, removed = removed, added
+	}
+	s.remove(removed)
+	s.add(added)
+	return true
+}
+
+// sorted returns the rules in canonical order.
+func (s ruleSet) sorted() [][]string {
+	lines := make([]*CasbinRule, 0, len(s))
+	for _, rule := range s {
+		lines = append(lines, savePolicyLine(rule[0], rule[1:]))
+	}
+	sortRules(lines)
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.ptypeRule())
+	}
+	return rules
+}
@@ -0,0 +1,58 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSetApply(t *testing.T) {
+	rules := make(ruleSet)
+	rules.add([][]string{{"p", "alice", "data1", "read"}, {"p", "bob", "data2", "write"}})
+
+	update := PolicyChange{
+		Operation: OpUpdatePolicy, Ptype: "p",
+		OldRules: [][]string{{"alice", "data1", "read"}}, Rules: [][]string{{"alice", "data1", "write"}},
+	}
+	require.True(t, rules.apply(update, false))
+	require.Equal(t, [][]string{{"p", "alice", "data1", "write"}, {"p", "bob", "data2", "write"}}, rules.sorted())
+
+	require.True(t, rules.apply(update, true))
+	require.Equal(t, [][]string{{"p", "alice", "data1", "read"}, {"p", "bob", "data2", "write"}}, rules.sorted())
+
+	sync := PolicyChange{Operation: OpSyncPolicy, Rules: [][]string{{"g", "alice", "admin"}}, Removed: [][]string{{"p", "bob", "data2", "write"}}}
+	require.True(t, rules.apply(sync, false))
+	require.Equal(t, [][]string{{"g", "alice", "admin"}, {"p", "alice", "data1", "read"}}, rules.sorted())
+
+	require.False(t, rules.apply(PolicyChange{Operation: OpSavePolicy}, false))
+}
+
+func (s *AdapterTestSuite) TestRestorePolicyAt() {
+	ctx := context.Background()
+	a, err := NewAdapterByDB(s.a.db, WithOperationLog())
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	time.Sleep(10 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"carol", "data1", "read"}, []string{"carol", "data1", "write"}))
+
+	m := s.e.GetModel().Copy()
+	m.ClearPolicy()
+	s.Require().NoError(a.LoadPolicyAt(ctx, m, at))
+	s.assertPolicy([][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"carol", "data1", "read"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, m.GetPolicy("p", "p"))
+
+	report, err := a.RestorePolicyAt(ctx, at)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([][]string{{"p", "alice", "data1", "read"}, {"p", "carol", "data1", "read"}}, report.Added)
+	s.Require().Equal([][]string{{"p", "carol", "data1", "write"}}, report.Removed)
+
+	// SavePolicy doesn't record its rules, the rules before it can't be reconstructed.
+	s.Require().NoError(a.SavePolicy(s.e.GetModel()))
+	_, err = a.RestorePolicyAt(ctx, at)
+	s.Require().Error(err)
+}
@@ -0,0 +1,91 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// WithRuleTextColumn adds a rule_text column to the rules table holding each rule as a line of a Casbin policy CSV file,
+// e.g. `p, alice, "data1,data2", read`, generated by Postgres from the ptype and value columns, see RuleText.
+// The column gets a btree index for equality lookups and a trigram index for ILIKE predicates passed to QueryRules,
+// and can be used as a human-readable key by audit and admin tooling, see GetPolicyByText.
+// The column requires Postgres 12 or later and the trigram index the CREATE privilege to install the pg_trgm extension.
+func WithRuleTextColumn() Option {
+	return func(a *Adapter) {
+		a.ruleTextColumn = true
+	}
+}
+
+// ruleTextValue is the SQL expression of a value of rule_text, values containing commas or quotes
+// or surrounded by white space are quoted like in CSV files.
+const ruleTextValue = `coalesce(', ' || CASE WHEN ?0 ~ '[",]|^\s|\s$' THEN '"' || replace(?0, '"', '""') || '"' ELSE ?0 END, '')`
+
+func (a *Adapter) createRuleTextColumn() error {
+	expr := "coalesce(ptype, '')"
+	for i := 0; i < 6; i++ {
+		expr += " || " + strings.ReplaceAll(ruleTextValue, "?0", valueColumn(i))
+	}
+	_, err := a.conn().Exec("ALTER TABLE ? ADD COLUMN IF NOT EXISTS rule_text text GENERATED ALWAYS AS (?) STORED",
+		pg.Ident(a.tableName), pg.Safe(expr))
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? (rule_text)",
+		pg.Ident(unqualified(a.tableName)+"_rule_text_idx"), pg.Ident(a.tableName))
+	if err != nil {
+		return err
+	}
+	if _, err := a.conn().Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm"); err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? USING gin (rule_text gin_trgm_ops)",
+		pg.Ident(unqualified(a.tableName)+"_rule_text_trgm_idx"), pg.Ident(a.tableName))
+	return err
+}
+
+// RuleText returns the value of the rule_text column of rule, see WithRuleTextColumn.
+// Empty values are left out like in the lines loaded by the adapter.
+func RuleText(ptype string, rule []string) string {
+	var sb strings.Builder
+	sb.WriteString(ptype)
+	for _, v := range rule {
+		if v == "" {
+			continue
+		}
+		sb.WriteString(", ")
+		if strings.ContainsAny(v, `",`) || isRuleTextSpace(v[0]) || isRuleTextSpace(v[len(v)-1]) {
+			sb.WriteString(`"` + strings.ReplaceAll(v, `"`, `""`) + `"`)
+		} else {
+			sb.WriteString(v)
+		}
+	}
+	return sb.String()
+}
+
+// isRuleTextSpace reports whether c matches \s in a Postgres regular expression.
+func isRuleTextSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	}
+	return false
+}
+
+// GetPolicyByText returns the rule whose rule_text is text, see RuleText. It requires WithRuleTextColumn.
+// It returns an error matching ErrNotFound if there is no such rule.
+func (a *Adapter) GetPolicyByText(ctx context.Context, text string) (ptype string, rule []string, err error) {
+	defer a.handleError(OpGetPolicyByText, "", 0, &err)
+
+	if !a.ruleTextColumn {
+		return "", nil, errors.New("the rule text column is not enabled, see WithRuleTextColumn")
+	}
+
+	line := &CasbinRule{}
+	if err := a.conn().ModelContext(ctx, line).Table(a.tableName).Where("rule_text = ?", text).Limit(1).Select(); err != nil {
+		return "", nil, err
+	}
+	return line.Ptype, line.rule(), nil
+}
@@ -0,0 +1,36 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleText(t *testing.T) {
+	require.Equal(t, "p, alice, data1, read", RuleText("p", []string{"alice", "data1", "read"}))
+	require.Equal(t, "p, alice, read", RuleText("p", []string{"alice", "", "read", ""}))
+	require.Equal(t, `p, alice, "data1,data2", "say ""hi""", " x"`,
+		RuleText("p", []string{"alice", "data1,data2", `say "hi"`, " x"}))
+}
+
+func (s *AdapterTestSuite) TestGetPolicyByText() {
+	a, err := NewAdapterByDB(s.a.db, WithRuleTextColumn())
+	s.Require().NoError(err)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1,data2", "read"}))
+
+	for _, rule := range [][]string{{"alice", "data1", "read"}, {"carol", "data1,data2", "read"}} {
+		ptype, got, err := a.GetPolicyByText(context.Background(), RuleText("p", rule))
+		s.Require().NoError(err)
+		s.Require().Equal("p", ptype)
+		s.Require().Equal(rule, got)
+	}
+
+	rules, err := a.QueryRules(context.Background(), "rule_text ILIKE ?", "g, ALICE%")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"g", "alice", "data2_admin"}}, rules)
+
+	_, _, err = a.GetPolicyByText(context.Background(), "p, nobody")
+	s.Require().True(errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,62 @@
+package pgadapter
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// WithSearchIndexes installs the pg_trgm extension and creates trigram GIN indexes on the value columns,
+// so SearchPolicies and ILIKE predicates passed to QueryRules don't scan the whole table.
+// Installing the extension requires the CREATE privilege on the database.
+func WithSearchIndexes() Option {
+	return func(a *Adapter) {
+		a.searchIndexes = true
+	}
+}
+
+func (a *Adapter) createSearchIndexes() error {
+	if _, err := a.conn().Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm"); err != nil {
+		return err
+	}
+	for i := 0; i < 6; i++ {
+		column := valueColumn(i)
+		_, err := a.conn().Exec("CREATE INDEX IF NOT EXISTS ? ON ? USING gin (? gin_trgm_ops)",
+			pg.Ident(unqualified(a.tableName)+"_"+column+"_trgm_idx"), pg.Ident(a.tableName), pg.Ident(column))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchPolicies returns the rules having a value containing text, ignoring case, in canonical order, see ExportPolicies.
+// Each returned rule starts with its ptype. See WithSearchIndexes to make it fast on large tables.
+func (a *Adapter) SearchPolicies(ctx context.Context, text string) (_ [][]string, err error) {
+	defer a.handleError(OpSearchPolicies, "", 0, &err)
+
+	pattern := "%" + likeEscaper.Replace(text) + "%"
+	var lines []*CasbinRule
+	query := a.conn().ModelContext(ctx, &lines).Table(a.tableName).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			for i := 0; i < 6; i++ {
+				q = q.WhereOr("? ILIKE ?", pg.Ident(valueColumn(i)), pattern)
+			}
+			return q, nil
+		}).
+		OrderExpr(canonicalOrder)
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+
+	rules := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		rules = append(rules, line.ptypeRule())
+	}
+	return rules, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,31 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikeEscaper(t *testing.T) {
+	require.Equal(t, `data\_1\%\\`, likeEscaper.Replace(`data_1%\`))
+}
+
+func (s *AdapterTestSuite) TestSearchPolicies() {
+	a, err := NewAdapterByDB(s.a.db, WithSearchIndexes())
+	s.Require().NoError(err)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data_3", "read"}))
+
+	rules, err := a.SearchPolicies(context.Background(), "DATA2")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{
+		{"g", "alice", "data2_admin"},
+		{"p", "bob", "data2", "write"},
+		{"p", "data2_admin", "data2", "read"},
+		{"p", "data2_admin", "data2", "write"},
+	}, rules)
+
+	rules, err = a.SearchPolicies(context.Background(), "a_3")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "carol", "data_3", "read"}}, rules)
+}
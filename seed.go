@@ -0,0 +1,89 @@
+package pgadapter
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// SeedPolicies inserts rules, keyed by ptype, with the same IDs the adapter would use.
+// It creates the rules table if needed, opts can be used to select the table, e.g. WithTableName.
+// It is meant for setting up integration test fixtures, existing rules are left untouched.
+func SeedPolicies(ctx context.Context, db *pg.DB, rules map[string][][]string, opts ...Option) error {
+	a, err := NewAdapterByDB(db, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Only the background work of the options is stopped, e.g. WithWebhook, the pool is the caller's.
+		a.db = nil
+		a.Close()
+	}()
+
+	ptypes := make([]string, 0, len(rules))
+	for ptype := range rules {
+		ptypes = append(ptypes, ptype)
+	}
+	sort.Strings(ptypes)
+
+	var lines []*CasbinRule
+	for _, ptype := range ptypes {
+		for _, rule := range rules[ptype] {
+			lines = append(lines, a.ruleLine(ptype, rule))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	_, err = db.ModelContext(ctx, &lines).Table(a.tableName).OnConflict("DO NOTHING").Insert()
+	return err
+}
+
+// SeedPoliciesFromFile is like SeedPolicies but reads the rules from a Casbin policy CSV file,
+// such as examples/rbac_policy.csv.
+func SeedPoliciesFromFile(ctx context.Context, db *pg.DB, path string, opts ...Option) error {
+	rules, err := readPolicyFile(path)
+	if err != nil {
+		return err
+	}
+	return SeedPolicies(ctx, db, rules, opts...)
+}
+
+// readPolicyFile parses a Casbin policy CSV file into rules keyed by ptype.
+func readPolicyFile(path string) (map[string][][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readPolicyCSV(f)
+}
+
+// readPolicyCSV parses Casbin policy CSV records into rules keyed by ptype.
+func readPolicyCSV(in io.Reader) (map[string][][]string, error) {
+	r := csv.NewReader(in)
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	rules := make(map[string][][]string)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		rules[record[0]] = append(rules[record[0]], record[1:])
+	}
+	return rules, nil
+}
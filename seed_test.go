@@ -0,0 +1,32 @@
+package pgadapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPolicyFile(t *testing.T) {
+	rules, err := readPolicyFile("examples/rbac_policy.csv")
+	require.NoError(t, err)
+	require.Equal(t, map[string][][]string{
+		"p": {{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}},
+		"g": {{"alice", "data2_admin"}},
+	}, rules)
+}
+
+func (s *AdapterTestSuite) TestSeedPoliciesClosesAdapter() {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	before := webhookGoroutines()
+	err := SeedPolicies(context.Background(), s.a.db, map[string][][]string{"p": {{"carol", "data1", "read"}}}, WithWebhook(srv.URL, "key"))
+	s.Require().NoError(err)
+	s.Require().Equal(before, webhookGoroutines(), "the webhook of the seeding adapter is stopped")
+
+	// The pool of the caller is left open.
+	s.Require().NoError(s.a.db.Ping(context.Background()))
+}
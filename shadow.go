@@ -0,0 +1,87 @@
+package pgadapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// shadowTableSuffix is appended to the name of the rules table to name the shadow table used by SaveShadowSwap.
+const shadowTableSuffix = "_new"
+
+// checkShadowSwap returns an error if options SaveShadowSwap doesn't support are combined with it.
+// The swap replaces the rules table by a plain table created with CREATE TABLE LIKE, so the options
+// making the adapter work on a view or another kind of table, or relying on triggers, can't be used.
+func (a *Adapter) checkShadowSwap() error {
+	if a.saveStrategy != SaveShadowSwap {
+		return nil
+	}
+	var unsupported []string
+	for name, set := range map[string]bool{
+		"WithColumnMapping":     len(a.columns) > 0,
+		"WithSoftDelete":        a.softDelete,
+		"WithTimestamps":        a.timestamps,
+		"WithTenant":            a.tenantScoped,
+		"WithPartitioning":      a.partitioning != nil,
+		"WithSurrogateKey":      a.surrogateKey != 0,
+		"WithJSONBStorage":      a.jsonb,
+		"WithGeneratedID":       a.generatedID,
+		"WithNormalizedStorage": a.normalization != nil,
+	} {
+		if set {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	sort.Strings(unsupported)
+	return fmt.Errorf("%s can't be used with WithSaveStrategy(SaveShadowSwap)", strings.Join(unsupported, ", "))
+}
+
+// swapInRules writes lines to a shadow table with COPY and swaps it with the rules table within tx.
+// The rules table is only locked for the renames at the end of the transaction.
+func (a *Adapter) swapInRules(tx *pg.Tx, lines []*CasbinRule) error {
+	table := a.tableName
+	shadow := table + shadowTableSuffix
+	old := table + "_old"
+
+	_, err := tx.Exec("DROP TABLE IF EXISTS ?; CREATE TABLE ? (LIKE ? INCLUDING ALL)",
+		pg.Ident(shadow), pg.Ident(shadow), pg.Ident(table))
+	if err != nil {
+		return err
+	}
+
+	if err := copyRules(tx, shadow, lines); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("LOCK TABLE ? IN ACCESS EXCLUSIVE MODE", pg.Ident(table))
+	if err != nil {
+		return err
+	}
+	if a.ruleOrder {
+		// The shadow table shares the seq sequence, which must survive dropping the old table.
+		var seq string
+		_, err = tx.QueryOne(pg.Scan(&seq), "SELECT pg_get_serial_sequence(?, 'seq')", quoteQualified(table))
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("ALTER SEQUENCE ? OWNED BY ?.seq", pg.Safe(seq), pg.Ident(shadow))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec("ALTER TABLE ? RENAME TO ?; ALTER TABLE ? RENAME TO ?; DROP TABLE ?",
+		pg.Ident(table), pg.Ident(unqualified(old)),
+		pg.Ident(shadow), pg.Ident(unqualified(table)),
+		pg.Ident(old))
+	return err
+}
+
+// unqualified returns name without its schema, as expected by ALTER TABLE RENAME TO.
+func unqualified(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
@@ -0,0 +1,106 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// DefaultSnapshotTableName is the table holding the snapshots, see WithSnapshots.
+const DefaultSnapshotTableName = "casbin_rule_snapshot"
+
+// Snapshot is a named copy of the rules of a rules table, see SnapshotPolicy.
+type Snapshot struct {
+	tableName struct{} `pg:"casbin_rule_snapshot"`
+	RuleTable string   `pg:",pk"`
+	Name      string   `pg:",pk"`
+	// Rules are the rules in canonical order, each one starting with its ptype.
+	Rules     [][]string
+	Actor     string
+	CreatedAt time.Time `pg:"default:now(),notnull"`
+}
+
+// WithSnapshots creates the snapshot table, see SnapshotPolicy.
+func WithSnapshots() Option {
+	return func(a *Adapter) {
+		a.snapshots = true
+	}
+}
+
+func (a *Adapter) createSnapshotTable() error {
+	return a.conn().Model((*Snapshot)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+}
+
+// SnapshotPolicy stores a copy of all rules as the snapshot name, e.g. before a risky change,
+// which LoadSnapshot restores. The actor is the one of ContextWithActor or WithActor.
+// The names are unique per rules table, taking an existing name fails. It requires WithSnapshots.
+func (a *Adapter) SnapshotPolicy(ctx context.Context, name string) (err error) {
+	defer a.handleError(OpSnapshotPolicy, "", 0, &err)
+
+	lines, err := a.canonicalRules(ctx)
+	if err != nil {
+		return err
+	}
+	snapshot := &Snapshot{RuleTable: a.tableName, Name: name, Rules: make([][]string, 0, len(lines)), Actor: a.actorOf(ctx)}
+	for _, line := range lines {
+		snapshot.Rules = append(snapshot.Rules, line.ptypeRule())
+	}
+	res, err := a.conn().ModelContext(ctx, snapshot).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("snapshot %q already exists", name)
+	}
+	return nil
+}
+
+// ListSnapshots returns the snapshots of the rules table from the oldest to the newest, without their rules.
+func (a *Adapter) ListSnapshots(ctx context.Context) (_ []*Snapshot, err error) {
+	defer a.handleError(OpListSnapshots, "", 0, &err)
+
+	var snapshots []*Snapshot
+	err = a.conn().ModelContext(ctx, &snapshots).ExcludeColumn("rules").
+		Where("rule_table = ?", a.tableName).Order("created_at", "name").Select()
+	return snapshots, err
+}
+
+// LoadSnapshot makes the table store exactly the rules of the snapshot name, like SyncPolicy,
+// in a single transaction, and reports the changes. A missing snapshot fails with ErrNotFound.
+func (a *Adapter) LoadSnapshot(ctx context.Context, name string) (_ *SyncReport, err error) {
+	snapshot := &Snapshot{RuleTable: a.tableName, Name: name}
+	if err := a.conn().ModelContext(ctx, snapshot).WherePK().Select(); err != nil {
+		err = fmt.Errorf("snapshot %q: %w", name, err)
+		a.handleError(OpLoadSnapshot, "", 0, &err)
+		return nil, err
+	}
+	return a.syncRules(ctx, rulesByPtype(snapshot.Rules))
+}
+
+// DeleteSnapshot deletes the snapshot name, a missing snapshot fails with ErrNotFound.
+func (a *Adapter) DeleteSnapshot(ctx context.Context, name string) (err error) {
+	defer a.handleError(OpDeleteSnapshot, "", 0, &err)
+
+	res, err := a.conn().ModelContext(ctx, &Snapshot{RuleTable: a.tableName, Name: name}).WherePK().Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("snapshot %q: %w", name, pg.ErrNoRows)
+	}
+	return nil
+}
+
+// rulesByPtype groups rules, which start with their ptype, by ptype.
+func rulesByPtype(rules [][]string) map[string][][]string {
+	byPtype := make(map[string][][]string)
+	for _, rule := range rules {
+		byPtype[rule[0]] = append(byPtype[rule[0]], rule[1:])
+	}
+	return byPtype
+}
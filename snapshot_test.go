@@ -0,0 +1,35 @@
+package pgadapter
+
+import (
+	"context"
+)
+
+func (s *AdapterTestSuite) TestSnapshots() {
+	ctx := context.Background()
+	a, err := NewAdapterByDB(s.a.db, WithSnapshots())
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.SnapshotPolicy(ctx, "before-cleanup"))
+	s.Require().Error(a.SnapshotPolicy(ctx, "before-cleanup"), "the names are unique")
+
+	s.Require().NoError(a.RemoveFilteredPolicy("p", "p", 0, "alice"))
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+
+	report, err := a.LoadSnapshot(ctx, "before-cleanup")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "alice", "data1", "read"}}, report.Added)
+	s.Require().Equal([][]string{{"p", "carol", "data1", "read"}}, report.Removed)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy([][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}}, s.e.GetPolicy())
+
+	snapshots, err := a.ListSnapshots(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(snapshots, 1)
+	s.Require().Equal("before-cleanup", snapshots[0].Name)
+	s.Require().Nil(snapshots[0].Rules)
+
+	s.Require().NoError(a.DeleteSnapshot(ctx, "before-cleanup"))
+	_, err = a.LoadSnapshot(ctx, "before-cleanup")
+	s.Require().ErrorIs(err, ErrNotFound)
+}
@@ -0,0 +1,76 @@
+package pgadapter
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// parseSocketURL removes the Unix socket directory from connURL, which go-pg can't parse, and returns the URL left
+// and the path of the socket, empty for TCP. Like with libpq, the directory is given as the host parameter,
+// e.g. postgres://user@/casbin?host=/var/run/postgresql, or as the percent-encoded host,
+// e.g. postgres://user@%2Fvar%2Frun%2Fpostgresql/casbin, the port parameter selecting the socket of another port.
+func parseSocketURL(connURL string) (string, string, error) {
+	dir := ""
+	if i := strings.Index(connURL, "://"); i >= 0 {
+		rest := connURL[i+3:]
+		end := strings.IndexAny(rest, "/?")
+		if end < 0 {
+			end = len(rest)
+		}
+		start := strings.LastIndex(rest[:end], "@") + 1
+		if host := rest[start:end]; strings.HasPrefix(strings.ToLower(host), "%2f") {
+			var err error
+			if dir, err = url.PathUnescape(host); err != nil {
+				return "", "", err
+			}
+			connURL = connURL[:i+3] + rest[:start] + rest[end:]
+		}
+	}
+
+	u, err := url.Parse(connURL)
+	if err != nil {
+		return "", "", err
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", "", err
+	}
+	if host := query.Get("host"); strings.HasPrefix(host, "/") {
+		dir = host
+	}
+	if dir == "" {
+		return connURL, "", nil
+	}
+	port := query.Get("port")
+	if port == "" {
+		port = "5432"
+	}
+	query.Del("host")
+	query.Del("port")
+	u.RawQuery = query.Encode()
+	return u.String(), socketPath(dir, port), nil
+}
+
+// socketPath returns the path of the socket Postgres listens to on port in dir.
+func socketPath(dir, port string) string {
+	return path.Join(dir, ".s.PGSQL."+port)
+}
+
+// applySocket makes opts connect to socket, a path returned by parseSocketURL.
+// The options connecting to a Unix socket may also name its directory, e.g. /var/run/postgresql,
+// the socket of port 5432 is used then. TLS isn't used on Unix sockets, like with libpq.
+func applySocket(opts *pg.Options, socket string) {
+	if socket != "" {
+		opts.Network, opts.Addr = "unix", socket
+	}
+	if opts.Network != "unix" {
+		return
+	}
+	if !strings.HasPrefix(path.Base(opts.Addr), ".s.PGSQL.") {
+		opts.Addr = socketPath(opts.Addr, "5432")
+	}
+	opts.TLSConfig = nil
+}
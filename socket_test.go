@@ -0,0 +1,39 @@
+package pgadapter
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketURL(t *testing.T) {
+	for _, connURL := range []string{
+		"postgres://casbin:secret@/casbin?host=/var/run/postgresql",
+		"postgres://casbin:secret@%2Fvar%2Frun%2Fpostgresql/casbin",
+		"postgres://casbin:secret@%2Fvar%2Frun%2Fpostgresql/casbin?port=5432&pool_size=3",
+	} {
+		opts, _, err := connectOptions(connURL, &Adapter{tlsConfig: &tls.Config{}})
+		require.NoError(t, err, connURL)
+		require.Equal(t, "unix", opts.Network, connURL)
+		require.Equal(t, "/var/run/postgresql/.s.PGSQL.5432", opts.Addr, connURL)
+		require.Equal(t, "casbin", opts.User, connURL)
+		require.Equal(t, "casbin", opts.Database, connURL)
+		require.Nil(t, opts.TLSConfig, connURL)
+		require.Nil(t, opts.Dialer, connURL)
+	}
+
+	opts, _, err := connectOptions("postgres://casbin@/casbin?host=/tmp&port=6432", nil)
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/.s.PGSQL.6432", opts.Addr)
+
+	opts, _, err = connectOptions(&pg.Options{Network: "unix", Addr: "/var/run/postgresql"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "/var/run/postgresql/.s.PGSQL.5432", opts.Addr)
+
+	opts, _, err = connectOptions("postgres://casbin@localhost:5432/casbin?sslmode=disable", nil)
+	require.NoError(t, err)
+	require.Equal(t, "localhost:5432", opts.Addr)
+	require.Empty(t, opts.Network)
+}
@@ -0,0 +1,68 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// WithSoftDelete makes the removals stamp the rules with their deletion time instead of deleting them,
+// so removed grants are retained until PurgeDeleted deletes them. The rules table gets a deleted_at column
+// and the adapter works on a view of its live rules named <table>_live, like with WithColumnMapping, whose deletes
+// a trigger turns into updates. The ID of a removed rule gets a "@<deletion time>" suffix, so the rule can be
+// added again. Updates change the rules in place and SavePolicy only stamps the rules missing from the model,
+// unless WithRuleOrder is set, in which case it removes all the rules before inserting them again.
+// Like with WithColumnMapping, options adding columns or indexes to the rules table can't be combined with it.
+func WithSoftDelete() Option {
+	return func(a *Adapter) {
+		a.softDelete = true
+	}
+}
+
+// softDeleteTriggerSQL returns the statements creating the trigger turning the deletes of the view
+// into updates of its table, see WithSoftDelete. The trigger of a tenant's view only updates the rules of the tenant.
+func (v ruleView) softDeleteTriggerSQL() []string {
+	id := quoteIdent(mappedColumn(v.columns, "id"))
+	view := quoteQualified(v.name())
+	function := quoteQualified(v.table + "_soft_delete")
+	var tenant string
+	if v.scoped {
+		tenant = quoteLiteral(v.tenant)
+	}
+	return []string{
+		"CREATE OR REPLACE FUNCTION " + function + "() RETURNS trigger AS $$\n" +
+			"BEGIN\n" +
+			"\tUPDATE " + quoteQualified(v.table) + " SET deleted_at = now(), " +
+			id + " = " + id + " || '@' || to_char(clock_timestamp(), 'YYYYMMDDHH24MISSUS')\n" +
+			"\tWHERE " + id + " = OLD.id AND deleted_at IS NULL AND (TG_NARGS = 0 OR tenant_id = TG_ARGV[0]);\n" +
+			"\tRETURN OLD;\n" +
+			"END $$ LANGUAGE plpgsql",
+		"DROP TRIGGER IF EXISTS soft_delete ON " + view,
+		"CREATE TRIGGER soft_delete INSTEAD OF DELETE ON " + view + " FOR EACH ROW EXECUTE PROCEDURE " + function + "(" + tenant + ")",
+	}
+}
+
+// PurgeDeleted deletes the rules removed before the given time, e.g. time.Now().AddDate(0, 0, -90),
+// and returns their number. With WithTenant, only the rules of the tenant are deleted. It requires WithSoftDelete.
+func (a *Adapter) PurgeDeleted(ctx context.Context, before time.Time) (n int, err error) {
+	defer a.handleError(OpPurgeDeleted, "", 0, &err)
+
+	if !a.softDelete {
+		return 0, errors.New("soft delete is not enabled, see WithSoftDelete")
+	}
+	if err := a.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	query, args := "DELETE FROM ? WHERE deleted_at < ?", []interface{}{pg.Ident(a.mappedTable), before}
+	if a.tenantScoped {
+		query, args = query+" AND tenant_id = ?", append(args, a.tenant)
+	}
+	res, err := a.conn().ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,65 @@
+package pgadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-pg/pg/v10"
+)
+
+func (s *AdapterTestSuite) TestSoftDelete() {
+	ctx := context.Background()
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_soft"), WithSoftDelete())
+	s.Require().NoError(err)
+	s.Require().Equal("casbin_rule_soft_live", a.TableName())
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	_, err = e.AddPolicies([][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}})
+	s.Require().NoError(err)
+	_, err = e.RemovePolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+	_, err = e.RemoveFilteredPolicy(0, "bob")
+	s.Require().NoError(err)
+	// The removed rule can be added again.
+	_, err = e.AddPolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+
+	s.Require().NoError(e.LoadPolicy())
+	s.assertPolicy([][]string{{"alice", "data1", "read"}}, e.GetPolicy())
+
+	var deleted int
+	_, err = s.a.db.QueryOne(pg.Scan(&deleted), "SELECT count(*) FROM casbin_rule_soft WHERE deleted_at IS NOT NULL")
+	s.Require().NoError(err)
+	s.Require().Equal(2, deleted)
+
+	n, err := a.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(n)
+	n, err = a.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Require().Equal(2, n)
+}
+
+func (s *AdapterTestSuite) TestPurgeDeletedTenant() {
+	ctx := context.Background()
+	acme, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_soft_tenant"), WithSoftDelete(), WithTenant("acme"))
+	s.Require().NoError(err)
+	globex, err := acme.ForTenant("globex")
+	s.Require().NoError(err)
+
+	for _, a := range []*Adapter{acme, globex} {
+		s.Require().NoError(a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+		s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+	}
+
+	n, err := acme.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+
+	var tenant string
+	_, err = s.a.db.QueryOne(pg.Scan(&tenant), "SELECT tenant_id FROM casbin_rule_soft_tenant WHERE deleted_at IS NOT NULL")
+	s.Require().NoError(err)
+	s.Require().Equal("globex", tenant, "the removed rules of the other tenants are kept")
+}
@@ -0,0 +1,550 @@
+package pgadapter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+// sqlConn is a connection pool or a transaction of another driver SQLAdapter runs its statements on.
+// The statements use the $1 placeholder syntax.
+type sqlConn interface {
+	exec(ctx context.Context, query string, args ...interface{}) (int64, error)
+	queryRules(ctx context.Context, query string, args ...interface{}) ([]*CasbinRule, error)
+}
+
+// sqlPool is a connection pool of another driver.
+type sqlPool interface {
+	sqlConn
+	inTx(ctx context.Context, fn func(sqlConn) error) error
+}
+
+// SQLAdapter is an adapter running on the connection pool of another driver than go-pg,
+// see NewAdapterByPgxPool and NewAdapterByDBSql. It stores the rules with the same table layout and IDs as Adapter,
+// so both can be used on the same table, and implements the same Casbin adapter interfaces.
+// It supports the options WithTableName, SkipTableCreate, SkipDefaultIndexes, WithColumnMapping, WithSoftDelete,
+// WithActor, WithConnectRetry, WithChangePublisher, WithWebhook, WithErrorHook, WithBeforeWrite, WithAllowedPtypes
+// and WithCaseInsensitive, the other options are ignored.
+type SQLAdapter struct {
+	pool sqlPool
+	// cfg holds the supported options and provides the hooks, the publishing and the error handling.
+	cfg      *Adapter
+	table    string
+	filtered bool
+}
+
+var (
+	_ persist.BatchAdapter     = (*SQLAdapter)(nil)
+	_ persist.FilteredAdapter  = (*SQLAdapter)(nil)
+	_ persist.UpdatableAdapter = (*SQLAdapter)(nil)
+	_ ContextAdapter           = (*SQLAdapter)(nil)
+)
+
+func newSQLAdapter(pool sqlPool, opts ...Option) (*SQLAdapter, error) {
+	o := &Adapter{tableName: DefaultTableName}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	a := &SQLAdapter{
+		pool: pool,
+		cfg: &Adapter{
+			tableName:          o.tableName,
+			actor:              o.actor,
+			webhook:            o.webhook,
+			publishers:         o.publishers,
+			errorHook:          o.errorHook,
+			beforeWriteHooks:   o.beforeWriteHooks,
+			validators:         o.validators,
+			allowedPtypes:      o.allowedPtypes,
+			columns:            o.columns,
+			softDelete:         o.softDelete,
+			surrogateKey:       o.surrogateKey,
+			idFunc:             o.idFunc,
+			skipDefaultIndexes: o.skipDefaultIndexes,
+			caseInsensitive:    o.caseInsensitive,
+			connectAttempts:    o.connectAttempts,
+			connectBackoff:     o.connectBackoff,
+		},
+	}
+	var err error
+	if o.tenantScoped {
+		// The tenant of the inserted rules is set in the write transactions, which SQLAdapter doesn't set up.
+		err = fmt.Errorf("WithTenant is not supported by SQLAdapter")
+	} else if o.jsonb {
+		err = fmt.Errorf("WithJSONBStorage is not supported by SQLAdapter")
+	} else if o.generatedID {
+		err = fmt.Errorf("WithGeneratedID is not supported by SQLAdapter")
+	} else if o.normalization != nil {
+		err = fmt.Errorf("WithNormalizedStorage is not supported by SQLAdapter")
+	} else {
+		err = a.cfg.mapColumns()
+	}
+	if err == nil && !o.skipTableCreate {
+		err = a.cfg.retryConnect(a.createTable)
+	}
+	if err != nil {
+		a.cfg.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	a.table = quoteQualified(a.cfg.tableName)
+	return a, nil
+}
+
+func (a *SQLAdapter) createTable() error {
+	table := a.cfg.tableName
+	if a.cfg.mappedTable != "" {
+		table = a.cfg.mappedTable
+	}
+	stmts := []string{createRulesTableSQL(table, a.cfg.columns, a.cfg.surrogateKey)}
+	var viewStmts []string
+	if a.cfg.mappedTable != "" {
+		// The first statement creates the table, the view is created after the indexes.
+		viewStmts = a.cfg.ruleView().sql()[1:]
+	}
+	if !a.cfg.skipDefaultIndexes {
+		stmts = append(stmts, defaultIndexesSQL(table, a.cfg.columns)...)
+	}
+	stmts = append(stmts, viewStmts...)
+	for _, stmt := range stmts {
+		if _, err := a.pool.exec(context.Background(), stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the change delivery of the adapter, the connection pool is left open.
+func (a *SQLAdapter) Close() error {
+	return a.cfg.Close()
+}
+
+// TableName returns the name of the table the adapter works on, the view of the rules table
+// with WithColumnMapping or WithSoftDelete, see Adapter.TableName.
+func (a *SQLAdapter) TableName() string {
+	return a.cfg.tableName
+}
+
+// Revision returns the revision of the last change made through this adapter.
+func (a *SQLAdapter) Revision() uint64 {
+	return a.cfg.Revision()
+}
+
+// Subscribe is like Adapter.Subscribe.
+func (a *SQLAdapter) Subscribe(ctx context.Context) (<-chan PolicyChange, error) {
+	return a.cfg.Subscribe(ctx)
+}
+
+// SetWritable is like Adapter.SetWritable.
+func (a *SQLAdapter) SetWritable(writable bool) {
+	a.cfg.SetWritable(writable)
+}
+
+// IsWritable is like Adapter.IsWritable.
+func (a *SQLAdapter) IsWritable() bool {
+	return a.cfg.IsWritable()
+}
+
+// selectRules is the query loading the rules, empty values are stored as NULL like go-pg does.
+func (a *SQLAdapter) selectRules() string {
+	return "SELECT id, coalesce(ptype, ''), coalesce(v0, ''), coalesce(v1, ''), coalesce(v2, ''), " +
+		"coalesce(v3, ''), coalesce(v4, ''), coalesce(v5, '') FROM " + a.table
+}
+
+func (a *SQLAdapter) insert(ctx context.Context, conn sqlConn, line *CasbinRule) error {
+	_, err := conn.exec(ctx, "INSERT INTO "+a.table+" (id, ptype, v0, v1, v2, v3, v4, v5) "+
+		"VALUES ($1, $2, nullif($3, ''), nullif($4, ''), nullif($5, ''), nullif($6, ''), nullif($7, ''), nullif($8, '')) "+
+		"ON CONFLICT DO NOTHING",
+		line.ID, line.Ptype, line.V0, line.V1, line.V2, line.V3, line.V4, line.V5)
+	return err
+}
+
+// whereFields returns the predicate matching ptype and the non-empty values starting at column v<fieldIndex>
+// and its arguments.
+func whereFields(ptype string, fieldIndex int, values []string) (string, []interface{}, error) {
+	where := "ptype = $1"
+	args := []interface{}{ptype}
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		if fieldIndex+i > 5 {
+			return "", nil, ErrTooManyFields
+		}
+		args = append(args, v)
+		where += " AND " + valueColumn(fieldIndex+i) + " = $" + strconv.Itoa(len(args))
+	}
+	return where, args, nil
+}
+
+// LoadPolicy loads policy from database.
+func (a *SQLAdapter) LoadPolicy(model model.Model) error {
+	return a.LoadPolicyCtx(context.Background(), model)
+}
+
+// LoadPolicyCtx is like LoadPolicy, ctx bounds the database calls.
+func (a *SQLAdapter) LoadPolicyCtx(ctx context.Context, model model.Model) (err error) {
+	defer a.cfg.handleError(OpLoadPolicy, "", 0, &err)
+
+	lines, err := a.pool.queryRules(ctx, a.selectRules())
+	if err != nil {
+		return err
+	}
+	skipped := skippedRules{}
+	if err := a.loadLines(model, lines, skipped); err != nil {
+		return err
+	}
+	a.cfg.reportSkipped(OpLoadPolicy, skipped)
+	a.filtered = false
+	return nil
+}
+
+// loadLines loads lines into model, the rules whose ptype isn't allowed are counted in skipped.
+func (a *SQLAdapter) loadLines(model model.Model, lines []*CasbinRule, skipped skippedRules) error {
+	for _, line := range lines {
+		if !a.cfg.loadAllowed(line.Ptype, skipped) {
+			continue
+		}
+		if err := persist.LoadPolicyLine(line.String(), model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFilteredPolicy loads only policy rules that match the filter, which must be a *Filter without labels.
+func (a *SQLAdapter) LoadFilteredPolicy(model model.Model, filter interface{}) error {
+	return a.LoadFilteredPolicyCtx(context.Background(), model, filter)
+}
+
+// LoadFilteredPolicyCtx is like LoadFilteredPolicy, ctx bounds the database calls.
+func (a *SQLAdapter) LoadFilteredPolicyCtx(ctx context.Context, model model.Model, filter interface{}) (err error) {
+	defer a.cfg.handleError(OpLoadFilteredPolicy, "", 0, &err)
+
+	if filter == nil {
+		return a.LoadPolicyCtx(ctx, model)
+	}
+	f, ok := filter.(*Filter)
+	if !ok {
+		return fmt.Errorf("invalid filter type")
+	}
+	if len(f.Labels) > 0 {
+		return fmt.Errorf("label filters are not supported by SQLAdapter")
+	}
+	f = a.cfg.foldFilter(f)
+
+	skipped := skippedRules{}
+	for _, sec := range f.sections() {
+		where, args, err := whereFields(sec.ptype, 0, sec.values)
+		if err != nil {
+			return err
+		}
+		lines, err := a.pool.queryRules(ctx, a.selectRules()+" WHERE "+where, args...)
+		if err != nil {
+			return err
+		}
+		if err := a.loadLines(model, lines, skipped); err != nil {
+			return err
+		}
+	}
+	a.cfg.reportSkipped(OpLoadFilteredPolicy, skipped)
+	a.filtered = true
+	return nil
+}
+
+// IsFiltered returns true if the loaded policy has been filtered.
+func (a *SQLAdapter) IsFiltered() bool {
+	return a.filtered
+}
+
+// SavePolicy saves policy to database, replacing the stored rules.
+func (a *SQLAdapter) SavePolicy(model model.Model) error {
+	return a.SavePolicyCtx(context.Background(), model)
+}
+
+// SavePolicyCtx is like SavePolicy, ctx bounds the database calls.
+func (a *SQLAdapter) SavePolicyCtx(ctx context.Context, model model.Model) (err error) {
+	defer a.cfg.handleError(OpSavePolicy, "", 0, &err)
+
+	if err := a.cfg.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	var lines []*CasbinRule
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range model[sec] {
+			if err := a.cfg.beforeWrite(OpSavePolicy, sec, ptype, ast.Policy); err != nil {
+				return err
+			}
+			for _, rule := range ast.Policy {
+				lines = append(lines, a.cfg.ruleLine(ptype, rule))
+			}
+		}
+	}
+
+	// Unlike Adapter, all the rules are deleted and inserted again, so with WithSoftDelete every save
+	// stamps all the stored rules as deleted.
+	err = a.pool.inTx(ctx, func(conn sqlConn) error {
+		if _, err := conn.exec(ctx, "DELETE FROM "+a.table); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := a.insert(ctx, conn, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.cfg.changed(ctx, PolicyChange{Operation: OpSavePolicy})
+	return nil
+}
+
+// AddPolicy adds a policy rule to the storage.
+func (a *SQLAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return a.AddPolicyCtx(context.Background(), sec, ptype, rule)
+}
+
+// AddPolicyCtx is like AddPolicy, ctx bounds the database calls.
+func (a *SQLAdapter) AddPolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+	return a.addPolicies(ctx, OpAddPolicy, sec, ptype, [][]string{rule})
+}
+
+// AddPolicies adds policy rules to the storage.
+func (a *SQLAdapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	return a.AddPoliciesCtx(context.Background(), sec, ptype, rules)
+}
+
+// AddPoliciesCtx is like AddPolicies, ctx bounds the database calls.
+func (a *SQLAdapter) AddPoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	return a.addPolicies(ctx, OpAddPolicies, sec, ptype, rules)
+}
+
+func (a *SQLAdapter) addPolicies(ctx context.Context, op string, sec string, ptype string, rules [][]string) (err error) {
+	defer a.cfg.handleError(op, ptype, len(rules), &err)
+
+	if err := a.cfg.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.cfg.beforeWrite(op, sec, ptype, rules); err != nil {
+		return err
+	}
+
+	err = a.pool.inTx(ctx, func(conn sqlConn) error {
+		for _, rule := range rules {
+			if err := a.insert(ctx, conn, a.cfg.ruleLine(ptype, rule)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.cfg.changed(ctx, PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: rules})
+	return nil
+}
+
+// RemovePolicy removes a policy rule from the storage.
+func (a *SQLAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.RemovePolicyCtx(context.Background(), sec, ptype, rule)
+}
+
+// RemovePolicyCtx is like RemovePolicy, ctx bounds the database calls.
+func (a *SQLAdapter) RemovePolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+	return a.removePolicies(ctx, OpRemovePolicy, sec, ptype, [][]string{rule})
+}
+
+// RemovePolicies removes policy rules from the storage.
+func (a *SQLAdapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	return a.RemovePoliciesCtx(context.Background(), sec, ptype, rules)
+}
+
+// RemovePoliciesCtx is like RemovePolicies, ctx bounds the database calls.
+func (a *SQLAdapter) RemovePoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	return a.removePolicies(ctx, OpRemovePolicies, sec, ptype, rules)
+}
+
+func (a *SQLAdapter) removePolicies(ctx context.Context, op string, sec string, ptype string, rules [][]string) (err error) {
+	defer a.cfg.handleError(op, ptype, len(rules), &err)
+
+	if err := a.cfg.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.cfg.beforeWrite(op, sec, ptype, rules); err != nil {
+		return err
+	}
+
+	ids := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, a.cfg.ruleID(ptype, rule))
+	}
+	if len(ids) > 0 {
+		if _, err := a.pool.exec(ctx, "DELETE FROM "+a.table+" WHERE id IN ("+sqlPlaceholders(1, len(ids))+")", ids...); err != nil {
+			return err
+		}
+	}
+
+	a.cfg.changed(ctx, PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: rules})
+	return nil
+}
+
+// RemoveFilteredPolicy removes policy rules that match the filter from the storage.
+func (a *SQLAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.RemoveFilteredPolicyCtx(context.Background(), sec, ptype, fieldIndex, fieldValues...)
+}
+
+// RemoveFilteredPolicyCtx is like RemoveFilteredPolicy, ctx bounds the database calls.
+func (a *SQLAdapter) RemoveFilteredPolicyCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) (err error) {
+	defer a.cfg.handleError(OpRemoveFilteredPolicy, ptype, 0, &err)
+
+	if err := a.cfg.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.cfg.beforeWrite(OpRemoveFilteredPolicy, sec, ptype, [][]string{fieldValues}); err != nil {
+		return err
+	}
+
+	where, args, err := whereFields(ptype, fieldIndex, a.cfg.fold(fieldValues))
+	if err != nil {
+		return err
+	}
+	removed, err := a.pool.queryRules(ctx, "DELETE FROM "+a.table+" WHERE "+where+
+		" RETURNING id, coalesce(ptype, ''), coalesce(v0, ''), coalesce(v1, ''), coalesce(v2, ''), "+
+		"coalesce(v3, ''), coalesce(v4, ''), coalesce(v5, '')", args...)
+	if err != nil {
+		return err
+	}
+
+	change := PolicyChange{Operation: OpRemoveFilteredPolicy, Sec: sec, Ptype: ptype, Rules: [][]string{fieldValues}, FieldIndex: fieldIndex}
+	for _, line := range removed {
+		change.Removed = append(change.Removed, line.rule())
+	}
+	a.cfg.changed(ctx, change)
+	return nil
+}
+
+// UpdatePolicy updates a policy rule from storage.
+func (a *SQLAdapter) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return a.UpdatePolicyCtx(context.Background(), sec, ptype, oldRule, newRule)
+}
+
+// UpdatePolicyCtx is like UpdatePolicy, ctx bounds the database calls.
+func (a *SQLAdapter) UpdatePolicyCtx(ctx context.Context, sec string, ptype string, oldRule, newRule []string) error {
+	return a.updatePolicies(ctx, OpUpdatePolicy, sec, ptype, [][]string{oldRule}, [][]string{newRule})
+}
+
+// UpdatePolicies updates some policy rules to storage.
+func (a *SQLAdapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return a.UpdatePoliciesCtx(context.Background(), sec, ptype, oldRules, newRules)
+}
+
+// UpdatePoliciesCtx is like UpdatePolicies, ctx bounds the database calls.
+func (a *SQLAdapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype string, oldRules, newRules [][]string) error {
+	return a.updatePolicies(ctx, OpUpdatePolicies, sec, ptype, oldRules, newRules)
+}
+
+func (a *SQLAdapter) updatePolicies(ctx context.Context, op string, sec string, ptype string, oldRules, newRules [][]string) (err error) {
+	defer a.cfg.handleError(op, ptype, len(newRules), &err)
+
+	if len(oldRules) != len(newRules) {
+		return fmt.Errorf("the number of old and new rules differ: %d != %d", len(oldRules), len(newRules))
+	}
+	if err := a.cfg.checkWritable(ctx); err != nil {
+		return err
+	}
+	if err := a.cfg.beforeWrite(op, sec, ptype, newRules); err != nil {
+		return err
+	}
+
+	err = a.pool.inTx(ctx, func(conn sqlConn) error {
+		for i, rule := range newRules {
+			line := a.cfg.ruleLine(ptype, rule)
+			_, err := conn.exec(ctx, "UPDATE "+a.table+" SET id = $1, ptype = $2, v0 = nullif($3, ''), v1 = nullif($4, ''), "+
+				"v2 = nullif($5, ''), v3 = nullif($6, ''), v4 = nullif($7, ''), v5 = nullif($8, '') WHERE id = $9",
+				line.ID, line.Ptype, line.V0, line.V1, line.V2, line.V3, line.V4, line.V5, a.cfg.ruleID(ptype, oldRules[i]))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.cfg.changed(ctx, PolicyChange{Operation: op, Sec: sec, Ptype: ptype, Rules: newRules, OldRules: oldRules})
+	return nil
+}
+
+// UpdateFilteredPolicies deletes the rules matching the filter, adds newRules and returns the deleted rules.
+func (a *SQLAdapter) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	return a.UpdateFilteredPoliciesCtx(context.Background(), sec, ptype, newRules, fieldIndex, fieldValues...)
+}
+
+// UpdateFilteredPoliciesCtx is like UpdateFilteredPolicies, ctx bounds the database calls.
+func (a *SQLAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) (_ [][]string, err error) {
+	defer a.cfg.handleError(OpUpdateFilteredPolicies, ptype, len(newRules), &err)
+
+	if err := a.cfg.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.cfg.beforeWrite(OpUpdateFilteredPolicies, sec, ptype, newRules); err != nil {
+		return nil, err
+	}
+
+	where, args, err := whereFields(ptype, fieldIndex, a.cfg.fold(fieldValues))
+	if err != nil {
+		return nil, err
+	}
+	var oldRules [][]string
+	err = a.pool.inTx(ctx, func(conn sqlConn) error {
+		removed, err := conn.queryRules(ctx, "DELETE FROM "+a.table+" WHERE "+where+
+			" RETURNING id, coalesce(ptype, ''), coalesce(v0, ''), coalesce(v1, ''), coalesce(v2, ''), "+
+			"coalesce(v3, ''), coalesce(v4, ''), coalesce(v5, '')", args...)
+		if err != nil {
+			return err
+		}
+		oldRules = make([][]string, 0, len(removed))
+		for _, line := range removed {
+			oldRules = append(oldRules, line.rule())
+		}
+		for _, rule := range newRules {
+			if err := a.insert(ctx, conn, a.cfg.ruleLine(ptype, rule)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.cfg.changed(ctx, PolicyChange{
+		Operation:  OpUpdateFilteredPolicies,
+		Sec:        sec,
+		Ptype:      ptype,
+		Rules:      newRules,
+		OldRules:   oldRules,
+		FieldIndex: fieldIndex,
+	})
+	return oldRules, nil
+}
+
+// sqlPlaceholders returns the placeholders "$from, ..., $from+n-1", drivers of database/sql don't all accept slices
+// as arguments.
+func sqlPlaceholders(from, n int) string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = "$" + strconv.Itoa(from+i)
+	}
+	return strings.Join(p, ", ")
+}
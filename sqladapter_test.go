@@ -0,0 +1,22 @@
+package pgadapter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhereFields(t *testing.T) {
+	where, args, err := whereFields("p", 1, []string{"data1", "", "allow"})
+	require.NoError(t, err)
+	require.Equal(t, "ptype = $1 AND v1 = $2 AND v3 = $3", where)
+	require.Equal(t, []interface{}{"p", "data1", "allow"}, args)
+
+	_, _, err = whereFields("p", 5, []string{"a", "b"})
+	require.True(t, errors.Is(err, ErrTooManyFields))
+}
+
+func TestSQLPlaceholders(t *testing.T) {
+	require.Equal(t, "$2, $3, $4", sqlPlaceholders(2, 3))
+}
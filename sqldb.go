@@ -0,0 +1,67 @@
+package pgadapter
+
+import (
+	"context"
+	"database/sql"
+)
+
+// NewAdapterByDBSql creates an adapter running on a database/sql pool instead of go-pg, e.g. opened with
+// the lib/pq or the pgx stdlib driver, so the pool, its instrumentation and driver wrappers can be reused.
+// The driver must accept the $1 placeholder syntax of Postgres. The pool is shared: closing the adapter leaves it open.
+// See SQLAdapter for the supported options.
+func NewAdapterByDBSql(db *sql.DB, opts ...Option) (*SQLAdapter, error) {
+	return newSQLAdapter(sqlDBPool{sqlDBConn: sqlDBConn{q: db}, db: db}, opts...)
+}
+
+// sqlQuerier is implemented by sql.DB and sql.Tx.
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type sqlDBConn struct {
+	q sqlQuerier
+}
+
+func (c sqlDBConn) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	res, err := c.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (c sqlDBConn) queryRules(ctx context.Context, query string, args ...interface{}) ([]*CasbinRule, error) {
+	rows, err := c.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*CasbinRule
+	for rows.Next() {
+		line := &CasbinRule{}
+		if err := rows.Scan(&line.ID, &line.Ptype, &line.V0, &line.V1, &line.V2, &line.V3, &line.V4, &line.V5); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+type sqlDBPool struct {
+	sqlDBConn
+	db *sql.DB
+}
+
+func (p sqlDBPool) inTx(ctx context.Context, fn func(sqlConn) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(sqlDBConn{q: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
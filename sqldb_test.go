@@ -0,0 +1,69 @@
+package pgadapter
+
+import (
+	"context"
+	"os"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+func (s *AdapterTestSuite) TestDBSql() {
+	cfg, err := pgx.ParseConfig(os.Getenv("PG_CONN"))
+	s.Require().NoError(err)
+	cfg.Database = s.a.db.Options().Database
+	db := stdlib.OpenDB(*cfg)
+	defer db.Close()
+
+	a, err := NewAdapterByDBSql(db)
+	s.Require().NoError(err)
+	defer a.Close()
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.assertPolicy(s.e.GetPolicy(), e.GetPolicy())
+
+	_, err = e.AddPolicies([][]string{{"carol", "data1", "read"}, {"dave", "data1", "read"}})
+	s.Require().NoError(err)
+	_, err = e.RemovePolicies([][]string{{"alice", "data1", "read"}, {"dave", "data1", "read"}})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy(
+		[][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data1", "read"}},
+		s.e.GetPolicy(),
+	)
+
+	s.Require().NoError(e.SavePolicy())
+	ok, err := s.a.HasPolicy(context.Background(), "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok)
+}
+
+func (s *AdapterTestSuite) TestDBSqlCaseInsensitive() {
+	cfg, err := pgx.ParseConfig(os.Getenv("PG_CONN"))
+	s.Require().NoError(err)
+	cfg.Database = s.a.db.Options().Database
+	db := stdlib.OpenDB(*cfg)
+	defer db.Close()
+
+	a, err := NewAdapterByDBSql(db, WithCaseInsensitive())
+	s.Require().NoError(err)
+	defer a.Close()
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"Carol", "data1", "read"}))
+	ok, err := s.a.HasPolicy(context.Background(), "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().True(ok, "the rules are lowercased like Adapter does")
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	s.Require().NoError(e.LoadFilteredPolicy(&Filter{P: []string{"CAROL"}}))
+	s.assertPolicy([][]string{{"carol", "data1", "read"}}, e.GetPolicy())
+
+	s.Require().NoError(a.RemoveFilteredPolicy("p", "p", 0, "CaRoL"))
+	ok, err = s.a.HasPolicy(context.Background(), "p", []string{"carol", "data1", "read"})
+	s.Require().NoError(err)
+	s.Require().False(ok)
+}
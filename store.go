@@ -0,0 +1,52 @@
+package pgadapter
+
+import (
+	"context"
+	"io"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+//go:generate mockgen -destination=mocks/mock_policy_store.go -package=mocks . PolicyStore
+
+// PolicyStore is the behavior exported by Adapter that doesn't depend on Postgres: the Casbin adapter interfaces,
+// the reads and writes of rules, their export, the change notifications and the maintenance flag.
+// The operations taking SQL, like QueryRules, administering the table, like Vacuum, or requiring an option,
+// like SnapshotPolicy, are only provided by *Adapter.
+// Services can depend on it instead of *Adapter and use mocks.MockPolicyStore or Fake in unit tests.
+type PolicyStore interface {
+	persist.BatchAdapter
+	persist.FilteredAdapter
+	persist.UpdatableAdapter
+	ContextAdapter
+
+	Close() error
+
+	AddPoliciesReturning(sec string, ptype string, rules [][]string) ([][]string, error)
+	RemoveFilteredPolicyReturning(sec string, ptype string, fieldIndex int, fieldValues ...string) ([][]string, error)
+	GetPolicyByID(ctx context.Context, id string) (ptype string, rule []string, err error)
+	IsEmpty(ctx context.Context) (bool, error)
+	HasPolicy(ctx context.Context, ptype string, rule []string) (bool, error)
+	GetFilteredPolicies(ptype string, fieldIndex int, fieldValues ...string) ([][]string, error)
+	ExportPolicies(ctx context.Context, w io.Writer) error
+
+	Revision() uint64
+	Subscribe(ctx context.Context) (<-chan PolicyChange, error)
+
+	SetWritable(writable bool)
+	IsWritable() bool
+	SetClusterWritable(ctx context.Context, writable bool) error
+}
+
+var _ PolicyStore = (*Adapter)(nil)
+
+// ContextAdapter is the context-aware counterpart of persist.Adapter, it matches persist.ContextAdapter
+// of the Casbin versions providing it. The context bounds the database calls of the operation.
+type ContextAdapter interface {
+	LoadPolicyCtx(ctx context.Context, model model.Model) error
+	SavePolicyCtx(ctx context.Context, model model.Model) error
+	AddPolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error
+	RemovePolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error
+	RemoveFilteredPolicyCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) error
+}
@@ -0,0 +1,20 @@
+package pgadapter_test
+
+import (
+	"testing"
+
+	pgadapter "github.com/casbin/casbin-pg-adapter"
+	"github.com/casbin/casbin-pg-adapter/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockPolicyStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	m := mocks.NewMockPolicyStore(ctrl)
+	m.EXPECT().AddPolicy("p", "p", []string{"alice", "data1", "read"}).Return(pgadapter.ErrReadOnly)
+
+	var store pgadapter.PolicyStore = m
+	require.ErrorIs(t, store.AddPolicy("p", "p", []string{"alice", "data1", "read"}), pgadapter.ErrReadOnly)
+}
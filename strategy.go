@@ -0,0 +1,113 @@
+package pgadapter
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// SaveStrategy selects how SavePolicy treats the rules already stored in the table.
+type SaveStrategy int
+
+const (
+	// SaveReplace replaces the stored rules with the rules of the model, it is the default.
+	// Only the missing rules are inserted and the others deleted, so the table is never empty
+	// and the unchanged rows, with their timestamps, are left alone.
+	// When several instances save divergent models the last writer wins.
+	SaveReplace SaveStrategy = iota
+	// SaveMergeUnion inserts the rules of the model and keeps the stored ones, so no rule is ever lost.
+	SaveMergeUnion
+	// SaveFailIfChanged replaces the stored rules like SaveReplace but fails with ErrConflict
+	// if the table has been changed by another adapter since this one last loaded or saved the policy.
+	// Changes are detected with a version counter that every adapter using this strategy maintains,
+	// all writers of the table must use it.
+	SaveFailIfChanged
+	// SaveShadowSwap replaces the stored rules like SaveReplace, but instead of deleting and inserting
+	// the rows it copies the rules into a new table and swaps it with the rules table by renaming them,
+	// so concurrent writers are only blocked for the renames and the table doesn't bloat.
+	// Grants and triggers of the rules table are not carried over to the new table,
+	// and views referencing the rules table must be recreated after each save. It can't be combined with
+	// the options making the adapter work on a view or another kind of table, nor with the options relying
+	// on triggers of the rules table, NewAdapterByDB returns an error.
+	SaveShadowSwap
+)
+
+// DefaultVersionTableName is the table holding the version counters used by SaveFailIfChanged.
+const DefaultVersionTableName = "casbin_table_version"
+
+// tableVersion is a row of the version table, one per rules table.
+type tableVersion struct {
+	tableName struct{} `pg:"casbin_table_version"`
+	RuleTable string   `pg:",pk"`
+	Version   int64    `pg:",use_zero,notnull"`
+}
+
+// WithSaveStrategy sets the SaveStrategy used by SavePolicy.
+func WithSaveStrategy(strategy SaveStrategy) Option {
+	return func(a *Adapter) {
+		a.saveStrategy = strategy
+	}
+}
+
+func (a *Adapter) createVersionTable() error {
+	err := a.conn().Model((*tableVersion)(nil)).CreateTable(&orm.CreateTableOptions{
+		IfNotExists: true,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Model(&tableVersion{RuleTable: a.tableName}).OnConflict("DO NOTHING").Insert()
+	return err
+}
+
+// loadVersion remembers the current version of the table, it is called when loading the policy.
+func (a *Adapter) loadVersion(ctx context.Context) error {
+	if a.saveStrategy != SaveFailIfChanged {
+		return nil
+	}
+
+	v := &tableVersion{RuleTable: a.tableName}
+	if err := a.conn().ModelContext(ctx, v).WherePK().Select(); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&a.version, v.Version)
+	return nil
+}
+
+// bumpVersion increments the version of the table within tx.
+// The known version follows along unless another writer changed the table in between.
+func (a *Adapter) bumpVersion(tx *pg.Tx) error {
+	if a.saveStrategy != SaveFailIfChanged {
+		return nil
+	}
+
+	v := &tableVersion{RuleTable: a.tableName}
+	_, err := tx.Model(v).
+		Set("version = version + 1").
+		WherePK().
+		Returning("version").
+		Update()
+	if err != nil {
+		return err
+	}
+	atomic.CompareAndSwapInt64(&a.version, v.Version-1, v.Version)
+	return nil
+}
+
+// checkVersion locks the version row within tx and returns ErrConflict if another writer changed the table.
+func (a *Adapter) checkVersion(tx *pg.Tx) error {
+	if a.saveStrategy != SaveFailIfChanged {
+		return nil
+	}
+
+	v := &tableVersion{RuleTable: a.tableName}
+	if err := tx.Model(v).WherePK().For("UPDATE").Select(); err != nil {
+		return err
+	}
+	if v.Version != atomic.LoadInt64(&a.version) {
+		return ErrConflict
+	}
+	return nil
+}
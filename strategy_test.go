@@ -0,0 +1,140 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckShadowSwap(t *testing.T) {
+	a := &Adapter{}
+	WithSaveStrategy(SaveShadowSwap)(a)
+	require.NoError(t, a.checkShadowSwap())
+
+	for name, opt := range map[string]Option{
+		"WithColumnMapping":     WithColumnMapping(map[string]string{"ptype": "p_type"}),
+		"WithSoftDelete":        WithSoftDelete(),
+		"WithTimestamps":        WithTimestamps(),
+		"WithTenant":            WithTenant("acme"),
+		"WithPartitioning":      WithPartitioning(Partitioning{}),
+		"WithSurrogateKey":      WithSurrogateKey(SurrogateBigserial),
+		"WithJSONBStorage":      WithJSONBStorage(),
+		"WithGeneratedID":       WithGeneratedID(),
+		"WithNormalizedStorage": WithNormalizedStorage(Normalization{}),
+	} {
+		a := &Adapter{}
+		opt(a)
+		require.NoError(t, a.checkShadowSwap(), "the options are only checked with SaveShadowSwap")
+		WithSaveStrategy(SaveShadowSwap)(a)
+		require.EqualError(t, a.checkShadowSwap(), name+" can't be used with WithSaveStrategy(SaveShadowSwap)")
+	}
+
+	// The options are checked before the adapter touches the database.
+	_, err := NewAdapterByDB(nil, WithSaveStrategy(SaveShadowSwap), WithPtypeColumn("p_type"))
+	require.EqualError(t, err, "pgadapter.NewAdapter: WithColumnMapping can't be used with WithSaveStrategy(SaveShadowSwap)")
+}
+
+func (s *AdapterTestSuite) TestSaveStrategyReplaceKeepsUnchangedRows() {
+	ctx := context.Background()
+	a, err := NewAdapterByDB(s.a.db, WithTimestamps())
+	s.Require().NoError(err)
+
+	before, err := a.ListPolicies(ctx, &Filter{P: []string{"alice"}})
+	s.Require().NoError(err)
+	s.Require().Len(before, 1)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	s.Require().NoError(err)
+	_, err = e.RemovePolicy("bob", "data2", "write")
+	s.Require().NoError(err)
+	_, err = e.AddPolicy("carol", "data3", "read")
+	s.Require().NoError(err)
+	s.Require().NoError(a.SavePolicy(e.GetModel()))
+
+	after, err := a.ListPolicies(ctx, &Filter{P: []string{"alice"}})
+	s.Require().NoError(err)
+	s.Require().Len(after, 1)
+	s.Require().True(after[0].CreatedAt.Equal(before[0].CreatedAt), "the unchanged rule was rewritten")
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.Assert().ElementsMatch(
+		[][]string{{"alice", "data1", "read"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data3", "read"}},
+		s.e.GetPolicy(),
+	)
+}
+
+func (s *AdapterTestSuite) TestSaveStrategyMergeUnion() {
+	a, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveMergeUnion))
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf")
+	s.Require().NoError(err)
+	_, err = e.AddPolicy("carol", "data3", "read")
+	s.Require().NoError(err)
+
+	err = a.SavePolicy(e.GetModel())
+	s.Require().NoError(err)
+
+	err = s.e.LoadPolicy()
+	s.Require().NoError(err)
+	s.assertPolicy(
+		[][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data3", "read"}},
+		s.e.GetPolicy(),
+	)
+}
+
+func (s *AdapterTestSuite) TestSaveStrategyFailIfChanged() {
+	a1, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveFailIfChanged))
+	s.Require().NoError(err)
+	a2, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveFailIfChanged))
+	s.Require().NoError(err)
+
+	e1, err := casbin.NewEnforcer("examples/rbac_model.conf", a1)
+	s.Require().NoError(err)
+	e2, err := casbin.NewEnforcer("examples/rbac_model.conf", a2)
+	s.Require().NoError(err)
+
+	// Own changes don't conflict.
+	_, err = e1.AddPolicy("carol", "data3", "read")
+	s.Require().NoError(err)
+	err = e1.SavePolicy()
+	s.Require().NoError(err)
+
+	// e2 loaded the policy before e1 changed it.
+	err = e2.SavePolicy()
+	s.Require().ErrorIs(err, ErrConflict)
+
+	err = e2.LoadPolicy()
+	s.Require().NoError(err)
+	err = e2.SavePolicy()
+	s.Require().NoError(err)
+	s.assertPolicy(
+		[][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data3", "read"}},
+		e2.GetPolicy(),
+	)
+}
+
+func (s *AdapterTestSuite) TestSaveStrategyShadowSwap() {
+	a, err := NewAdapterByDB(s.a.db, WithSaveStrategy(SaveShadowSwap))
+	s.Require().NoError(err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	s.Require().NoError(err)
+	_, err = e.RemovePolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+	e.GetModel().AddPolicy("p", "p", []string{"carol", "data3", "read"})
+
+	// Save twice to make sure the shadow table can be created again.
+	s.Require().NoError(a.SavePolicy(e.GetModel()))
+	s.Require().NoError(a.SavePolicy(e.GetModel()))
+
+	err = s.e.LoadPolicy()
+	s.Require().NoError(err)
+	s.assertPolicy(
+		[][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data3", "read"}},
+		s.e.GetPolicy(),
+	)
+	s.Require().NoError(s.a.AddPolicy("p", "p", []string{"carol", "data3", "read"}), "duplicates are still ignored")
+}
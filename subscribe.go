@@ -0,0 +1,96 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const subscriptionBufferSize = 64
+
+type subscriptions struct {
+	mu     sync.Mutex
+	subs   map[chan PolicyChange]struct{}
+	closed bool
+	done   chan struct{}
+}
+
+// Subscribe returns a channel receiving every PolicyChange committed through this adapter and the adapters
+// derived from the same adapter, e.g. with WithTx, WithTable or ForTenant, like the change publishers.
+// The changes made by other processes aren't delivered, see NewWatcher to be notified of them.
+// The channel is closed when ctx is done or the adapter it was derived from is closed.
+// Changes are dropped for a subscriber whose buffer is full, so slow consumers don't block writes.
+func (a *Adapter) Subscribe(ctx context.Context) (<-chan PolicyChange, error) {
+	return a.subscriptions().subscribe(ctx)
+}
+
+// subscriptions returns the subscriptions of the adapter a was derived from, they are shared by its siblings.
+func (a *Adapter) subscriptions() *subscriptions {
+	for a.parent != nil {
+		a = a.parent
+	}
+	return &a.subs
+}
+
+func (s *subscriptions) subscribe(ctx context.Context) (<-chan PolicyChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, errors.New("adapter is closed")
+	}
+	if s.subs == nil {
+		s.subs = make(map[chan PolicyChange]struct{})
+		s.done = make(chan struct{})
+	}
+
+	ch := make(chan PolicyChange, subscriptionBufferSize)
+	s.subs[ch] = struct{}{}
+
+	done := s.done
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.remove(ch)
+		case <-done:
+		}
+	}()
+
+	return ch, nil
+}
+
+func (s *subscriptions) remove(ch chan PolicyChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[ch]; ok {
+		delete(s.subs, ch)
+		close(ch)
+	}
+}
+
+func (s *subscriptions) publish(change PolicyChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+func (s *subscriptions) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for ch := range s.subs {
+		close(ch)
+	}
+	s.subs = nil
+	if s.done != nil {
+		close(s.done)
+	}
+}
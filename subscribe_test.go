@@ -0,0 +1,59 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	a := &Adapter{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := a.Subscribe(ctx)
+	require.NoError(t, err)
+
+	a.changed(context.Background(), PolicyChange{Operation: OpAddPolicies, Ptype: "p", Rules: [][]string{{"alice", "data1", "read"}}})
+	change := <-ch
+	require.Equal(t, OpAddPolicies, change.Operation)
+	require.Equal(t, uint64(1), change.Revision)
+
+	cancel()
+	for range ch {
+	}
+
+	ch, err = a.Subscribe(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, a.Close())
+	_, ok := <-ch
+	require.False(t, ok)
+
+	_, err = a.Subscribe(context.Background())
+	require.Error(t, err)
+}
+
+func TestSubscribeSiblings(t *testing.T) {
+	a := &Adapter{}
+	ch, err := a.Subscribe(context.Background())
+	require.NoError(t, err)
+
+	b := a.WithTx(&pg.Tx{})
+	b.changed(context.Background(), PolicyChange{Operation: OpAddPolicy, Ptype: "p"})
+	require.Equal(t, OpAddPolicy, (<-ch).Operation)
+
+	chB, err := b.Subscribe(context.Background())
+	require.NoError(t, err)
+	a.changed(context.Background(), PolicyChange{Operation: OpRemovePolicy, Ptype: "p"})
+	require.Equal(t, OpRemovePolicy, (<-chB).Operation)
+	require.Equal(t, OpRemovePolicy, (<-ch).Operation)
+
+	// Closing the sibling leaves the subscriptions of a open.
+	require.NoError(t, b.Close())
+	a.changed(context.Background(), PolicyChange{Operation: OpUpdatePolicy, Ptype: "p"})
+	require.Equal(t, OpUpdatePolicy, (<-ch).Operation)
+	require.NoError(t, a.Close())
+	for range chB {
+	}
+}
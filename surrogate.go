@@ -0,0 +1,59 @@
+package pgadapter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SurrogateKey is the type of the surrogate primary key of the rules table, see WithSurrogateKey.
+type SurrogateKey int
+
+const (
+	// SurrogateBigserial makes the id column a bigserial.
+	SurrogateBigserial SurrogateKey = iota + 1
+	// SurrogateUUID makes the id column a uuid set by gen_random_uuid(), which requires Postgres 13 or later.
+	SurrogateUUID
+)
+
+// ruleIDColumn is the column holding the IDs computed from the rules with WithSurrogateKey.
+const ruleIDColumn = "rule_id"
+
+// WithSurrogateKey makes the primary key of the rules table the id column of type key, which never changes
+// for a row, e.g. for admin tools expecting stable numeric IDs. The IDs the adapter computes from the rules
+// are stored in the rule_id column instead, which has a unique constraint preventing duplicated rules.
+// UpdatePolicy updates the rows in place, keeping their id. The adapter works on a view of the table
+// like with WithColumnMapping, the table must have this layout if it exists already.
+// It can't be combined with WithTenant nor WithPartitioning.
+func WithSurrogateKey(key SurrogateKey) Option {
+	return func(a *Adapter) {
+		a.surrogateKey = key
+	}
+}
+
+// mapSurrogateKey maps the id column to ruleIDColumn with WithSurrogateKey, unless it is mapped already.
+func (a *Adapter) mapSurrogateKey() error {
+	if a.surrogateKey == 0 {
+		return nil
+	}
+	if a.surrogateKey.columnSQL() == "" {
+		return fmt.Errorf("invalid surrogate key %d", a.surrogateKey)
+	}
+	if a.tenantScoped || a.partitioning != nil {
+		return errors.New("WithSurrogateKey can't be combined with WithTenant nor WithPartitioning")
+	}
+	if mappedColumn(a.columns, "id") == "id" {
+		WithColumnMapping(map[string]string{"id": ruleIDColumn})(a)
+	}
+	return nil
+}
+
+// columnSQL returns the definition of the id column of type k.
+func (k SurrogateKey) columnSQL() string {
+	switch k {
+	case SurrogateBigserial:
+		return `"id" bigserial PRIMARY KEY`
+	case SurrogateUUID:
+		return `"id" uuid PRIMARY KEY DEFAULT gen_random_uuid()`
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSurrogateKeySQL(t *testing.T) {
+	a := &Adapter{tableName: "casbin_rule"}
+	WithSurrogateKey(SurrogateBigserial)(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "casbin_rule_mapped", a.tableName)
+	require.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "casbin_rule" ("id" bigserial PRIMARY KEY, "rule_id" text, "ptype" text, "v0" text, "v1" text, "v2" text, "v3" text, "v4" text, "v5" text, UNIQUE ("rule_id"))`,
+		a.ruleView().sql()[0])
+
+	a = &Adapter{tableName: "casbin_rule"}
+	WithSurrogateKey(SurrogateUUID)(a)
+	WithTenant("acme")(a)
+	require.Error(t, a.mapColumns())
+}
+
+func (s *AdapterTestSuite) TestSurrogateKey() {
+	a, err := NewAdapterByDB(s.a.db, WithTableName("casbin_rule_sk"), WithSurrogateKey(SurrogateBigserial))
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	s.Require().Error(a.AddPolicy("p", "p", []string{"alice", "data1", "read"}), "the rules are unique")
+
+	var id int64
+	_, err = s.a.db.QueryOne(pg.Scan(&id), "SELECT id FROM casbin_rule_sk WHERE v0 = 'alice'")
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}))
+	var updated int64
+	_, err = s.a.db.QueryOne(pg.Scan(&updated), "SELECT id FROM casbin_rule_sk WHERE v0 = 'alice'")
+	s.Require().NoError(err)
+	s.Require().Equal(id, updated, "the rows are updated in place")
+
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"alice", "data1", "write"}))
+}
@@ -0,0 +1,169 @@
+package pgadapter
+
+import (
+	"context"
+	"strings"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/go-pg/pg/v10"
+)
+
+// SyncReport lists the changes made by SyncPolicy, each rule starts with its ptype.
+type SyncReport struct {
+	Added   [][]string
+	Removed [][]string
+}
+
+// SyncPolicy makes the table store exactly the rules of model and reports the changes.
+// Unlike SavePolicy it only inserts the missing rules and deletes the rules model doesn't have,
+// in a single transaction locking out concurrent writers, so the unchanged rows are left alone.
+// The changes are published as a single SyncPolicy change.
+func (a *Adapter) SyncPolicy(ctx context.Context, model model.Model) (*SyncReport, error) {
+	rules := make(map[string][][]string)
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range model[sec] {
+			rules[ptype] = append(rules[ptype], ast.Policy...)
+		}
+	}
+	return a.syncRules(ctx, rules)
+}
+
+// SyncWithFile is like SyncPolicy with the rules of the Casbin policy CSV file at path,
+// e.g. to keep the table in line with a policy file kept in version control on every boot.
+func (a *Adapter) SyncWithFile(ctx context.Context, path string) (_ *SyncReport, err error) {
+	rules, err := readPolicyFile(path)
+	if err != nil {
+		wrapError(OpSyncPolicy, &err)
+		return nil, err
+	}
+	return a.syncRules(ctx, rules)
+}
+
+func (a *Adapter) syncRules(ctx context.Context, rules map[string][][]string) (*SyncReport, error) {
+	var report *SyncReport
+	err := a.doContext(ctx, &Operation{Name: OpSyncPolicy}, func(ctx context.Context) error {
+		var err error
+		report, err = a.sync(ctx, rules)
+		return err
+	})
+	return report, err
+}
+
+func (a *Adapter) sync(ctx context.Context, rules map[string][][]string) (_ *SyncReport, err error) {
+	defer a.handleError(OpSyncPolicy, "", 0, &err)
+
+	if err := a.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	var want []*CasbinRule
+	for ptype, ptypeRules := range rules {
+		sec, err := ptypeSec(ptype)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.beforeWrite(OpSyncPolicy, sec, ptype, ptypeRules); err != nil {
+			return nil, err
+		}
+		for _, rule := range ptypeRules {
+			want = append(want, a.ruleLine(ptype, rule))
+		}
+	}
+	sortRules(want)
+	want = uniqueRules(want)
+
+	report := &SyncReport{}
+	change := PolicyChange{Operation: OpSyncPolicy}
+	err = a.runInTransaction(ctx, func(tx *pg.Tx) error {
+		added, err := a.replaceRules(ctx, tx, want, report)
+		if err != nil {
+			return err
+		}
+		if len(report.Added) == 0 && len(report.Removed) == 0 {
+			return nil
+		}
+		if err := a.checkQuota(tx, added); err != nil {
+			return err
+		}
+		change.Rules, change.Removed = report.Added, report.Removed
+		return a.recordChange(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Added) > 0 || len(report.Removed) > 0 {
+		a.changed(ctx, change)
+	}
+	return report, nil
+}
+
+// replaceRules makes the table store exactly want, in canonical order without repeats, within tx by inserting
+// the missing rules and deleting the others, and reports them. It returns the inserted lines.
+// The table is locked against concurrent writers first, except with WithCockroachDB.
+func (a *Adapter) replaceRules(ctx context.Context, tx *pg.Tx, want []*CasbinRule, report *SyncReport) ([]*CasbinRule, error) {
+	// CockroachDB has no table locks, its serializable transactions fail the conflicting writes instead.
+	if !a.cockroach {
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE ? IN SHARE ROW EXCLUSIVE MODE", pg.Ident(a.tableName)); err != nil {
+			return nil, err
+		}
+	}
+	var have []*CasbinRule
+	if err := tx.ModelContext(ctx, &have).Table(a.tableName).OrderExpr(canonicalOrder).Select(); err != nil {
+		return nil, err
+	}
+
+	d := diffRules(want, have)
+	report.Added, report.Removed = d.OnlyHere, d.OnlyThere
+
+	// The stored rows are deleted by their own ID, which may be stale, see RepairPolicyIDs.
+	if len(report.Removed) > 0 {
+		stored := make(map[string]*CasbinRule, len(have))
+		for _, line := range have {
+			stored[strings.Join(line.ptypeRule(), "\x00")] = line
+		}
+		ids := make([]string, 0, len(report.Removed))
+		for _, line := range a.linesOf(report.Removed) {
+			ids = append(ids, stored[strings.Join(line.ptypeRule(), "\x00")].ID)
+		}
+		if err := a.deleteIDs(ctx, tx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	added := a.linesOf(report.Added)
+	if len(added) == 0 {
+		return nil, nil
+	}
+	// An empty table is filled with COPY directly, views of mapped tables don't support COPY.
+	var err error
+	switch {
+	case a.batchSize() > 0:
+		err = a.insertBatches(tx, added)
+	case len(have) == 0 && a.mappedTable == "":
+		err = copyRules(tx, a.tableName, added)
+	default:
+		err = a.insertRules(tx, added)
+	}
+	return added, err
+}
+
+// uniqueRules drops the repeated rules of lines in canonical order.
+func uniqueRules(lines []*CasbinRule) []*CasbinRule {
+	unique := lines[:0]
+	for i, line := range lines {
+		if i == 0 || line.ID != lines[i-1].ID {
+			unique = append(unique, line)
+		}
+	}
+	return unique
+}
+
+// linesOf returns rules, which start with their ptype, as lines.
+func (a *Adapter) linesOf(rules [][]string) []*CasbinRule {
+	lines := make([]*CasbinRule, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, a.ruleLine(rule[0], rule[1:]))
+	}
+	return lines
+}
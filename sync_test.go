@@ -0,0 +1,49 @@
+package pgadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueRules(t *testing.T) {
+	lines := []*CasbinRule{
+		savePolicyLine("g", []string{"alice", "admin"}),
+		savePolicyLine("p", []string{"alice", "data1", "read"}),
+		savePolicyLine("p", []string{"alice", "data1", "read"}),
+	}
+	sortRules(lines)
+	require.Len(t, uniqueRules(lines), 2)
+}
+
+func TestSyncInvalidPtype(t *testing.T) {
+	a := &Adapter{tableName: DefaultTableName}
+	_, err := a.RestorePolicy(context.Background(), strings.NewReader("p, bob, data2, write\n, alice, data1\n"), DumpCSV)
+	require.ErrorIs(t, err, ErrInvalidRule)
+	_, err = a.RestorePolicy(context.Background(), strings.NewReader(`[["", "alice"]]`), DumpJSON)
+	require.ErrorIs(t, err, ErrInvalidRule)
+	_, err = a.RestorePolicy(context.Background(), strings.NewReader(`[["x", "alice"]]`), DumpJSON)
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func (s *AdapterTestSuite) TestSyncWithFile() {
+	_, err := s.e.AddPolicy("carol", "data1", "read")
+	s.Require().NoError(err)
+	_, err = s.e.RemovePolicy("alice", "data1", "read")
+	s.Require().NoError(err)
+
+	report, err := s.a.SyncWithFile(context.Background(), "examples/rbac_policy.csv")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "alice", "data1", "read"}}, report.Added)
+	s.Require().Equal([][]string{{"p", "carol", "data1", "read"}}, report.Removed)
+
+	report, err = s.a.SyncPolicy(context.Background(), s.e.GetModel())
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"p", "carol", "data1", "read"}}, report.Added)
+	s.Require().Equal([][]string{{"p", "alice", "data1", "read"}}, report.Removed)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy([][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"carol", "data1", "read"}}, s.e.GetPolicy())
+}
@@ -0,0 +1,86 @@
+package pgadapter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// TenantSetting is the Postgres setting holding the tenant of the write transactions of the adapters scoped
+// with WithTenant, the tenant_id column defaults to it and the policy created by WithTenantRowLevelSecurity checks it.
+const TenantSetting = "casbin.tenant_id"
+
+// WithTenant scopes the adapter to the rules of the tenant tenantID, so several customers can share the rules table.
+// The table gets a tenant_id column, which is part of its primary key, and the adapter works on a view of the rules
+// of the tenant named <table>_t_<hash of tenantID>, like with WithColumnMapping, so all the loads and mutations
+// only see the rules of the tenant. The rules added by the adapter get the tenant from TenantSetting, which is set
+// in every write transaction. The rules stored before are the ones of the tenant "". Use ForTenant to get adapters
+// for several tenants sharing one connection pool. Like with WithColumnMapping, options adding columns or indexes
+// to the rules table can't be combined with it.
+func WithTenant(tenantID string) Option {
+	return func(a *Adapter) {
+		a.tenant, a.tenantScoped = tenantID, true
+	}
+}
+
+// WithTenantRowLevelSecurity enables row level security on the rules table of the adapters scoped with WithTenant,
+// with a policy restricting the rows to the tenant of TenantSetting. The policy applies to the roles
+// other than the table owner, e.g. applications reading the rules directly after setting TenantSetting,
+// the adapters filter the rules by themselves.
+func WithTenantRowLevelSecurity() Option {
+	return func(a *Adapter) {
+		a.tenantRLS = true
+	}
+}
+
+// ForTenant returns a sibling adapter scoped to the tenant tenantID, see WithTenant, creating its view if needed.
+// Like WithTable, the sibling shares the connection pool of a, which must be closed last.
+func (a *Adapter) ForTenant(tenantID string) (*Adapter, error) {
+	b := a.sibling()
+	b.tableName, b.mappedTable = a.ruleView().table, ""
+	b.tenant, b.tenantScoped = tenantID, true
+	if err := b.mapColumns(); err != nil {
+		b.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := b.createTables(); err != nil {
+		b.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	return b, nil
+}
+
+// tenantColumnDef is the definition of the tenant_id column.
+const tenantColumnDef = "tenant_id text NOT NULL DEFAULT coalesce(current_setting('" + TenantSetting + "', true), '')"
+
+// tenantHash returns the suffix identifying the view of the tenant, tenant IDs can't be used in names as is.
+func tenantHash(tenant string) string {
+	sum := sha256.Sum256([]byte(tenant))
+	return hex.EncodeToString(sum[:6])
+}
+
+// tenantSQL returns the statements adding the tenant_id column to the table and to its primary key.
+func (v ruleView) tenantSQL() []string {
+	table := quoteQualified(v.table)
+	stmts := []string{
+		"ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS " + tenantColumnDef,
+		// The rules of different tenants have the same ID, the primary key is extended once.
+		"DO $$\n" +
+			"DECLARE pk name;\n" +
+			"BEGIN\n" +
+			"\tSELECT conname INTO pk FROM pg_constraint c WHERE conrelid = " + quoteLiteral(table) + "::regclass AND contype = 'p'\n" +
+			"\t\tAND NOT EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = c.conrelid AND attnum = ANY(c.conkey) AND attname = 'tenant_id');\n" +
+			"\tIF pk IS NOT NULL THEN\n" +
+			"\t\tEXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I, ADD PRIMARY KEY (tenant_id, %I)', " +
+			quoteLiteral(table) + "::regclass, pk, " + quoteLiteral(mappedColumn(v.columns, "id")) + ");\n" +
+			"\tEND IF;\n" +
+			"END $$",
+	}
+	if v.rls {
+		stmts = append(stmts,
+			"ALTER TABLE "+table+" ENABLE ROW LEVEL SECURITY",
+			"DROP POLICY IF EXISTS tenant_isolation ON "+table,
+			"CREATE POLICY tenant_isolation ON "+table+" USING (tenant_id = current_setting('"+TenantSetting+"', true))",
+		)
+	}
+	return stmts
+}
@@ -0,0 +1,53 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantView(t *testing.T) {
+	a := &Adapter{tableName: "rules"}
+	WithTenant("acme's")(a)
+	require.NoError(t, a.mapColumns())
+	require.Equal(t, "rules_t_"+tenantHash("acme's"), a.tableName)
+	require.NotEqual(t, tenantHash("acme's"), tenantHash("globex"))
+
+	v := a.ruleView()
+	require.Equal(t,
+		`CREATE OR REPLACE VIEW "`+a.tableName+`" AS SELECT "id" AS id, "ptype" AS ptype, "v0" AS v0, "v1" AS v1, "v2" AS v2, "v3" AS v3, "v4" AS v4, "v5" AS v5 FROM "rules" WHERE tenant_id = 'acme''s'`,
+		v.createSQL())
+	require.Len(t, v.sql(), 4)
+
+	v.rls = true
+	require.Len(t, v.sql(), 7)
+}
+
+func (s *AdapterTestSuite) TestTenant() {
+	acme, err := NewAdapterByDB(s.a.db, WithTenant("acme"))
+	s.Require().NoError(err)
+	globex, err := acme.ForTenant("globex")
+	s.Require().NoError(err)
+
+	s.Require().NoError(acme.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	s.Require().NoError(globex.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	s.Require().NoError(globex.AddPolicy("p", "p", []string{"bob", "data1", "read"}))
+	s.Require().NoError(acme.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", globex)
+	s.Require().NoError(err)
+	s.assertPolicy([][]string{{"alice", "data1", "read"}, {"bob", "data1", "read"}}, e.GetPolicy())
+
+	e, err = casbin.NewEnforcer("examples/rbac_model.conf", acme)
+	s.Require().NoError(err)
+	s.Require().Empty(e.GetPolicy())
+
+	// The rules stored before belong to the tenant "".
+	policies, err := acme.ForTenant("")
+	s.Require().NoError(err)
+	list, err := policies.ListPolicies(context.Background(), nil)
+	s.Require().NoError(err)
+	s.Require().Len(list, 5)
+}
@@ -0,0 +1,49 @@
+package pgadapter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// WithStatementTimeout bounds the duration of the statements of the adapter with the statement_timeout
+// setting of Postgres, so a filtered removal or a load of a huge table can't hold its locks forever.
+// It is set on the connections opened by NewSyncedEnforcer and NewEnforcerFromConn, and with SET LOCAL
+// in the write transactions of adapters sharing a pool, e.g. created by NewAdapterByDB, so the other users
+// of the pool are not affected. The loads of such adapters are bounded by the context of the Ctx methods.
+// Settings of WithSettings and Operation.Settings take precedence.
+func WithStatementTimeout(d time.Duration) Option {
+	return func(a *Adapter) {
+		a.statementTimeout = d
+	}
+}
+
+// statementTimeoutSetting returns the statement timeout in milliseconds, the unit of statement_timeout.
+// A timeout below a millisecond is rounded up, zero disables the timeout.
+func (a *Adapter) statementTimeoutSetting() string {
+	ms := a.statementTimeout.Milliseconds()
+	if ms == 0 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// applyStatementTimeout makes the connections of opts set the statement timeout when they are opened.
+func (a *Adapter) applyStatementTimeout(opts *pg.Options) {
+	if a.statementTimeout <= 0 {
+		return
+	}
+	setting := a.statementTimeoutSetting()
+	onConnect := opts.OnConnect
+	opts.OnConnect = func(ctx context.Context, conn *pg.Conn) error {
+		if onConnect != nil {
+			if err := onConnect(ctx, conn); err != nil {
+				return err
+			}
+		}
+		_, err := conn.ExecContext(ctx, "SELECT set_config('statement_timeout', ?, false)", setting)
+		return err
+	}
+}
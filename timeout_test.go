@@ -0,0 +1,45 @@
+package pgadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementTimeoutSetting(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		1500 * time.Millisecond: "1500",
+		time.Minute:             "60000",
+		time.Microsecond:        "1",
+	} {
+		a := &Adapter{}
+		WithStatementTimeout(d)(a)
+		require.Equal(t, want, a.statementTimeoutSetting(), "%v", d)
+	}
+
+	opts := &pg.Options{}
+	(&Adapter{}).applyStatementTimeout(opts)
+	require.Nil(t, opts.OnConnect, "no timeout is set by default")
+}
+
+func (s *AdapterTestSuite) TestStatementTimeout() {
+	a, err := NewAdapterByDB(s.a.db, WithStatementTimeout(1500*time.Millisecond))
+	s.Require().NoError(err)
+
+	var timeout string
+	err = a.runInTransaction(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.QueryOne(pg.Scan(&timeout), "SHOW statement_timeout")
+		return err
+	})
+	s.Require().NoError(err)
+	s.Require().Equal("1500ms", timeout)
+
+	err = a.runInTransaction(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.Exec("SELECT pg_sleep(3)")
+		return err
+	})
+	s.Require().Error(err, "the statement is canceled")
+}
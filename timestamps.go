@@ -0,0 +1,41 @@
+package pgadapter
+
+import (
+	"github.com/go-pg/pg/v10"
+)
+
+// WithTimestamps adds the created_at and updated_at columns to the rules table, set by Postgres when a rule
+// is inserted and by a trigger when it is updated, so the table tells when a grant was added or last changed.
+// The stored rules get the time the columns are added. ListPolicies returns the timestamps.
+func WithTimestamps() Option {
+	return func(a *Adapter) {
+		a.timestamps = true
+	}
+}
+
+func (a *Adapter) createTimestampColumns() error {
+	_, err := a.conn().Exec(`ALTER TABLE ?
+		ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now(),
+		ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now()`, pg.Ident(a.tableName))
+	if err != nil {
+		return err
+	}
+
+	function := pg.Ident(a.tableName + "_touch")
+	_, err = a.conn().Exec(`CREATE OR REPLACE FUNCTION ?() RETURNS trigger AS $$
+		BEGIN
+			NEW.updated_at = now();
+			RETURN NEW;
+		END $$ LANGUAGE plpgsql`, function)
+	if err != nil {
+		return err
+	}
+	_, err = a.conn().Exec("DROP TRIGGER IF EXISTS touch ON ?", pg.Ident(a.tableName))
+	if err != nil {
+		return err
+	}
+	// The rows rewritten unchanged, e.g. by ON CONFLICT DO UPDATE, keep their timestamp.
+	_, err = a.conn().Exec(`CREATE TRIGGER touch BEFORE UPDATE ON ?
+		FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE PROCEDURE ?()`, pg.Ident(a.tableName), function)
+	return err
+}
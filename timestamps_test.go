@@ -0,0 +1,28 @@
+package pgadapter
+
+import (
+	"context"
+	"time"
+)
+
+func (s *AdapterTestSuite) TestTimestamps() {
+	ctx := context.Background()
+	a, err := NewAdapterByDB(s.a.db, WithTimestamps())
+	s.Require().NoError(err)
+
+	start := time.Now().Add(-time.Minute)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(a.UpdatePolicy("p", "p", []string{"bob", "data2", "write"}, []string{"bob", "data3", "write"}))
+
+	policies, err := a.ListPolicies(ctx, &Filter{P: []string{}})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(policies)
+	for _, p := range policies {
+		s.Require().True(p.CreatedAt.After(start), "%v was created at %v", p.Rule, p.CreatedAt)
+		s.Require().False(p.UpdatedAt.Before(p.CreatedAt))
+	}
+
+	policies, err = s.a.ListPolicies(ctx, nil)
+	s.Require().NoError(err)
+	s.Require().True(policies[0].CreatedAt.IsZero(), "the timestamps are only read with WithTimestamps")
+}
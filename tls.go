@@ -0,0 +1,54 @@
+package pgadapter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+// WithTLSConfig makes the adapters created by NewSyncedEnforcer and NewEnforcerFromConn connect with TLS
+// using config, e.g. one made by NewTLSConfig. It replaces the configuration set by the sslmode of the URL.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(a *Adapter) {
+		a.tlsConfig = config
+	}
+}
+
+// TLSOptions are the files and server name of a verify-full TLS configuration, see NewTLSConfig.
+type TLSOptions struct {
+	// ServerName is the name the server certificate must be valid for, the host of the URL when empty.
+	ServerName string
+	// RootCAFile is the PEM file of the CAs the server certificate must be signed by,
+	// the system CAs are used when empty.
+	RootCAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate and its key, which are optional.
+	CertFile string
+	KeyFile  string
+}
+
+// NewTLSConfig returns the TLS configuration of sslmode=verify-full: the server certificate must be signed
+// by a trusted CA and valid for the server name. The client certificate is presented when the server asks for it.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{ServerName: opts.ServerName, MinVersion: tls.VersionTLS12}
+
+	if opts.RootCAFile != "" {
+		pem, err := os.ReadFile(opts.RootCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + opts.RootCAFile)
+		}
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
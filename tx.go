@@ -0,0 +1,226 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// WithRole makes the adapter execute SET LOCAL ROLE role at the start of every write transaction,
+// so the writes are performed under a narrowly granted role while the pool connects with a login role.
+// The login role must be a member of role. Loads aren't run in a transaction and keep the login role.
+func WithRole(role string) Option {
+	return func(a *Adapter) {
+		a.role = role
+	}
+}
+
+// WithSettings sets Postgres settings applied with SET LOCAL in every write transaction of the adapter,
+// e.g. {"app.service": "billing"}. Settings of Operation.Settings take precedence.
+func WithSettings(settings map[string]string) Option {
+	return func(a *Adapter) {
+		a.settings = settings
+	}
+}
+
+// WithTx returns a sibling adapter running its statements in the caller's transaction tx,
+// so policy changes commit or roll back together with the caller's own writes.
+// Its multi-statement operations run in a savepoint, a failing operation is rolled back to it
+// and the caller's transaction stays usable. The role and settings of the adapter, if any,
+// remain in effect for the rest of tx once an operation succeeded.
+// Changes are published when the operation succeeds, before tx is committed.
+func (a *Adapter) WithTx(tx *pg.Tx) *Adapter {
+	b := a.sibling()
+	b.tx = tx
+	return b
+}
+
+// NewAdapterByTx creates an adapter running all its statements in the caller's transaction tx, like WithTx,
+// for the callers having no adapter yet. The tables are created within tx unless SkipTableCreate is set.
+// The adapter has no connection pool, so Vacuum and NewWatcher can't be used with it
+// and closing it doesn't close anything. Don't use it once tx is committed or rolled back.
+func NewAdapterByTx(tx *pg.Tx, opts ...Option) (*Adapter, error) {
+	a := &Adapter{tx: tx, sharedDB: true, tableName: DefaultTableName}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if err := a.checkCockroachDB(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.mapColumns(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.createTables(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	if err := a.resolveSchema(); err != nil {
+		a.handleError(OpNewAdapter, "", 0, &err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// errNoPool is returned by the operations needing the connection pool of an adapter created by NewAdapterByTx.
+var errNoPool = errors.New("the adapter has no connection pool, see NewAdapterByTx")
+
+// Transaction runs fn with a sibling adapter whose operations all run in one transaction, see WithTx,
+// so several mutations commit atomically. The transaction is committed if fn returns nil and rolled back otherwise.
+// The changes are published once the transaction is committed, they are dropped when it is rolled back.
+// Within the caller's transaction set with WithTx, fn runs in a savepoint.
+// It fails with ErrReadOnly without running fn while writes are disabled, see SetWritable.
+func (a *Adapter) Transaction(ctx context.Context, fn func(txAdapter *Adapter) error) error {
+	if err := a.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	var changes []PolicyChange
+	run := func(tx *pg.Tx) error {
+		b := a.WithTx(tx)
+		b.txChanges = &changes
+		return fn(b)
+	}
+
+	var err error
+	if a.tx != nil {
+		err = a.runInSavepoint(ctx, run)
+	} else {
+		err = a.db.RunInTransaction(ctx, run)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		a.changed(ctx, change)
+	}
+	return nil
+}
+
+// conn returns the caller's transaction set with WithTx, or the connection pool.
+func (a *Adapter) conn() orm.DB {
+	if a.tx != nil {
+		return a.tx
+	}
+	return a.db
+}
+
+// runInTransaction runs fn in a transaction set up by setupTx, see pg.DB.RunInTransaction.
+// Within the caller's transaction it runs fn in a savepoint instead.
+func (a *Adapter) runInTransaction(ctx context.Context, fn func(*pg.Tx) error) error {
+	if a.tx != nil {
+		return a.runInSavepoint(ctx, fn)
+	}
+	return a.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := a.setupTx(ctx, tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+const (
+	txMaxAttempts = 4
+	txBackoff     = 20 * time.Millisecond
+)
+
+// runInTransactionRetry is like runInTransaction but runs fn again in a fresh transaction, with exponential backoff,
+// when the transaction fails with a deadlock or a serialization failure. fn must be idempotent.
+// Within the caller's transaction the failure is returned as is, the caller has to retry its whole transaction.
+func (a *Adapter) runInTransactionRetry(ctx context.Context, fn func(*pg.Tx) error) error {
+	backoff := txBackoff
+	for attempt := 1; ; attempt++ {
+		err := a.runInTransaction(ctx, fn)
+		if err == nil || a.tx != nil || attempt == txMaxAttempts || !retryable(err) {
+			return err
+		}
+
+		// The jitter keeps the transactions that deadlocked each other from colliding again.
+		timer := time.NewTimer(backoff + time.Duration(rand.Int63n(int64(backoff))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether err is a deadlock or a serialization failure, the transaction can be retried.
+func retryable(err error) bool {
+	switch pgErrorCode(err) {
+	case pgCodeDeadlock, pgCodeSerializationFailure:
+		return true
+	}
+	return false
+}
+
+func (a *Adapter) runInSavepoint(ctx context.Context, fn func(*pg.Tx) error) error {
+	tx := a.tx
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT casbin_adapter"); err != nil {
+		return err
+	}
+
+	err := a.setupTx(ctx, tx)
+	if err == nil {
+		err = fn(tx)
+	}
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT casbin_adapter"); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT casbin_adapter")
+	return err
+}
+
+// setupTx applies the role, the statement timeout, the values of the extra columns and the settings of the adapter and of the operation carried by ctx,
+// and sets TenantSetting with WithTenant.
+func (a *Adapter) setupTx(ctx context.Context, tx *pg.Tx) error {
+	if a.role != "" {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE ?", pg.Ident(a.role)); err != nil {
+			return err
+		}
+	}
+
+	settings := make(map[string]string, len(a.settings)+1)
+	if a.statementTimeout > 0 {
+		settings["statement_timeout"] = a.statementTimeoutSetting()
+	}
+	a.extraSettings(ctx, settings)
+	for k, v := range a.settings {
+		settings[k] = v
+	}
+	if op := operationFrom(ctx); op != nil {
+		for k, v := range op.Settings {
+			settings[k] = v
+		}
+	}
+	if a.tenantScoped {
+		settings[TenantSetting] = a.tenant
+	}
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		// set_config with is_local is the parameterizable form of SET LOCAL.
+		if _, err := tx.ExecContext(ctx, "SELECT set_config(?, ?, true)", k, settings[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package pgadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *AdapterTestSuite) TestWithRole() {
+	_, err := s.a.db.Exec("DROP ROLE IF EXISTS casbin_test_writer")
+	s.Require().NoError(err)
+	_, err = s.a.db.Exec("CREATE ROLE casbin_test_writer NOLOGIN")
+	s.Require().NoError(err)
+	defer s.a.db.Exec("DROP OWNED BY casbin_test_writer; DROP ROLE casbin_test_writer")
+	_, err = s.a.db.Exec("GRANT casbin_test_writer TO CURRENT_USER")
+	s.Require().NoError(err)
+	_, err = s.a.db.Exec("GRANT SELECT, INSERT ON casbin_rule TO casbin_test_writer")
+	s.Require().NoError(err)
+
+	a, err := NewAdapterByDB(s.a.db, WithRole("casbin_test_writer"), SkipTableCreate())
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data3", "read"}))
+
+	// The role is not allowed to delete.
+	err = a.RemovePolicy("p", "p", []string{"carol", "data3", "read"})
+	s.Require().Error(err)
+}
+
+func (s *AdapterTestSuite) TestSettings() {
+	_, err := s.a.db.Exec(`
+		CREATE TABLE casbin_rule_audit (actor text, service text);
+		CREATE FUNCTION casbin_rule_audit() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO casbin_rule_audit VALUES (current_setting('app.actor', true), current_setting('app.service', true));
+			RETURN NEW;
+		END $$ LANGUAGE plpgsql;
+		CREATE TRIGGER casbin_rule_audit AFTER INSERT ON casbin_rule FOR EACH ROW EXECUTE PROCEDURE casbin_rule_audit();
+	`)
+	s.Require().NoError(err)
+
+	a, err := NewAdapterByDB(s.a.db, WithSettings(map[string]string{"app.service": "billing", "app.actor": "system"}))
+	s.Require().NoError(err)
+	a.Use(func(next Handler) Handler {
+		return func(op *Operation) error {
+			op.Settings = map[string]string{"app.actor": "jane"}
+			return next(op)
+		}
+	})
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data3", "read"}))
+
+	var actor, service string
+	_, err = s.a.db.QueryOne(pg.Scan(&actor, &service), "SELECT actor, service FROM casbin_rule_audit")
+	s.Require().NoError(err)
+	s.Require().Equal("jane", actor)
+	s.Require().Equal("billing", service)
+}
+
+func (s *AdapterTestSuite) TestWithTx() {
+	tx, err := s.a.db.Begin()
+	s.Require().NoError(err)
+	defer tx.Close()
+
+	a := s.a.WithTx(tx)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data3", "read"}))
+
+	// A failing operation is rolled back to its savepoint, the caller's transaction stays usable.
+	a.Use(func(next Handler) Handler {
+		return func(op *Operation) error {
+			op.Settings = map[string]string{"invalid setting name": "x"}
+			return next(op)
+		}
+	})
+	s.Require().Error(a.AddPolicy("p", "p", []string{"dave", "data3", "read"}))
+
+	var count int
+	_, err = tx.QueryOne(pg.Scan(&count), "SELECT count(*) FROM casbin_rule WHERE v0 IN ('carol', 'dave')")
+	s.Require().NoError(err)
+	s.Require().Equal(1, count)
+
+	// Nothing is visible outside the transaction until it is committed.
+	_, err = s.a.db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM casbin_rule WHERE v0 = 'carol'")
+	s.Require().NoError(err)
+	s.Require().Equal(0, count)
+
+	s.Require().NoError(tx.Commit())
+	_, err = s.a.db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM casbin_rule WHERE v0 = 'carol'")
+	s.Require().NoError(err)
+	s.Require().Equal(1, count)
+}
+
+func (s *AdapterTestSuite) TestTransaction() {
+	ctx := context.Background()
+	changes, err := s.a.Subscribe(ctx)
+	s.Require().NoError(err)
+
+	err = s.a.Transaction(ctx, func(a *Adapter) error {
+		if err := a.AddPoliciesCtx(ctx, "p", "p", [][]string{{"carol", "data1", "read"}}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	s.Require().EqualError(err, "abort")
+
+	err = s.a.Transaction(ctx, func(a *Adapter) error {
+		if err := a.AddPoliciesCtx(ctx, "p", "p", [][]string{{"dave", "data1", "read"}}); err != nil {
+			return err
+		}
+		if err := a.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "bob"); err != nil {
+			return err
+		}
+		s.Require().Empty(changes, "the changes are published once committed")
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(OpAddPolicies, (<-changes).Operation)
+	s.Require().Equal(OpRemoveFilteredPolicy, (<-changes).Operation)
+
+	s.Require().NoError(s.e.LoadPolicy())
+	s.assertPolicy([][]string{{"alice", "data1", "read"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}, {"dave", "data1", "read"}}, s.e.GetPolicy())
+}
+
+func (s *AdapterTestSuite) TestNewAdapterByTx() {
+	tx, err := s.a.db.Begin()
+	s.Require().NoError(err)
+	defer tx.Close()
+
+	a, err := NewAdapterByTx(tx, WithTableName("casbin_rule_by_tx"))
+	s.Require().NoError(err)
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(tx.Rollback())
+
+	_, err = s.a.db.Exec("SELECT 1 FROM casbin_rule_by_tx")
+	s.Require().ErrorContains(err, "does not exist", "the table creation is rolled back with the caller's transaction")
+}
+
+// pgError is a pg.Error with a SQLSTATE code.
+type pgError string
+
+func (e pgError) Error() string { return "ERROR #" + string(e) }
+
+func (e pgError) Field(field byte) string {
+	if field == 'C' {
+		return string(e)
+	}
+	return ""
+}
+
+func (e pgError) IntegrityViolation() bool { return false }
+
+func TestRetryable(t *testing.T) {
+	require.True(t, retryable(pgError(pgCodeDeadlock)))
+	require.True(t, retryable(fmt.Errorf("update: %w", pgError(pgCodeSerializationFailure))))
+	require.False(t, retryable(pgError(pgCodeUniqueViolation)))
+	require.False(t, retryable(errors.New("boom")))
+
+	err := error(pgError(pgCodeDeadlock))
+	wrapError(OpUpdatePolicies, &err)
+	require.ErrorIs(t, err, ErrConflict)
+}
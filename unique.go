@@ -0,0 +1,49 @@
+package pgadapter
+
+import (
+	"errors"
+	"strings"
+)
+
+// WithNaturalUniqueIndex creates a unique index on the ptype and the values of the rules, so a rule can't be
+// stored twice even when rows are inserted by other tools or the IDs are computed differently, see WithIDFunc.
+// Empty and NULL values are the same for the index. The adapter skips the rules already stored like with the IDs,
+// and an update to a stored rule fails with ErrPolicyExists. The rules of different tenants are kept apart
+// with WithTenant, and the rules removed with WithSoftDelete are left out of the index.
+// Btree indexes limit the size of their entries to about 2700 bytes, longer rules can't be stored.
+// It can't be combined with WithPartitioning, whose tables don't support unique expression indexes.
+func WithNaturalUniqueIndex() Option {
+	return func(a *Adapter) {
+		a.naturalUniqueIndex = true
+	}
+}
+
+func (a *Adapter) createNaturalUniqueIndex() error {
+	if a.partitioning != nil {
+		return errors.New("WithNaturalUniqueIndex can't be combined with WithPartitioning")
+	}
+	_, err := a.conn().Exec(a.ruleView().naturalUniqueIndexSQL())
+	return err
+}
+
+// naturalUniqueIndexSQL returns the statement creating the unique index on the rules of the table of the view.
+func (v ruleView) naturalUniqueIndexSQL() string {
+	var cols []string
+	if v.scoped {
+		cols = append(cols, "tenant_id")
+	}
+	if v.jsonb {
+		cols = append(cols, "ptype", "rule")
+	} else {
+		cols = append(cols, quoteIdent(mappedColumn(v.columns, "ptype")))
+		for _, column := range ruleColumns[2:] {
+			cols = append(cols, "coalesce("+quoteIdent(mappedColumn(v.columns, column))+", '')")
+		}
+	}
+	stmt := "CREATE UNIQUE INDEX IF NOT EXISTS " + quoteIdent(unqualified(v.table)+"_rule_key") +
+		" ON " + quoteQualified(v.table) + " (" + strings.Join(cols, ", ") + ")"
+	if v.softDelete {
+		stmt += " WHERE deleted_at IS NULL"
+	}
+	return stmt
+}
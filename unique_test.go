@@ -0,0 +1,42 @@
+package pgadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNaturalUniqueIndexSQL(t *testing.T) {
+	v := ruleView{table: "casbin_rule", columns: map[string]string{"ptype": "p_type"}}
+	require.Equal(t, `CREATE UNIQUE INDEX IF NOT EXISTS "casbin_rule_rule_key" ON "casbin_rule" `+
+		`("p_type", coalesce("v0", ''), coalesce("v1", ''), coalesce("v2", ''), coalesce("v3", ''), coalesce("v4", ''), coalesce("v5", ''))`,
+		v.naturalUniqueIndexSQL())
+
+	v = ruleView{table: "casbin_rule", jsonb: true, scoped: true, softDelete: true}
+	require.Equal(t, `CREATE UNIQUE INDEX IF NOT EXISTS "casbin_rule_rule_key" ON "casbin_rule" `+
+		`(tenant_id, ptype, rule) WHERE deleted_at IS NULL`,
+		v.naturalUniqueIndexSQL())
+
+	a := &Adapter{}
+	WithNaturalUniqueIndex()(a)
+	WithPartitioning(Partitioning{})(a)
+	require.Error(t, a.createNaturalUniqueIndex())
+}
+
+func (s *AdapterTestSuite) TestNaturalUniqueIndex() {
+	a, err := NewAdapterByDB(s.a.db, WithNaturalUniqueIndex())
+	s.Require().NoError(err)
+
+	s.Require().NoError(a.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	_, err = s.a.db.Exec("INSERT INTO casbin_rule (id, ptype, v0, v1, v2) VALUES ('other', 'p', 'carol', 'data1', 'read')")
+	s.Require().Equal(pgCodeUniqueViolation, pgErrorCode(err))
+
+	b, err := NewAdapterByDB(s.a.db, WithNaturalUniqueIndex(), WithIDFunc(SHA256PolicyID))
+	s.Require().NoError(err)
+	s.Require().NoError(b.AddPolicy("p", "p", []string{"carol", "data1", "read"}), "the stored rule is skipped")
+	rules, err := a.GetFilteredPolicies("p", 0, "carol")
+	s.Require().NoError(err)
+	s.Require().Equal([][]string{{"carol", "data1", "read"}}, rules)
+
+	s.Require().NoError(a.RemovePolicy("p", "p", []string{"carol", "data1", "read"}))
+}
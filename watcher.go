@@ -0,0 +1,122 @@
+package pgadapter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/go-pg/pg/v10"
+)
+
+// DefaultNotifyChannel is the channel the adapter notifies its writes on, see WithNotify.
+const DefaultNotifyChannel = "casbin_policy_changes"
+
+// Notification is the payload of the notifications sent by the adapter, see WithNotify.
+type Notification struct {
+	Table     string `json:"table"`
+	Operation string `json:"operation"`
+	// Sender identifies the adapter that made the change.
+	Sender string `json:"sender"`
+}
+
+// WithNotify makes the adapter send a NOTIFY on DefaultNotifyChannel within the transaction of every write,
+// so the other instances learn about the committed changes through a Watcher without Redis or etcd.
+func WithNotify() Option {
+	return func(a *Adapter) {
+		a.notifySender = newNotifySender()
+	}
+}
+
+func newNotifySender() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// notify sends the notification of change within tx if WithNotify is set.
+func (a *Adapter) notify(tx *pg.Tx, change PolicyChange) error {
+	if a.notifySender == "" {
+		return nil
+	}
+	payload, err := json.Marshal(Notification{Table: a.tableName, Operation: change.Operation, Sender: a.notifySender})
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("SELECT pg_notify(?, ?)", DefaultNotifyChannel, string(payload))
+	return err
+}
+
+// Watcher is a persist.Watcher listening to the notifications of the adapters writing to the rules table.
+// The update callback is called with the JSON encoded Notification of each change made by another adapter.
+type Watcher struct {
+	a  *Adapter
+	ln *pg.Listener
+
+	mu       sync.Mutex
+	callback func(string)
+	done     chan struct{}
+}
+
+var _ persist.Watcher = (*Watcher)(nil)
+
+// NewWatcher creates a Watcher listening on the connection pool of a to the changes of its rules table
+// made by other adapters, a must be created with WithNotify. Close the watcher before a.
+func NewWatcher(a *Adapter) (*Watcher, error) {
+	if a.notifySender == "" {
+		return nil, errors.New("notifications are not enabled, see WithNotify")
+	}
+	if a.db == nil {
+		return nil, errNoPool
+	}
+
+	// The listener reconnects and listens again when its connection breaks.
+	ln := a.db.Listen(context.Background(), DefaultNotifyChannel)
+	w := &Watcher{a: a, ln: ln, done: make(chan struct{})}
+	go w.run()
+	return w, nil
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	for n := range w.ln.Channel() {
+		var msg Notification
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+			continue
+		}
+		if msg.Table != w.a.tableName || msg.Sender == w.a.notifySender {
+			continue
+		}
+
+		w.mu.Lock()
+		callback := w.callback
+		w.mu.Unlock()
+		if callback != nil {
+			callback(n.Payload)
+		}
+	}
+}
+
+// SetUpdateCallback sets the function called when another adapter changed the rules.
+func (w *Watcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	w.callback = callback
+	w.mu.Unlock()
+	return nil
+}
+
+// Update does nothing, the adapter notifies the other instances within the transaction of every write.
+func (w *Watcher) Update() error {
+	return nil
+}
+
+// Close stops listening and waits for a running callback to return.
+func (w *Watcher) Close() {
+	w.ln.Close()
+	<-w.done
+}
@@ -0,0 +1,42 @@
+package pgadapter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWatcherRequiresNotify(t *testing.T) {
+	_, err := NewWatcher(&Adapter{})
+	require.Error(t, err)
+}
+
+func (s *AdapterTestSuite) TestWatcher() {
+	a, err := NewAdapterByDB(s.a.db, WithNotify())
+	s.Require().NoError(err)
+	other, err := NewAdapterByDB(s.a.db, WithNotify())
+	s.Require().NoError(err)
+
+	w, err := NewWatcher(a)
+	s.Require().NoError(err)
+	defer w.Close()
+
+	updates := make(chan string, 10)
+	s.Require().NoError(w.SetUpdateCallback(func(msg string) { updates <- msg }))
+
+	s.Require().NoError(a.AddPolicyCtx(context.Background(), "p", "p", []string{"carol", "data1", "read"}))
+	s.Require().NoError(other.AddPolicyCtx(context.Background(), "p", "p", []string{"dave", "data1", "read"}))
+
+	select {
+	case msg := <-updates:
+		var n Notification
+		s.Require().NoError(json.Unmarshal([]byte(msg), &n))
+		s.Require().Equal(OpAddPolicy, n.Operation)
+		s.Require().Equal(other.notifySender, n.Sender, "the changes of the own adapter are skipped")
+	case <-time.After(5 * time.Second):
+		s.Fail("no notification")
+	}
+}
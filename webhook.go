@@ -0,0 +1,164 @@
+package pgadapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request body.
+const WebhookSignatureHeader = "X-Casbin-Signature"
+
+const (
+	webhookQueueSize   = 256
+	webhookMaxAttempts = 5
+	webhookBackoff     = 500 * time.Millisecond
+)
+
+// WithWebhook makes the adapter POST a JSON encoded PolicyChange to url after every successful mutation.
+// The body is signed with HMAC-SHA256 using signingKey, see WebhookSignatureHeader.
+// Deliveries are queued and sent in the background, failed deliveries are retried with exponential backoff.
+// If the queue is full the change is dropped rather than blocking the caller. The changes dropped and
+// the deliveries still failing after the retries are reported to the error hook and the logger as OpWebhook.
+func WithWebhook(url, signingKey string) Option {
+	return func(a *Adapter) {
+		a.webhook = newWebhook(url, signingKey)
+		a.webhook.report = func(change PolicyChange, err error) {
+			a.handleError(OpWebhook, change.Ptype, len(change.Rules), &err)
+		}
+		a.publishers = append(a.publishers, a.webhook)
+	}
+}
+
+type webhook struct {
+	url         string
+	key         []byte
+	client      *http.Client
+	queue       chan PolicyChange
+	maxAttempts int
+	backoff     time.Duration
+	// report is called with the changes that couldn't be delivered.
+	report func(change PolicyChange, err error)
+
+	// mu guards closed, the queue must not be sent to once closed.
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+}
+
+func newWebhook(url, signingKey string) *webhook {
+	w := &webhook{
+		url:         url,
+		key:         []byte(signingKey),
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan PolicyChange, webhookQueueSize),
+		maxAttempts: webhookMaxAttempts,
+		backoff:     webhookBackoff,
+		done:        make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Publish implements ChangePublisher by queueing the change for delivery.
+// It fails once the webhook is closed, e.g. for the writes of a sibling adapter outliving its parent.
+func (w *webhook) Publish(_ context.Context, change PolicyChange) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("webhook is closed")
+	}
+	select {
+	case w.queue <- change:
+		w.mu.Unlock()
+	default:
+		w.mu.Unlock()
+		w.failed(change, errors.New("webhook queue is full, the change is dropped"))
+	}
+	return nil
+}
+
+func (w *webhook) run() {
+	defer close(w.done)
+	for change := range w.queue {
+		w.deliver(change)
+	}
+}
+
+// close stops accepting changes and waits until the queued ones have been delivered.
+func (w *webhook) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *webhook) deliver(change PolicyChange) {
+	body, err := json.Marshal(change)
+	if err != nil {
+		w.failed(change, err)
+		return
+	}
+
+	backoff := w.backoff
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		var retry bool
+		retry, err = w.post(body)
+		if err == nil {
+			return
+		}
+		if !retry {
+			break
+		}
+		if attempt < w.maxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	w.failed(change, err)
+}
+
+// failed reports a change that couldn't be delivered.
+func (w *webhook) failed(change PolicyChange, err error) {
+	if w.report != nil {
+		w.report(change, err)
+	}
+}
+
+func (w *webhook) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, signPayload(w.key, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook responded with %s", resp.Status)
+}
+
+func signPayload(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,111 @@
+package pgadapter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	const key = "secret"
+	var calls int32
+	received := make(chan PolicyChange, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, signPayload([]byte(key), body), r.Header.Get(WebhookSignatureHeader))
+
+		// The first delivery fails and must be retried.
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var change PolicyChange
+		require.NoError(t, json.Unmarshal(body, &change))
+		received <- change
+	}))
+	defer srv.Close()
+
+	a := &Adapter{}
+	WithActor("tester")(a)
+	WithWebhook(srv.URL, key)(a)
+	a.webhook.backoff = time.Millisecond
+	defer a.Close()
+
+	a.changed(context.Background(), PolicyChange{Operation: OpAddPolicy, Sec: "p", Ptype: "p", Rules: [][]string{{"alice", "data1", "read"}}})
+
+	select {
+	case change := <-received:
+		require.Equal(t, OpAddPolicy, change.Operation)
+		require.Equal(t, [][]string{{"alice", "data1", "read"}}, change.Rules)
+		require.Equal(t, "tester", change.Actor)
+		require.Equal(t, uint64(1), change.Revision)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookPublishAfterClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	a := &Adapter{}
+	WithWebhook(srv.URL, "secret")(a)
+	b := a.sibling()
+	require.NoError(t, a.Close())
+
+	require.Error(t, a.webhook.Publish(context.Background(), PolicyChange{Operation: OpAddPolicy}))
+	// The sibling shares the webhook as a publisher, its writes must not panic.
+	require.NotPanics(t, func() {
+		b.changed(context.Background(), PolicyChange{Operation: OpAddPolicy, Sec: "p", Ptype: "p", Rules: [][]string{{"alice", "data1", "read"}}})
+	})
+	require.NoError(t, a.Close())
+}
+
+func TestWebhookFailureReported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	reported := make(chan error, 1)
+	a := &Adapter{}
+	WithErrorHook(func(op string, err error, meta map[string]interface{}) {
+		require.Equal(t, OpWebhook, op)
+		require.Equal(t, "p", meta["ptype"])
+		reported <- err
+	})(a)
+	WithWebhook(srv.URL, "secret")(a)
+	a.webhook.backoff = time.Millisecond
+	defer a.Close()
+
+	a.changed(context.Background(), PolicyChange{Operation: OpAddPolicy, Sec: "p", Ptype: "p", Rules: [][]string{{"alice", "data1", "read"}}})
+	select {
+	case err := <-reported:
+		require.ErrorContains(t, err, "503")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the failed delivery was not reported")
+	}
+}
+
+func TestWebhookQueueFullReported(t *testing.T) {
+	var reported []string
+	w := &webhook{queue: make(chan PolicyChange, 1)}
+	w.report = func(change PolicyChange, err error) {
+		reported = append(reported, change.Operation)
+	}
+
+	require.NoError(t, w.Publish(context.Background(), PolicyChange{Operation: OpAddPolicy}))
+	require.NoError(t, w.Publish(context.Background(), PolicyChange{Operation: OpRemovePolicy}))
+	require.Equal(t, []string{OpRemovePolicy}, reported)
+}